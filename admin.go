@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/S-Devoe/golang-simple-bank/config"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/S-Devoe/golang-simple-bank/util/password"
+	"github.com/S-Devoe/golang-simple-bank/val"
+)
+
+// adminPasswordEnv holds the password of a new admin when it isn't piped in with -password-stdin
+const adminPasswordEnv = "ADMIN_PASSWORD"
+
+// runUserCommand handles `user create-admin`; an existing user with that username is promoted instead of recreated.
+// The password is never a flag, so it doesn't end up in the process list or the shell history.
+func runUserCommand(config config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "create-admin" {
+		return errUsage
+	}
+
+	flags := flag.NewFlagSet("user create-admin", flag.ContinueOnError)
+	username := flags.String("username", "", "username of the admin")
+	email := flags.String("email", "", "email of the admin")
+	fullName := flags.String("full-name", "", "full name of the admin")
+	passwordStdin := flags.Bool("password-stdin", false, "read the password of the admin, 6 to 100 characters, from stdin instead of "+adminPasswordEnv)
+	if err := flags.Parse(args[1:]); err != nil {
+		return errUsage
+	}
+	if *username == "" {
+		return errors.New("-username is required")
+	}
+
+	connection, store, err := connectStore(config)
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	ctx := context.Background()
+	_, err = store.GetUser(ctx, *username)
+	if err != nil {
+		if err != db.ErrRecordNotFound {
+			return fmt.Errorf("cannot look up user: %w", err)
+		}
+		plainPassword, err := readAdminPassword(*passwordStdin, os.Stdin)
+		if err != nil {
+			return err
+		}
+		if *email == "" || *fullName == "" || plainPassword == "" {
+			return fmt.Errorf("-email, -full-name and a password (-password-stdin or %s) are required to create a new admin", adminPasswordEnv)
+		}
+		// the same rules a signup over REST or gRPC goes through
+		if err := val.ValidateUsername(*username); err != nil {
+			return fmt.Errorf("-username %w", err)
+		}
+		if err := val.ValidateEmail(*email); err != nil {
+			return fmt.Errorf("-email %w", err)
+		}
+		if err := val.ValidateFullName(*fullName); err != nil {
+			return fmt.Errorf("-full-name %w", err)
+		}
+		if err := val.ValidatePassword(plainPassword); err != nil {
+			return fmt.Errorf("password %w", err)
+		}
+		hashedPassword, err := password.GeneratePasswordHash(plainPassword)
+		if err != nil {
+			return fmt.Errorf("cannot hash password: %w", err)
+		}
+		_, err = store.CreateUser(ctx, db.CreateUserParams{
+			Username:       *username,
+			HashedPassword: hashedPassword,
+			FullName:       *fullName,
+			Email:          *email,
+		})
+		if err != nil {
+			return fmt.Errorf("cannot create user: %w", err)
+		}
+	}
+
+	if _, err := store.UpdateUserRole(ctx, db.UpdateUserRoleParams{Username: *username, Role: util.AdminRole}); err != nil {
+		return fmt.Errorf("cannot grant admin role: %w", err)
+	}
+	log.Printf("%s is now an admin", *username)
+	return nil
+}
+
+// readAdminPassword takes the first line of stdin when fromStdin is set, and ADMIN_PASSWORD otherwise
+func readAdminPassword(fromStdin bool, stdin io.Reader) (string, error) {
+	if !fromStdin {
+		return os.Getenv(adminPasswordEnv), nil
+	}
+	line, err := bufio.NewReader(stdin).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("cannot read password from stdin: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// runSessionCommand handles `session revoke`, blocking one session or every session of a user
+func runSessionCommand(config config.Config, args []string) error {
+	if len(args) == 0 || args[0] != "revoke" {
+		return errUsage
+	}
+
+	flags := flag.NewFlagSet("session revoke", flag.ContinueOnError)
+	id := flags.String("id", "", "id of the session to revoke")
+	username := flags.String("username", "", "revoke every session of this user")
+	if err := flags.Parse(args[1:]); err != nil {
+		return errUsage
+	}
+	if (*id == "") == (*username == "") {
+		return errors.New("exactly one of -id or -username is required")
+	}
+
+	connection, store, err := connectStore(config)
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	ctx := context.Background()
+	if *id != "" {
+		if _, err := store.BlockSession(ctx, *id); err != nil {
+			if err == db.ErrRecordNotFound {
+				return fmt.Errorf("session %s not found", *id)
+			}
+			return fmt.Errorf("cannot revoke session: %w", err)
+		}
+		log.Printf("revoked session %s", *id)
+		return nil
+	}
+
+	revoked, err := store.BlockUserSessions(ctx, *username)
+	if err != nil {
+		return fmt.Errorf("cannot revoke sessions: %w", err)
+	}
+	log.Printf("revoked %d sessions of %s", revoked, *username)
+	return nil
+}
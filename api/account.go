@@ -1,10 +1,13 @@
 package api
 
 import (
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 
+	"github.com/S-Devoe/golang-simple-bank/apperr"
 	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
-	"github.com/S-Devoe/golang-simple-bank/token"
 	"github.com/S-Devoe/golang-simple-bank/util"
 	"github.com/gin-gonic/gin"
 )
@@ -14,14 +17,16 @@ type createAccountRequest struct {
 	Currency string `json:"currency" binding:"required,currency"`
 }
 
-func (server *Server) createAccount(ctx *gin.Context) {
+func (server *Server) createAccount(ctx *gin.Context) error {
 	var req createAccountRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
 		// ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		ctx.JSON(http.StatusBadRequest, util.CreateResponse(http.StatusBadRequest, nil, err))
-		return
+		return bindingError(err)
+	}
+	authPayload, err := authorizationPayload(ctx)
+	if err != nil {
+		return err
 	}
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	arg := db.CreateAccountParams{
 		Owner:    authPayload.Username,
 		Currency: req.Currency,
@@ -30,14 +35,13 @@ func (server *Server) createAccount(ctx *gin.Context) {
 	account, err := server.store.CreateAccount(ctx, arg)
 	if err != nil {
 		if db.ErrorCode(err) == db.UniqueViolation {
-			ctx.JSON(http.StatusForbidden, util.CreateResponse(http.StatusForbidden, nil, err))
-			return
+			return apperr.New(apperr.AccountAlreadyExists, fmt.Sprintf("account in %s already exists", req.Currency))
 		}
 
-		ctx.JSON(http.StatusInternalServerError, util.CreateResponse(http.StatusInternalServerError, nil, err))
-		return
+		return err
 	}
 	ctx.JSON(http.StatusCreated, util.CreateResponse(http.StatusCreated, account, nil))
+	return nil
 }
 
 // get account by id
@@ -45,66 +49,145 @@ type getAccountRequest struct {
 	ID int64 `uri:"id" binding:"required,min=1"`
 }
 
-func (server *Server) getAccount(ctx *gin.Context) {
+func (server *Server) getAccount(ctx *gin.Context) error {
 	var req getAccountRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, util.CreateResponse(http.StatusBadRequest, nil, err))
-		return
+		return bindingError(err)
 	}
 	account, err := server.store.GetAccount(ctx, req.ID)
 	if err != nil {
-		if err == db.ErrRecordNotFound {
-			ctx.JSON(http.StatusNotFound, util.CreateResponse(http.StatusNotFound, nil, "Account not found"))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, util.CreateResponse(http.StatusInternalServerError, nil, err))
-		return
+		return orNotFound(err, apperr.AccountNotFound, "account not found")
+	}
+	authPayload, err := authorizationPayload(ctx)
+	if err != nil {
+		return err
 	}
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	if account.Owner != authPayload.Username {
-		ctx.JSON(http.StatusUnauthorized, util.CreateResponse(http.StatusUnauthorized, nil, "Account doesn't belong to this authenticated user"))
-		return
+		return apperr.New(apperr.AccountNotOwned, "account does not belong to the authenticated user")
 	}
 	ctx.JSON(http.StatusOK, util.CreateResponse(http.StatusOK, account, nil))
+	return nil
 }
 
 // get accounts
-type listAccountsRequest struct {
-	Limit int32 `query:"limit"`
-	Page  int32 `query:"page"`
+func (server *Server) listAccounts(ctx *gin.Context) error {
+	pagination, err := util.ParseCursorQuery(ctx)
+	if err != nil {
+		return invalidArgument(err.Error())
+	}
+	// accounts are listed oldest first, so the first page starts after id 0
+	cursor, err := pagination.Int64Key(0)
+	if err != nil {
+		return invalidArgument(err.Error())
+	}
+
+	authPayload, err := authorizationPayload(ctx)
+	if err != nil {
+		return err
+	}
+	var accounts []db.Account
+	if pagination.Backward {
+		accounts, err = server.store.ListAccountsReverse(ctx, db.ListAccountsReverseParams{
+			Owner:  authPayload.Username,
+			Cursor: cursor,
+			Limit:  pagination.Limit + 1,
+		})
+	} else {
+		accounts, err = server.store.ListAccounts(ctx, db.ListAccountsParams{
+			Owner:  authPayload.Username,
+			Cursor: cursor,
+			Limit:  pagination.Limit + 1,
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	data := util.NewCursorPage(accounts, pagination, func(account db.Account) string {
+		return strconv.FormatInt(account.ID, 10)
+	})
+	if pagination.WithTotal {
+		totalItems, err := server.store.CountAccounts(ctx, authPayload.Username)
+		if err != nil {
+			return err
+		}
+		data.Total = &totalItems
+	}
+	ctx.JSON(http.StatusOK, util.CreateCursorPaginatedResponse(http.StatusOK, data, nil))
+	return nil
 }
 
-func (server *Server) listAccounts(ctx *gin.Context) {
-	var req listAccountsRequest
+// get the entries of an account, with the memo and reference of the transfer behind each one
+func (server *Server) listAccountEntries(ctx *gin.Context) error {
+	var req getAccountRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		return bindingError(err)
+	}
+
+	pagination, err := util.ParseCursorQuery(ctx)
+	if err != nil {
+		return invalidArgument(err.Error())
+	}
+	// entries are listed newest first, so the first page starts below every id
+	cursor, err := pagination.Int64Key(math.MaxInt64)
+	if err != nil {
+		return invalidArgument(err.Error())
+	}
 
-	pagination, err := util.ParsePaginationQuery(ctx)
+	account, err := server.ownedAccount(ctx, req.ID)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, util.CreateResponse(http.StatusBadRequest, nil, err.Error()))
-		return
+		return err
 	}
 
-	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, util.CreateResponse(http.StatusBadRequest, nil, err))
-		return
+	var entries []db.ListAccountEntriesRow
+	if pagination.Backward {
+		var rows []db.ListAccountEntriesReverseRow
+		rows, err = server.store.ListAccountEntriesReverse(ctx, db.ListAccountEntriesReverseParams{
+			AccountID: account.ID,
+			Cursor:    cursor,
+			Limit:     pagination.Limit + 1,
+		})
+		for _, row := range rows {
+			entries = append(entries, db.ListAccountEntriesRow(row))
+		}
+	} else {
+		entries, err = server.store.ListAccountEntries(ctx, db.ListAccountEntriesParams{
+			AccountID: account.ID,
+			Cursor:    cursor,
+			Limit:     pagination.Limit + 1,
+		})
 	}
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	arg := db.ListAccountsParams{
-		Limit:  pagination.Limit,
-		Offset: pagination.Offset,
-		Owner:  authPayload.Username,
+	if err != nil {
+		return err
 	}
 
-	accounts, err := server.store.ListAccounts(ctx, arg)
+	data := util.NewCursorPage(entries, pagination, func(entry db.ListAccountEntriesRow) string {
+		return strconv.FormatInt(entry.ID, 10)
+	})
+	if pagination.WithTotal {
+		totalItems, err := server.store.CountAccountEntries(ctx, account.ID)
+		if err != nil {
+			return err
+		}
+		data.Total = &totalItems
+	}
+	ctx.JSON(http.StatusOK, util.CreateCursorPaginatedResponse(http.StatusOK, data, nil))
+	return nil
+}
 
+// ownedAccount fetches an account and makes sure it belongs to the authenticated user
+func (server *Server) ownedAccount(ctx *gin.Context, accountID int64) (db.Account, error) {
+	account, err := server.store.GetAccount(ctx, accountID)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, util.CreateResponse(http.StatusInternalServerError, nil, err))
-		return
+		return account, orNotFound(err, apperr.AccountNotFound, "account not found")
 	}
 
-	totalItems, err := server.store.CountAccounts(ctx)
+	authPayload, err := authorizationPayload(ctx)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, util.CreateResponse(http.StatusInternalServerError, nil, err))
-		return
+		return account, err
+	}
+	if account.Owner != authPayload.Username {
+		return account, apperr.New(apperr.AccountNotOwned, "account does not belong to the authenticated user")
 	}
-	ctx.JSON(http.StatusOK, util.CreatePaginatedResponse(http.StatusOK, accounts, pagination.Page, pagination.Limit, totalItems, nil))
+	return account, nil
 }
@@ -3,11 +3,13 @@ package api
 import "github.com/gin-gonic/gin"
 
 func (server *Server) setUpAccountRoutes(router *gin.RouterGroup) {
-	accountsGroup := router.Group("/accounts").Use(authMiddleware(server.tokenMaker))
+	accountsGroup := router.Group("/accounts").Use(authMiddleware(server.tokenMaker, server.sessions))
 	{
 		// accounts endpoint
-		accountsGroup.POST("", server.createAccount)
-		accountsGroup.GET("/:id", server.getAccount)
-		accountsGroup.GET("", server.listAccounts)
+		accountsGroup.POST("", handle(server.createAccount))
+		accountsGroup.GET("/:id", handle(server.getAccount))
+		accountsGroup.GET("", handle(server.listAccounts))
+		accountsGroup.GET("/:id/entries", handle(server.listAccountEntries))
+		accountsGroup.GET("/:id/analytics", handle(server.getAccountAnalytics))
 	}
 }
@@ -8,6 +8,7 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
@@ -59,8 +60,8 @@ func TestCreateAccountAPI(t *testing.T) {
 func TestListAccountsAPI(t *testing.T) {
 	// Mock data
 	accounts := []db.Account{
-		{ID: 1, Owner: "owner1", Balance: 100.0, Currency: "USD"},
-		{ID: 2, Owner: "owner2", Balance: 200.0, Currency: "EUR"},
+		{ID: 1, Owner: "user", Balance: 100.0, Currency: "USD"},
+		{ID: 2, Owner: "user", Balance: 200.0, Currency: "EUR"},
 	}
 
 	ctrl := gomock.NewController(t)
@@ -68,17 +69,22 @@ func TestListAccountsAPI(t *testing.T) {
 
 	store := mockdb.NewMockStore(ctrl)
 	// Mock `ListAccounts`
-	store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(1).Return(accounts, nil)
-	// Mock `CountAccounts`
+	arg := db.ListAccountsParams{
+		Owner:  "user",
+		Cursor: 0,
+		Limit:  util.DefaultLimit + 1,
+	}
+	store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(arg)).Times(1).Return(accounts, nil)
+	// Mock `CountAccounts`, scoped to the caller
 	store.EXPECT().
-		CountAccounts(gomock.Any()).
+		CountAccounts(gomock.Any(), gomock.Eq("user")).
 		Times(1).
 		Return(int64(len(accounts)), nil)
 
 	server := newTestServer(t, store)
 	recorder := httptest.NewRecorder()
 
-	req, err := http.NewRequest(http.MethodGet, "/api/v1/accounts", nil)
+	req, err := http.NewRequest(http.MethodGet, "/api/v1/accounts?include_total=true", nil)
 	require.Nil(t, err)
 
 	addAuthorization(t, req, server.tokenMaker, authorizationTypeBearer, "user", "devoe", time.Minute)
@@ -89,6 +95,98 @@ func TestListAccountsAPI(t *testing.T) {
 
 }
 
+func TestListAccountsCursorAPI(t *testing.T) {
+	accounts := []db.Account{
+		{ID: 4, Owner: "user", Balance: 100.0, Currency: "USD"},
+		{ID: 5, Owner: "user", Balance: 200.0, Currency: "EUR"},
+		{ID: 6, Owner: "user", Balance: 300.0, Currency: "CAD"},
+	}
+
+	testCases := []struct {
+		name          string
+		query         url.Values
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "NextPage",
+			query: url.Values{"limit": {"2"}, "cursor": {util.EncodeCursor("3", false)}},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAccountsParams{Owner: "user", Cursor: 3, Limit: 3}
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(arg)).Times(1).Return(accounts, nil)
+				store.EXPECT().CountAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				page := requireCursorPage(t, recorder.Body)
+				require.Len(t, page.Results, 2)
+				require.Equal(t, int64(4), page.Results[0].ID)
+				require.Nil(t, page.Total)
+				require.Equal(t, util.EncodeCursor("5", false), page.NextCursor)
+				require.Equal(t, util.EncodeCursor("4", true), page.PrevCursor)
+			},
+		},
+		{
+			name:  "PrevPage",
+			query: url.Values{"limit": {"2"}, "cursor": {util.EncodeCursor("7", true)}},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.ListAccountsReverseParams{Owner: "user", Cursor: 7, Limit: 3}
+				// the reverse query returns the closest accounts first
+				store.EXPECT().ListAccountsReverse(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return([]db.Account{accounts[2], accounts[1], accounts[0]}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				page := requireCursorPage(t, recorder.Body)
+				require.Equal(t, []db.Account{accounts[1], accounts[2]}, page.Results)
+				require.Equal(t, util.EncodeCursor("6", false), page.NextCursor)
+				require.Equal(t, util.EncodeCursor("5", true), page.PrevCursor)
+			},
+		},
+		{
+			name:  "InvalidCursor",
+			query: url.Values{"cursor": {"not-a-cursor"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "LimitTooLarge",
+			query: url.Values{"limit": {"101"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ListAccounts(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/api/v1/accounts?"+tc.query.Encode(), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, "user", "devoe", time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
 func TestGetAccountAPI(t *testing.T) {
 	user := randomUser()
 	account := randomAccount(user.Username)
@@ -234,7 +332,6 @@ func requireBodyMatchAccountList(t *testing.T, body *bytes.Buffer, expectedAccou
 		Status int `json:"status"`
 		Data   struct {
 			Results []db.Account `json:"results"`
-			Page    int          `json:"page"`
 			Limit   int          `json:"limit"`
 			Total   int          `json:"total"`
 		} `json:"data"`
@@ -249,7 +346,6 @@ func requireBodyMatchAccountList(t *testing.T, body *bytes.Buffer, expectedAccou
 	require.Nil(t, response.Error)
 
 	// Validate pagination metadata
-	require.Equal(t, 1, response.Data.Page)   //  Expected page
 	require.Equal(t, 10, response.Data.Limit) //  Expected limit
 	require.NotZero(t, response.Data.Total)   //  Ensure total is not zero
 
@@ -260,6 +356,17 @@ func requireBodyMatchAccountList(t *testing.T, body *bytes.Buffer, expectedAccou
 	}
 }
 
+func requireCursorPage(t *testing.T, body *bytes.Buffer) util.PaginatedData[db.Account] {
+	data, err := io.ReadAll(body)
+	require.NoError(t, err)
+
+	var response struct {
+		Data util.PaginatedData[db.Account] `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(data, &response))
+	return response.Data
+}
+
 func requireBodyMatchCreateAccount(t *testing.T, body *bytes.Buffer, account db.Account) {
 	data, err := io.ReadAll(body)
 	require.NoError(t, err)
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/S-Devoe/golang-simple-bank/apperr"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/gin-gonic/gin"
+)
+
+// register alias
+type createAliasRequest struct {
+	Alias string `json:"alias" binding:"required"`
+}
+
+func (server *Server) createAlias(ctx *gin.Context) error {
+	var req createAliasRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return bindingError(err)
+	}
+
+	kind, alias, err := util.ParseAlias(req.Alias)
+	if err != nil {
+		return apperr.Invalid(apperr.Field("alias", err.Error()))
+	}
+	if kind == util.AliasUsername {
+		return invalidArgument("usernames can always be paid and don't need to be registered", apperr.Field("alias", "must not be a username"))
+	}
+
+	authPayload, err := authorizationPayload(ctx)
+	if err != nil {
+		return err
+	}
+	user, err := server.store.GetUser(ctx, authPayload.Username)
+	if err != nil {
+		return err
+	}
+
+	// a tag is verified by being unique, an email only if it is the verified address on the user's profile.
+	// phone numbers stay unverified, and so don't resolve, until there is a code to confirm them with.
+	// only verified aliases are reserved, so an unverified one doesn't keep the owner of that email from it.
+	verified := kind == util.AliasTag ||
+		(kind == util.AliasEmail && user.IsEmailVerified && alias == strings.ToLower(user.Email))
+
+	arg := db.CreateAliasParams{
+		Username:   user.Username,
+		Alias:      alias,
+		Kind:       kind,
+		IsVerified: verified,
+	}
+	created, err := server.store.CreateAlias(ctx, arg)
+	if err != nil {
+		if db.ErrorCode(err) == db.UniqueViolation {
+			return apperr.New(apperr.AliasTaken, "alias is already taken")
+		}
+		return err
+	}
+	ctx.JSON(http.StatusCreated, util.CreateResponse(http.StatusCreated, created, nil))
+	return nil
+}
+
+// get aliases
+func (server *Server) listAliases(ctx *gin.Context) error {
+	authPayload, err := authorizationPayload(ctx)
+	if err != nil {
+		return err
+	}
+	aliases, err := server.store.ListAliases(ctx, authPayload.Username)
+	if err != nil {
+		return err
+	}
+	ctx.JSON(http.StatusOK, util.CreateResponse(http.StatusOK, aliases, nil))
+	return nil
+}
+
+// delete alias
+type deleteAliasRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) deleteAlias(ctx *gin.Context) error {
+	var req deleteAliasRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		return bindingError(err)
+	}
+
+	alias, err := server.store.GetAlias(ctx, req.ID)
+	if err != nil {
+		return orNotFound(err, apperr.AliasNotFound, "alias not found")
+	}
+
+	authPayload, err := authorizationPayload(ctx)
+	if err != nil {
+		return err
+	}
+	if alias.Username != authPayload.Username {
+		return apperr.New(apperr.AliasNotOwned, "alias does not belong to the authenticated user")
+	}
+
+	if err := server.store.DeleteAlias(ctx, req.ID); err != nil {
+		return err
+	}
+	ctx.JSON(http.StatusOK, util.CreateResponse(http.StatusOK, messageResponse{Message: "Alias deleted"}, nil))
+	return nil
+}
+
+// look up who an alias pays before sending money to it
+type resolveAliasRequest struct {
+	Alias    string `form:"alias" binding:"required"`
+	Currency string `form:"currency" binding:"required,currency"`
+}
+
+type resolveAliasResponse struct {
+	Alias       string `json:"alias"`
+	Currency    string `json:"currency"`
+	DisplayName string `json:"display_name"`
+}
+
+func (server *Server) resolveAlias(ctx *gin.Context) error {
+	var req resolveAliasRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		return bindingError(err)
+	}
+	if _, _, err := util.ParseAlias(req.Alias); err != nil {
+		return apperr.Invalid(apperr.Field("alias", err.Error()))
+	}
+
+	result, err := server.store.ResolveAlias(ctx, req.Alias, req.Currency)
+	if err != nil {
+		return orNotFound(err, apperr.AliasNotFound, "no account found for this alias and currency")
+	}
+
+	rsp := resolveAliasResponse{
+		Alias:       req.Alias,
+		Currency:    result.Account.Currency,
+		DisplayName: util.MaskName(result.Owner.FullName),
+	}
+	ctx.JSON(http.StatusOK, util.CreateResponse(http.StatusOK, rsp, nil))
+	return nil
+}
@@ -0,0 +1,14 @@
+package api
+
+import "github.com/gin-gonic/gin"
+
+func (server *Server) setUpAliasRoutes(router *gin.RouterGroup) {
+	aliasesGroup := router.Group("/aliases").Use(authMiddleware(server.tokenMaker, server.sessions))
+	{
+		// aliases endpoints
+		aliasesGroup.POST("", handle(server.createAlias))
+		aliasesGroup.GET("", handle(server.listAliases))
+		aliasesGroup.GET("/resolve", handle(server.resolveAlias))
+		aliasesGroup.DELETE("/:id", handle(server.deleteAlias))
+	}
+}
@@ -0,0 +1,348 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/S-Devoe/golang-simple-bank/apperr"
+	mockdb "github.com/S-Devoe/golang-simple-bank/db/mock"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateAliasAPI(t *testing.T) {
+	user := randomUser()
+
+	testCases := []struct {
+		name          string
+		alias         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "VerifiedTag",
+			alias: "@My_Tag",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				arg := db.CreateAliasParams{
+					Username:   user.Username,
+					Alias:      "@my_tag",
+					Kind:       util.AliasTag,
+					IsVerified: true,
+				}
+				store.EXPECT().CreateAlias(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Alias{ID: 1, Alias: arg.Alias}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:  "UnverifiedPhone",
+			alias: "+234 801-234-5678",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				arg := db.CreateAliasParams{
+					Username:   user.Username,
+					Alias:      "+2348012345678",
+					Kind:       util.AliasPhone,
+					IsVerified: false,
+				}
+				store.EXPECT().CreateAlias(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Alias{ID: 1, Alias: arg.Alias}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:  "UnverifiedProfileEmail",
+			alias: user.Email,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				arg := db.CreateAliasParams{
+					Username:   user.Username,
+					Alias:      strings.ToLower(user.Email),
+					Kind:       util.AliasEmail,
+					IsVerified: false,
+				}
+				store.EXPECT().CreateAlias(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Alias{ID: 1, Alias: arg.Alias}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:  "VerifiedProfileEmail",
+			alias: user.Email,
+			buildStubs: func(store *mockdb.MockStore) {
+				verifiedUser := user
+				verifiedUser.IsEmailVerified = true
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(verifiedUser, nil)
+				arg := db.CreateAliasParams{
+					Username:   user.Username,
+					Alias:      strings.ToLower(user.Email),
+					Kind:       util.AliasEmail,
+					IsVerified: true,
+				}
+				store.EXPECT().CreateAlias(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Alias{ID: 1, Alias: arg.Alias}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:  "OtherUsersEmail",
+			alias: "someone@else.com",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().CreateAlias(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ any, arg db.CreateAliasParams) (db.Alias, error) {
+						require.False(t, arg.IsVerified)
+						return db.Alias{ID: 1, Alias: arg.Alias}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:  "Username",
+			alias: "someusername",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAlias(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:  "AliasTaken",
+			alias: "@taken",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().CreateAlias(gomock.Any(), gomock.Any()).Times(1).Return(db.Alias{}, db.ErrUniqueViolation)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{"alias": tc.alias})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/api/v1/aliases", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Email, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestResolveAliasAPI(t *testing.T) {
+	user := randomUser()
+	recipient := randomUser()
+	recipient.FullName = "John Smith"
+	account := randomAccount(recipient.Username)
+	account.Currency = util.USD
+
+	testCases := []struct {
+		name          string
+		alias         string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:  "OK",
+			alias: "@john",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ResolveAlias(gomock.Any(), gomock.Eq("@john"), gomock.Eq(util.USD)).Times(1).
+					Return(db.ResolveAliasResult{Account: account, Owner: recipient}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				require.NoError(t, err)
+
+				var response struct {
+					Data resolveAliasResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(data, &response))
+				require.Equal(t, "J*** S****", response.Data.DisplayName)
+				require.Equal(t, util.USD, response.Data.Currency)
+			},
+		},
+		{
+			name:  "UsernameStartingWithDigit",
+			alias: "123abc",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ResolveAlias(gomock.Any(), gomock.Eq("123abc"), gomock.Eq(util.USD)).Times(1).
+					Return(db.ResolveAliasResult{Account: account, Owner: recipient}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:  "NotFound",
+			alias: "@nobody",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ResolveAlias(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					Return(db.ResolveAliasResult{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:  "InvalidAlias",
+			alias: "@x",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().ResolveAlias(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			query := url.Values{"alias": {tc.alias}, "currency": {util.USD}}
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/aliases/resolve?%s", query.Encode()), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Email, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestTransferToAliasAPI(t *testing.T) {
+	user := randomUser()
+	fromAccount := randomAccount(user.Username)
+	fromAccount.Currency = util.USD
+
+	testCases := []struct {
+		name          string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_alias": "@john", "currency": util.USD, "amount": 10},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				arg := db.TransferTxParams{
+					FromAccountID: fromAccount.ID,
+					Amount:        10,
+					ToAlias:       "@john",
+					Currency:      util.USD,
+					CooldownLimit: 1000,
+				}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferTxResult{
+					Transfer:    db.Transfer{Amount: 10},
+					FromAccount: db.Account{Balance: 90},
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name: "AliasNotFound",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_alias": "@nobody", "currency": util.USD, "amount": 10},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "AccountClosed",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_account_id": 5, "currency": util.USD, "amount": 10},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(int64(5))).Times(1).Return(db.Account{ID: 5, Currency: util.USD}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusNotFound, apperr.AccountNotFound)
+			},
+		},
+		{
+			name: "AliasAndAccountID",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_account_id": 5, "to_alias": "@john", "currency": util.USD, "amount": 10},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/api/v1/transfer/transfers", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Email, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/S-Devoe/golang-simple-bank/apperr"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultAnalyticsInterval = "month"
+	defaultAnalyticsRange    = 90 * 24 * time.Hour
+	topCounterpartiesLimit   = 5
+)
+
+type getAccountAnalyticsRequest struct {
+	Interval string    `form:"interval" binding:"omitempty,oneof=day week month"`
+	From     time.Time `form:"from" time_format:"2006-01-02" time_utc:"1"`
+	To       time.Time `form:"to" time_format:"2006-01-02" time_utc:"1"`
+}
+
+type analyticsTotals struct {
+	Inflow  float64 `json:"inflow"`
+	Outflow float64 `json:"outflow"`
+	Net     float64 `json:"net"`
+}
+
+// counterparties are shown by their masked name, as when resolving an alias
+type counterpartyResponse struct {
+	DisplayName   string  `json:"display_name"`
+	Received      float64 `json:"received"`
+	Sent          float64 `json:"sent"`
+	TransferCount int64   `json:"transfer_count"`
+}
+
+type accountAnalyticsResponse struct {
+	AccountID         int64                       `json:"account_id"`
+	Currency          string                      `json:"currency"`
+	Interval          string                      `json:"interval"`
+	From              time.Time                   `json:"from"`
+	To                time.Time                   `json:"to"`
+	Totals            analyticsTotals             `json:"totals"`
+	Periods           []db.GetAccountAnalyticsRow `json:"periods"`
+	TopCounterparties []counterpartyResponse      `json:"top_counterparties"`
+}
+
+// getAccountAnalytics reports inflow, outflow, net and average balance of an account per day, week or month.
+// It reads the daily rollups kept by TransferTx, so it doesn't have to scan the entries or transfers.
+func (server *Server) getAccountAnalytics(ctx *gin.Context) error {
+	var uri getAccountRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		return bindingError(err)
+	}
+	var req getAccountAnalyticsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		return bindingError(err)
+	}
+
+	if req.Interval == "" {
+		req.Interval = defaultAnalyticsInterval
+	}
+	if req.To.IsZero() {
+		req.To = time.Now().UTC().Truncate(24 * time.Hour)
+	}
+	if req.From.IsZero() {
+		req.From = req.To.Add(-defaultAnalyticsRange)
+	}
+	if req.From.After(req.To) {
+		return invalidArgument("from must not be after to", apperr.Field("from", "must not be after to"))
+	}
+	// the "to" day is included in the report
+	endTime := req.To.Add(24 * time.Hour)
+
+	account, err := server.ownedAccount(ctx, uri.ID)
+	if err != nil {
+		return err
+	}
+
+	periods, err := server.store.GetAccountAnalytics(ctx, db.GetAccountAnalyticsParams{
+		Interval:  req.Interval,
+		AccountID: account.ID,
+		StartTime: req.From,
+		EndTime:   endTime,
+	})
+	if err != nil {
+		return err
+	}
+
+	counterparties, err := server.store.ListTopCounterparties(ctx, db.ListTopCounterpartiesParams{
+		AccountID: account.ID,
+		StartTime: req.From,
+		EndTime:   endTime,
+		Limit:     topCounterpartiesLimit,
+	})
+	if err != nil {
+		return err
+	}
+
+	topCounterparties := make([]counterpartyResponse, len(counterparties))
+	for i, counterparty := range counterparties {
+		topCounterparties[i] = counterpartyResponse{
+			DisplayName:   util.MaskName(counterparty.CounterpartyName),
+			Received:      counterparty.Received,
+			Sent:          counterparty.Sent,
+			TransferCount: counterparty.TransferCount,
+		}
+	}
+
+	var totals analyticsTotals
+	for _, period := range periods {
+		totals.Inflow += period.Inflow
+		totals.Outflow += period.Outflow
+		totals.Net += period.Net
+	}
+
+	rsp := accountAnalyticsResponse{
+		AccountID:         account.ID,
+		Currency:          account.Currency,
+		Interval:          req.Interval,
+		From:              req.From,
+		To:                req.To,
+		Totals:            totals,
+		Periods:           periods,
+		TopCounterparties: topCounterparties,
+	}
+	ctx.JSON(http.StatusOK, util.CreateResponse(http.StatusOK, rsp, nil))
+	return nil
+}
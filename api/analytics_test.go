@@ -0,0 +1,128 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	mockdb "github.com/S-Devoe/golang-simple-bank/db/mock"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetAccountAnalyticsAPI(t *testing.T) {
+	user := randomUser()
+	account := randomAccount(user.Username)
+	other := randomUser()
+	other.FullName = "Jane Doe"
+
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 3, 31, 0, 0, 0, 0, time.UTC)
+	periods := []db.GetAccountAnalyticsRow{
+		{Period: from, Inflow: 100, Outflow: 40, Net: 60, AverageBalance: 500, EntryCount: 4},
+		{Period: from.AddDate(0, 1, 0), Inflow: 10, Outflow: 30, Net: -20, AverageBalance: 520, EntryCount: 2},
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		query         url.Values
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			query:    url.Values{"interval": {"month"}, "from": {"2024-01-01"}, "to": {"2024-03-31"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				arg := db.GetAccountAnalyticsParams{
+					Interval:  "month",
+					AccountID: account.ID,
+					StartTime: from,
+					EndTime:   to.Add(24 * time.Hour),
+				}
+				store.EXPECT().GetAccountAnalytics(gomock.Any(), gomock.Eq(arg)).Times(1).Return(periods, nil)
+				store.EXPECT().ListTopCounterparties(gomock.Any(), gomock.Any()).Times(1).
+					Return([]db.ListTopCounterpartiesRow{{CounterpartyName: other.FullName, Received: 10, Sent: 40, TransferCount: 3}}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := io.ReadAll(recorder.Body)
+				require.NoError(t, err)
+
+				var response struct {
+					Data accountAnalyticsResponse `json:"data"`
+				}
+				require.NoError(t, json.Unmarshal(data, &response))
+				require.Equal(t, analyticsTotals{Inflow: 110, Outflow: 70, Net: 40}, response.Data.Totals)
+				require.Len(t, response.Data.Periods, 2)
+				require.Equal(t, []counterpartyResponse{{DisplayName: "J*** D**", Received: 10, Sent: 40, TransferCount: 3}}, response.Data.TopCounterparties)
+				require.NotContains(t, string(data), other.Username)
+			},
+		},
+		{
+			name:     "InvalidInterval",
+			username: user.Username,
+			query:    url.Values{"interval": {"year"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountAnalytics(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "FromAfterTo",
+			username: user.Username,
+			query:    url.Values{"from": {"2024-04-01"}, "to": {"2024-03-31"}},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccountAnalytics(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "NotOwner",
+			username: other.Username,
+			query:    url.Values{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+				store.EXPECT().GetAccountAnalytics(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api/v1/accounts/%d/analytics?%s", account.ID, tc.query.Encode())
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, "", time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
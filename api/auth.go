@@ -1,11 +1,13 @@
 package api
 
 import (
-	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/S-Devoe/golang-simple-bank/apperr"
 	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/logging"
+	"github.com/S-Devoe/golang-simple-bank/metrics"
 	"github.com/S-Devoe/golang-simple-bank/util"
 	"github.com/S-Devoe/golang-simple-bank/util/password"
 	"github.com/gin-gonic/gin"
@@ -33,54 +35,52 @@ type LoginUserResponse struct {
 // @Produce json
 // @Param loginRequest body LoginUserRequest true "Login User Request"
 // @Success 200 {object} util.Response{data=LoginUserResponse} "Success"
-// @Failure 400 {object} util.Response "Bad Request"
-// @Failure 401 {object} util.Response "Unauthorized"
-// @Failure 404 {object} util.Response "Not Found"
-// @Failure 500 {object} util.Response "Internal Server Error"
+// @Failure 400 {object} apperr.Problem "Bad Request"
+// @Failure 401 {object} apperr.Problem "Unauthorized"
+// @Failure 404 {object} apperr.Problem "Not Found"
+// @Failure 500 {object} apperr.Problem "Internal Server Error"
 // @Router /login [post]
-func (s *Server) loginUser(ctx *gin.Context) {
+func (s *Server) loginUser(ctx *gin.Context) error {
 	var req LoginUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, util.CreateResponse(http.StatusBadRequest, nil, err))
-		return
+		return bindingError(err)
 	}
+	logging.With(ctx.Request.Context(), "username", req.Username)
 
 	user, err := s.store.GetUser(ctx, req.Username)
 	if err != nil {
 		if err == db.ErrRecordNotFound {
-			ctx.JSON(http.StatusNotFound, util.CreateResponse(http.StatusNotFound, nil, "User not found"))
-			return
+			metrics.Logins.WithLabelValues(metrics.TransportHTTP, metrics.LoginFailed).Inc()
+			return apperr.New(apperr.UserNotFound, "user not found")
 		}
-		ctx.JSON(http.StatusInternalServerError, util.CreateResponse(http.StatusInternalServerError, nil, err))
-		return
+		return err
 	}
 
 	passwordMatch, err := password.ComparePasswordAndHash(req.Password, user.HashedPassword)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, util.CreateResponse(http.StatusUnauthorized, nil, err))
-		return
+		return apperr.New(apperr.InvalidCredentials, "invalid username or password")
 	}
 
 	if !passwordMatch {
-		ctx.JSON(http.StatusUnauthorized, util.CreateResponse(http.StatusUnauthorized, nil, "invalid username or password"))
-		return
-	}
-
-	accessToken, accessPayload, err := s.tokenMaker.CreateToken(user.Username, user.Email, s.config.AccessTokenDuration)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, util.CreateResponse(http.StatusInternalServerError, nil, err))
-		return
+		metrics.Logins.WithLabelValues(metrics.TransportHTTP, metrics.LoginFailed).Inc()
+		return apperr.New(apperr.InvalidCredentials, "invalid username or password")
 	}
 
 	refreshToken, refreshPayload, err := s.tokenMaker.CreateToken(
 		user.Username,
 		user.Email,
+		"",
 		s.config.RefreshTokenDuration,
 	)
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, util.CreateResponse(http.StatusInternalServerError, nil, err))
-		return
+		return err
+	}
+
+	// the access token is only honoured while the session it belongs to is live
+	accessToken, accessPayload, err := s.tokenMaker.CreateToken(user.Username, user.Email, refreshPayload.ID.String(), s.config.AccessTokenDuration)
+	if err != nil {
+		return err
 	}
 
 	session, err := s.store.CreateSession(ctx, db.CreateSessionParams{
@@ -93,9 +93,7 @@ func (s *Server) loginUser(ctx *gin.Context) {
 		ExpiresAt:    refreshPayload.ExpiredAt,
 	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, util.CreateResponse(http.StatusInternalServerError, nil, err))
-		fmt.Println(err)
-		return
+		return err
 	}
 
 	rsp := LoginUserResponse{
@@ -107,7 +105,9 @@ func (s *Server) loginUser(ctx *gin.Context) {
 		RefreshTokenExpiresAt: refreshPayload.ExpiredAt,
 	}
 
+	metrics.Logins.WithLabelValues(metrics.TransportHTTP, metrics.LoginSucceeded).Inc()
 	ctx.JSON(http.StatusOK, util.CreateResponse(http.StatusOK, rsp, nil))
+	return nil
 }
 
 type renewAccessTokenRequest struct {
@@ -127,66 +127,58 @@ type renewAccessTokenResponse struct {
 // @Produce json
 // @Param renewRequest body renewAccessTokenRequest true "Request body to renew access/refresh tokens"
 // @Success 200 {object} util.Response{data=renewAccessTokenResponse} "Success"
-// @Failure 400 {object} util.Response "Bad Request"
-// @Failure 401 {object} util.Response "Unauthorized"
-// @Failure 404 {object} util.Response "Session Not Found"
-// @Failure 500 {object} util.Response "Internal Server Error"
+// @Failure 400 {object} apperr.Problem "Bad Request"
+// @Failure 401 {object} apperr.Problem "Unauthorized"
+// @Failure 404 {object} apperr.Problem "Session Not Found"
+// @Failure 500 {object} apperr.Problem "Internal Server Error"
 // @Router /token/renew [post]
-func (s *Server) renewAccessToken(ctx *gin.Context) {
+func (s *Server) renewAccessToken(ctx *gin.Context) error {
 	var req renewAccessTokenRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, util.CreateResponse(http.StatusBadRequest, nil, err))
-		return
-	}
-	refreshPayload, err := s.tokenMaker.VerifyToken(req.RefreshToken)
-	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, util.CreateResponse(http.StatusUnauthorized, nil, "Expired or invalid refresh token, please login again"))
-		return
+		return bindingError(err)
 	}
 
-	session, err := s.store.GetSession(ctx, string(refreshPayload.ID.String()))
+	accessToken, accessPayload, err := s.sessions.Renew(ctx, req.RefreshToken)
 	if err != nil {
-		if err == db.ErrRecordNotFound {
-			ctx.JSON(http.StatusNotFound, util.CreateResponse(http.StatusNotFound, nil, err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, util.CreateResponse(http.StatusInternalServerError, nil, err))
-		return
+		return err
 	}
 
-	if session.IsBlocked {
-		ctx.JSON(http.StatusUnauthorized, util.CreateResponse(http.StatusUnauthorized, nil, "Expired or invalid refresh token, please login again"))
-		return
+	rsp := renewAccessTokenResponse{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: accessPayload.ExpiredAt,
 	}
 
-	if session.Username != refreshPayload.Username {
-		ctx.JSON(http.StatusUnauthorized, util.CreateResponse(http.StatusUnauthorized, nil, "Incorrect session user"))
-		return
-	}
-	if session.RefreshToken != req.RefreshToken {
-		ctx.JSON(http.StatusUnauthorized, util.CreateResponse(http.StatusUnauthorized, nil, "mismathced session token"))
-		return
-	}
-	if time.Now().After(session.ExpiresAt) {
-		ctx.JSON(http.StatusUnauthorized, util.CreateResponse(http.StatusUnauthorized, nil, "session expired"))
-		return
-	}
+	ctx.JSON(http.StatusOK, util.CreateResponse(http.StatusOK, rsp, nil))
+	return nil
+}
 
-	accessToken, accessPayload, err := s.tokenMaker.CreateToken(
-		refreshPayload.Username,
-		refreshPayload.Email,
-		s.config.AccessTokenDuration,
-	)
+type logoutUserRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
 
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, util.CreateResponse(http.StatusInternalServerError, nil, err))
-		return
+// Logout godoc
+// @Summary Logout
+// @Description Block the session behind a refresh token so it can no longer renew access tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param logoutRequest body logoutUserRequest true "Request body with the refresh token of the session to end"
+// @Success 200 {object} util.Response "Success"
+// @Failure 400 {object} apperr.Problem "Bad Request"
+// @Failure 401 {object} apperr.Problem "Unauthorized"
+// @Failure 404 {object} apperr.Problem "Session Not Found"
+// @Failure 500 {object} apperr.Problem "Internal Server Error"
+// @Router /logout [post]
+func (s *Server) logoutUser(ctx *gin.Context) error {
+	var req logoutUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return bindingError(err)
 	}
 
-	rsp := renewAccessTokenResponse{
-		AccessToken:          accessToken,
-		AccessTokenExpiresAt: accessPayload.ExpiredAt,
+	if _, err := s.sessions.Logout(ctx, req.RefreshToken); err != nil {
+		return err
 	}
 
-	ctx.JSON(http.StatusOK, util.CreateResponse(http.StatusOK, rsp, nil))
+	ctx.JSON(http.StatusOK, util.CreateResponse(http.StatusOK, nil, nil))
+	return nil
 }
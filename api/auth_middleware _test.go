@@ -7,9 +7,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/S-Devoe/golang-simple-bank/apperr"
+	mockdb "github.com/S-Devoe/golang-simple-bank/db/mock"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
 	"github.com/S-Devoe/golang-simple-bank/token"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 )
 
 func addAuthorization(
@@ -21,7 +25,7 @@ func addAuthorization(
 	email string,
 	duration time.Duration,
 ) {
-	token, payload, err := tokenMaker.CreateToken(username, email, duration)
+	token, payload, err := tokenMaker.CreateToken(username, email, testSessionID(username), duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -33,6 +37,7 @@ func addAuthorization(
 func TestAuthMiddleware(t *testing.T) {
 	testCases := []struct {
 		name          string
+		buildStubs    func(store *mockdb.MockStore)
 		setupAuth     func(t *testing.T, request *http.Request, tokenMaker token.Maker)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{
@@ -80,18 +85,82 @@ func TestAuthMiddleware(t *testing.T) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
 		},
+		{
+			// a refresh token names no session of its own and is not an access token
+			name: "RefreshToken",
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				refreshToken, _, err := tokenMaker.CreateToken("user", "devoe", "", time.Minute)
+				require.NoError(t, err)
+				req.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, refreshToken))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusUnauthorized, apperr.Unauthenticated)
+			},
+		},
+		{
+			name: "LoggedOutSession",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAuthSession(gomock.Any(), gomock.Eq("logged-out")).Times(1).
+					Return(db.GetAuthSessionRow{Session: db.Session{ID: "logged-out", Username: "user", IsBlocked: true, ExpiresAt: time.Now().Add(time.Hour)}}, nil)
+			},
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				accessToken, _, err := tokenMaker.CreateToken("user", "devoe", "logged-out", time.Minute)
+				require.NoError(t, err)
+				req.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusUnauthorized, apperr.Unauthenticated)
+			},
+		},
+		{
+			name: "PasswordChangedSinceIssue",
+			buildStubs: func(store *mockdb.MockStore) {
+				session := db.Session{ID: "before-password-change", Username: "user", ExpiresAt: time.Now().Add(time.Hour)}
+				store.EXPECT().GetAuthSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).
+					Return(db.GetAuthSessionRow{Session: session, PasswordChangedAt: time.Now().Add(time.Minute)}, nil)
+			},
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				accessToken, _, err := tokenMaker.CreateToken("user", "devoe", "before-password-change", time.Minute)
+				require.NoError(t, err)
+				req.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusUnauthorized, apperr.Unauthenticated)
+			},
+		},
+		{
+			name: "DeletedSession",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAuthSession(gomock.Any(), gomock.Eq("deleted")).Times(1).Return(db.GetAuthSessionRow{}, db.ErrRecordNotFound)
+			},
+			setupAuth: func(t *testing.T, req *http.Request, tokenMaker token.Maker) {
+				accessToken, _, err := tokenMaker.CreateToken("user", "devoe", "deleted", time.Minute)
+				require.NoError(t, err)
+				req.Header.Set(authorizationHeaderKey, fmt.Sprintf("%s %s", authorizationTypeBearer, accessToken))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireProblem(t, recorder, http.StatusUnauthorized, apperr.Unauthenticated)
+			},
+		},
 	}
 
 	for i := range testCases {
 		tc := testCases[i]
 
 		t.Run(tc.name, func(t *testing.T) {
-			server := newTestServer(t, nil)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			if tc.buildStubs != nil {
+				tc.buildStubs(store)
+			}
+			server := newTestServer(t, store)
 
 			authPath := "/api/v1/auth"
 			server.router.GET(
 				authPath,
-				authMiddleware(server.tokenMaker),
+				authMiddleware(server.tokenMaker, server.sessions),
 				func(ctx *gin.Context) {
 					ctx.JSON(http.StatusOK, gin.H{})
 				},
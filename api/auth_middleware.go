@@ -3,9 +3,12 @@ package api
 import (
 	"errors"
 	"fmt"
-	"net/http"
 	"strings"
 
+	"github.com/S-Devoe/golang-simple-bank/apperr"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/logging"
+	"github.com/S-Devoe/golang-simple-bank/session"
 	"github.com/S-Devoe/golang-simple-bank/token"
 	"github.com/S-Devoe/golang-simple-bank/util"
 	"github.com/gin-gonic/gin"
@@ -17,37 +20,72 @@ const (
 	authorizationPayloadKey = "authorization_payload"
 )
 
-func authMiddleware(tokenMaker token.Maker) gin.HandlerFunc {
+// authMiddleware accepts access tokens whose session is still live
+func authMiddleware(tokenMaker token.Maker, sessions *session.Service) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(authorizationHeaderKey)
 		if len(authorizationHeader) == 0 {
-			err := errors.New("authorization header is not provided")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, util.CreateResponse(http.StatusUnauthorized, nil, err))
+			abort(ctx, apperr.New(apperr.Unauthenticated, "authorization header is not provided"))
 			return
 		}
 
 		// split the authorization header into two strings/part, i.e bearer and the token string
 		fields := strings.Fields(authorizationHeader)
 		if len(fields) < 2 {
-			err := errors.New("invalid authorization header format")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, util.CreateResponse(http.StatusUnauthorized, nil, err))
+			abort(ctx, apperr.New(apperr.Unauthenticated, "invalid authorization header format"))
 			return
 		}
 
 		authorizationType := strings.ToLower(fields[0])
 		if authorizationType != authorizationTypeBearer {
-			err := fmt.Errorf("unsupported authorization type %s", authorizationType)
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, util.CreateResponse(http.StatusUnauthorized, nil, err))
+			abort(ctx, apperr.New(apperr.Unauthenticated, fmt.Sprintf("unsupported authorization type %s", authorizationType)))
 			return
 		}
 
 		accessToken := fields[1]
 		payload, err := tokenMaker.VerifyToken(accessToken)
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, util.CreateResponse(http.StatusUnauthorized, nil, err))
+			abort(ctx, apperr.New(apperr.Unauthenticated, err.Error()))
+			return
+		}
+		if err := sessions.Authorize(ctx, payload); err != nil {
+			abort(ctx, err)
 			return
 		}
 		ctx.Set(authorizationPayloadKey, payload)
+		logging.With(ctx.Request.Context(), "username", payload.Username)
 		ctx.Next()
 	}
 }
+
+// adminMiddleware lets through only users holding the admin role; it must run after authMiddleware
+func adminMiddleware(store db.Store) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		payload, err := authorizationPayload(ctx)
+		if err != nil {
+			abort(ctx, err)
+			return
+		}
+		user, err := store.GetUser(ctx, payload.Username)
+		if err != nil {
+			abort(ctx, orNotFound(err, apperr.Unauthenticated, "user not found"))
+			return
+		}
+		if user.Role != util.AdminRole {
+			abort(ctx, apperr.New(apperr.PermissionDenied, "admin role required"))
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// authorizationPayload returns the payload authMiddleware stored. A route registered without the middleware
+// gets a 500 rather than a panic.
+func authorizationPayload(ctx *gin.Context) (*token.Payload, error) {
+	if payload, ok := ctx.Get(authorizationPayloadKey); ok {
+		if payload, ok := payload.(*token.Payload); ok {
+			return payload, nil
+		}
+	}
+	return nil, errors.New("no authorization payload, the route is missing authMiddleware")
+}
@@ -6,8 +6,10 @@ func (server *Server) setUpAuthRoutes(router *gin.RouterGroup) {
 	authGroup := router.Group("")
 	{
 		// auth
-		authGroup.POST("/login", server.loginUser)
-		authGroup.POST("/signup", server.createUser)
-		authGroup.POST("/token/renew", server.renewAccessToken)
+		authGroup.POST("/login", handle(server.loginUser))
+		authGroup.POST("/signup", handle(server.createUser))
+		authGroup.POST("/token/renew", handle(server.renewAccessToken))
+		authGroup.POST("/logout", handle(server.logoutUser))
+		authGroup.GET("/verify_email", handle(server.verifyEmail))
 	}
 }
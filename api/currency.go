@@ -0,0 +1,173 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/S-Devoe/golang-simple-bank/apperr"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// currencyResponse is the public view of a registry entry
+type currencyResponse struct {
+	Code              string  `json:"code"`
+	Exponent          int16   `json:"exponent"`
+	Symbol            string  `json:"symbol"`
+	MinTransferAmount float64 `json:"min_transfer_amount"`
+	MaxTransferAmount float64 `json:"max_transfer_amount"`
+	// transfers above PayeeCooldownLimit need the recipient to be an established payee
+	PayeeCooldownLimit float64 `json:"payee_cooldown_limit"`
+}
+
+func newCurrencyResponse(currency db.Currency) currencyResponse {
+	return currencyResponse{
+		Code:               currency.Code,
+		Exponent:           currency.Exponent,
+		Symbol:             currency.Symbol,
+		MinTransferAmount:  currency.MinTransferAmount,
+		MaxTransferAmount:  currency.MaxTransferAmount,
+		PayeeCooldownLimit: currency.PayeeCooldownLimit,
+	}
+}
+
+// list the currencies accounts can be opened and transfers made in
+func (server *Server) listCurrencies(ctx *gin.Context) error {
+	currencies, err := server.store.ListEnabledCurrencies(ctx)
+	if err != nil {
+		return err
+	}
+
+	res := make([]currencyResponse, 0, len(currencies))
+	for _, currency := range currencies {
+		res = append(res, newCurrencyResponse(currency))
+	}
+	ctx.JSON(http.StatusOK, util.CreateResponse(http.StatusOK, res, nil))
+	return nil
+}
+
+// list every currency in the registry, disabled ones included
+func (server *Server) listAllCurrencies(ctx *gin.Context) error {
+	currencies, err := server.store.ListCurrencies(ctx)
+	if err != nil {
+		return err
+	}
+	ctx.JSON(http.StatusOK, util.CreateResponse(http.StatusOK, currencies, nil))
+	return nil
+}
+
+// add a currency to the registry
+type createCurrencyRequest struct {
+	Code               string  `json:"code" binding:"required,len=3,uppercase"`
+	Exponent           *int16  `json:"exponent" binding:"required,min=0,max=4"`
+	Symbol             string  `json:"symbol" binding:"required"`
+	Enabled            *bool   `json:"enabled"`
+	MinTransferAmount  float64 `json:"min_transfer_amount" binding:"required,gt=0"`
+	MaxTransferAmount  float64 `json:"max_transfer_amount" binding:"required,gtefield=MinTransferAmount"`
+	PayeeCooldownLimit float64 `json:"payee_cooldown_limit" binding:"required,gt=0"`
+}
+
+func (server *Server) createCurrency(ctx *gin.Context) error {
+	var req createCurrencyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return bindingError(err)
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+	arg := db.CreateCurrencyParams{
+		Code:               req.Code,
+		Exponent:           *req.Exponent,
+		Symbol:             req.Symbol,
+		Enabled:            enabled,
+		MinTransferAmount:  req.MinTransferAmount,
+		MaxTransferAmount:  req.MaxTransferAmount,
+		PayeeCooldownLimit: req.PayeeCooldownLimit,
+	}
+	currency, err := server.store.CreateCurrency(ctx, arg)
+	if err != nil {
+		if db.ErrorCode(err) == db.UniqueViolation {
+			return apperr.New(apperr.CurrencyExists, "currency already exists")
+		}
+		return err
+	}
+
+	server.currencies.invalidate()
+	ctx.JSON(http.StatusCreated, util.CreateResponse(http.StatusCreated, currency, nil))
+	return nil
+}
+
+// update a currency; the exponent is fixed once created since existing balances depend on it
+type updateCurrencyUri struct {
+	Code string `uri:"code" binding:"required,len=3"`
+}
+
+type updateCurrencyRequest struct {
+	Symbol             *string  `json:"symbol" binding:"omitempty,min=1"`
+	Enabled            *bool    `json:"enabled"`
+	MinTransferAmount  *float64 `json:"min_transfer_amount" binding:"omitempty,gt=0"`
+	MaxTransferAmount  *float64 `json:"max_transfer_amount" binding:"omitempty,gt=0"`
+	PayeeCooldownLimit *float64 `json:"payee_cooldown_limit" binding:"omitempty,gt=0"`
+}
+
+func (server *Server) updateCurrency(ctx *gin.Context) error {
+	var uri updateCurrencyUri
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		return bindingError(err)
+	}
+	var req updateCurrencyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return bindingError(err)
+	}
+
+	arg := db.UpdateCurrencyParams{Code: uri.Code}
+	if req.Symbol != nil {
+		arg.Symbol = pgtype.Text{String: *req.Symbol, Valid: true}
+	}
+	if req.Enabled != nil {
+		arg.Enabled = pgtype.Bool{Bool: *req.Enabled, Valid: true}
+	}
+	if req.MinTransferAmount != nil {
+		arg.MinTransferAmount = pgtype.Float8{Float64: *req.MinTransferAmount, Valid: true}
+	}
+	if req.MaxTransferAmount != nil {
+		arg.MaxTransferAmount = pgtype.Float8{Float64: *req.MaxTransferAmount, Valid: true}
+	}
+	if req.PayeeCooldownLimit != nil {
+		arg.PayeeCooldownLimit = pgtype.Float8{Float64: *req.PayeeCooldownLimit, Valid: true}
+	}
+
+	currency, err := server.store.UpdateCurrency(ctx, arg)
+	if err != nil {
+		if err == db.ErrRecordNotFound {
+			return apperr.New(apperr.CurrencyNotFound, "currency not found")
+		}
+		if db.ErrorCode(err) == db.CheckViolation {
+			return apperr.Invalid(apperr.Field("max_transfer_amount", "must not be below min_transfer_amount"))
+		}
+		return err
+	}
+
+	server.currencies.invalidate()
+	ctx.JSON(http.StatusOK, util.CreateResponse(http.StatusOK, currency, nil))
+	return nil
+}
+
+// checkTransferAmount enforces the registry's limits and precision for the transfer currency
+func (server *Server) checkTransferAmount(ctx *gin.Context, code string, amount float64) (db.Currency, error) {
+	currency, ok := server.currencies.get(ctx, code)
+	if !ok {
+		return currency, apperr.Invalid(apperr.Field("currency", "unsupported currency"))
+	}
+	if !util.HasValidPrecision(amount, currency.Exponent) {
+		return currency, apperr.Invalid(apperr.Field("amount", fmt.Sprintf("%s amounts can have at most %d decimals", currency.Code, currency.Exponent)))
+	}
+	if amount < currency.MinTransferAmount || amount > currency.MaxTransferAmount {
+		return currency, apperr.Invalid(apperr.Field("amount", fmt.Sprintf("%s transfers must be between %.2f and %.2f", currency.Code, currency.MinTransferAmount, currency.MaxTransferAmount)))
+	}
+	return currency, nil
+}
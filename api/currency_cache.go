@@ -0,0 +1,84 @@
+package api
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/logging"
+	"golang.org/x/sync/singleflight"
+)
+
+// currencyCache holds the enabled currencies of the registry, reloading them once they are older than ttl
+type currencyCache struct {
+	store      db.Store
+	ttl        time.Duration
+	mu         sync.RWMutex
+	currencies map[string]db.Currency
+	expiresAt  time.Time
+	// requests arriving while the copy is stale share a single reload
+	reloads singleflight.Group
+}
+
+func newCurrencyCache(store db.Store, ttl time.Duration) *currencyCache {
+	return &currencyCache{
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+// get returns the enabled currency with the given code
+func (c *currencyCache) get(ctx context.Context, code string) (db.Currency, bool) {
+	c.mu.RLock()
+	fresh := time.Now().Before(c.expiresAt)
+	currency, ok := c.currencies[code]
+	c.mu.RUnlock()
+	if fresh {
+		return currency, ok
+	}
+
+	if err := c.load(ctx); err != nil {
+		// keep serving the stale copy rather than rejecting every currency while the database is unavailable
+		logging.FromContext(ctx).Warn("cannot reload currency registry, serving the stale copy", "error", err)
+		return currency, ok
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	currency, ok = c.currencies[code]
+	return currency, ok
+}
+
+func (c *currencyCache) load(ctx context.Context) error {
+	// the reload outlives a caller that gives up, the others waiting on it still want the result
+	ctx = context.WithoutCancel(ctx)
+	_, err, _ := c.reloads.Do("currencies", func() (any, error) {
+		currencies, err := c.store.ListEnabledCurrencies(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.set(currencies)
+		return nil, nil
+	})
+	return err
+}
+
+func (c *currencyCache) set(currencies []db.Currency) {
+	byCode := make(map[string]db.Currency, len(currencies))
+	for _, currency := range currencies {
+		byCode[currency.Code] = currency
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.currencies = byCode
+	c.expiresAt = time.Now().Add(c.ttl)
+}
+
+// invalidate makes the next lookup reload the registry
+func (c *currencyCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.expiresAt = time.Time{}
+}
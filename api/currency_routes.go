@@ -0,0 +1,19 @@
+package api
+
+import "github.com/gin-gonic/gin"
+
+func (server *Server) setUpCurrencyRoutes(router *gin.RouterGroup) {
+	currenciesGroup := router.Group("/currencies")
+	{
+		// public currencies endpoint
+		currenciesGroup.GET("", handle(server.listCurrencies))
+	}
+
+	adminGroup := router.Group("/admin/currencies").Use(authMiddleware(server.tokenMaker, server.sessions), adminMiddleware(server.store))
+	{
+		// currency registry management
+		adminGroup.GET("", handle(server.listAllCurrencies))
+		adminGroup.POST("", handle(server.createCurrency))
+		adminGroup.PATCH("/:code", handle(server.updateCurrency))
+	}
+}
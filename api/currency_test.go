@@ -0,0 +1,326 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	mockdb "github.com/S-Devoe/golang-simple-bank/db/mock"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListCurrenciesAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().ListEnabledCurrencies(gomock.Any()).Times(1).Return(testCurrencies, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	// the list is public, no authorization header
+	request, err := http.NewRequest(http.MethodGet, "/api/v1/currencies", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var response struct {
+		Data []currencyResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	require.Len(t, response.Data, len(testCurrencies))
+	require.Equal(t, newCurrencyResponse(testCurrencies[0]), response.Data[0])
+}
+
+func TestCreateCurrencyAPI(t *testing.T) {
+	admin := randomUser()
+	admin.Role = util.AdminRole
+	user := randomUser()
+	user.Role = util.DepositorRole
+
+	gbp := db.Currency{Code: "GBP", Exponent: 2, Symbol: "£", Enabled: true, MinTransferAmount: 1, MaxTransferAmount: 5000, PayeeCooldownLimit: 800}
+
+	testCases := []struct {
+		name          string
+		username      string
+		body          gin.H
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: admin.Username,
+			body: gin.H{
+				"code":                 "GBP",
+				"exponent":             2,
+				"symbol":               "£",
+				"min_transfer_amount":  1,
+				"max_transfer_amount":  5000,
+				"payee_cooldown_limit": 800,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				arg := db.CreateCurrencyParams{
+					Code:               "GBP",
+					Exponent:           2,
+					Symbol:             "£",
+					Enabled:            true,
+					MinTransferAmount:  1,
+					MaxTransferAmount:  5000,
+					PayeeCooldownLimit: 800,
+				}
+				store.EXPECT().CreateCurrency(gomock.Any(), gomock.Eq(arg)).Times(1).Return(gbp, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:     "ZeroExponent",
+			username: admin.Username,
+			body: gin.H{
+				"code":                 "JPY",
+				"exponent":             0,
+				"symbol":               "¥",
+				"enabled":              false,
+				"min_transfer_amount":  100,
+				"max_transfer_amount":  1000000,
+				"payee_cooldown_limit": 150000,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				arg := db.CreateCurrencyParams{
+					Code:               "JPY",
+					Exponent:           0,
+					Symbol:             "¥",
+					Enabled:            false,
+					MinTransferAmount:  100,
+					MaxTransferAmount:  1000000,
+					PayeeCooldownLimit: 150000,
+				}
+				store.EXPECT().CreateCurrency(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Currency{Code: "JPY"}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+			},
+		},
+		{
+			name:     "NotAdmin",
+			username: user.Username,
+			body:     gin.H{"code": "GBP", "exponent": 2, "symbol": "£", "min_transfer_amount": 1, "max_transfer_amount": 5000, "payee_cooldown_limit": 800},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+				store.EXPECT().CreateCurrency(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name:     "MaxBelowMin",
+			username: admin.Username,
+			body:     gin.H{"code": "GBP", "exponent": 2, "symbol": "£", "min_transfer_amount": 10, "max_transfer_amount": 5},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().CreateCurrency(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:     "DuplicateCode",
+			username: admin.Username,
+			body:     gin.H{"code": "USD", "exponent": 2, "symbol": "$", "min_transfer_amount": 1, "max_transfer_amount": 5000, "payee_cooldown_limit": 800},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+				store.EXPECT().CreateCurrency(gomock.Any(), gomock.Any()).Times(1).Return(db.Currency{}, db.ErrUniqueViolation)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/api/v1/admin/currencies", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, "", time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestUpdateCurrencyAPI(t *testing.T) {
+	admin := randomUser()
+	admin.Role = util.AdminRole
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(admin.Username)).Times(1).Return(admin, nil)
+	arg := db.UpdateCurrencyParams{
+		Code:    util.NGN,
+		Enabled: pgtype.Bool{Bool: false, Valid: true},
+	}
+	store.EXPECT().UpdateCurrency(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.Currency{Code: util.NGN}, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodPatch, "/api/v1/admin/currencies/NGN", bytes.NewReader([]byte(`{"enabled":false}`)))
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, admin.Username, "", time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// the update drops the cached registry, so the next currency check reloads it
+	store.EXPECT().ListEnabledCurrencies(gomock.Any()).Times(1).Return(testCurrencies[:1], nil)
+	_, ok := server.currencies.get(context.Background(), util.NGN)
+	require.False(t, ok)
+}
+
+func TestTransferAmountPrecisionAPI(t *testing.T) {
+	user := randomUser()
+	jpy := db.Currency{Code: "JPY", Exponent: 0, Symbol: "¥", Enabled: true, MinTransferAmount: 100, MaxTransferAmount: 1000000}
+
+	testCases := []struct {
+		name   string
+		amount float64
+	}{
+		{name: "TooManyDecimals", amount: 150.5},
+		{name: "BelowMinimum", amount: 50},
+		{name: "AboveMaximum", amount: 2000000},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+
+			server := newTestServer(t, store)
+			server.currencies.set(append(testCurrencies, jpy))
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(gin.H{
+				"from_account_id": 1,
+				"to_account_id":   2,
+				"currency":        "JPY",
+				"amount":          tc.amount,
+			})
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/api/v1/transfer/transfers", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Email, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusBadRequest, recorder.Code)
+		})
+	}
+}
+
+func TestTransferBelowOneUnitAPI(t *testing.T) {
+	user := randomUser()
+	fromAccount := randomAccount(user.Username)
+	fromAccount.Currency = util.USD
+	toAccount := randomAccount(randomUser().Username)
+	toAccount.ID = fromAccount.ID + 1
+	toAccount.Currency = util.USD
+	usd := testCurrencies[0]
+	usd.MinTransferAmount = 0.01
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+	store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(_ any, arg db.TransferTxParams) (db.TransferTxResult, error) {
+			require.Equal(t, 0.5, arg.Amount)
+			return db.TransferTxResult{Transfer: db.Transfer{Amount: arg.Amount}, FromAccount: db.Account{Balance: 1}}, nil
+		})
+
+	server := newTestServer(t, store)
+	// the registry minimum applies, not one whole unit
+	server.currencies.set([]db.Currency{usd})
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{
+		"from_account_id": fromAccount.ID,
+		"to_account_id":   toAccount.ID,
+		"currency":        util.USD,
+		"amount":          0.5,
+	})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPost, "/api/v1/transfer/transfers", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Email, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+}
+
+func TestCurrencyCacheSingleReload(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	release := make(chan struct{})
+	store := mockdb.NewMockStore(ctrl)
+	// every lookup below finds the copy stale, but only one of them reloads it
+	store.EXPECT().ListEnabledCurrencies(gomock.Any()).Times(1).
+		DoAndReturn(func(context.Context) ([]db.Currency, error) {
+			<-release
+			return testCurrencies, nil
+		})
+
+	cache := newCurrencyCache(store, time.Minute)
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, ok := cache.get(context.Background(), util.USD)
+			require.True(t, ok)
+		}()
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"github.com/S-Devoe/golang-simple-bank/apperr"
+	"github.com/S-Devoe/golang-simple-bank/logging"
+	"github.com/gin-gonic/gin"
+)
+
+// handlerFunc is a gin handler that returns its failure instead of answering it, errorMiddleware does that
+type handlerFunc func(ctx *gin.Context) error
+
+// handle adapts h to gin, leaving its error on the context for errorMiddleware
+func handle(h handlerFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if err := h(ctx); err != nil {
+			ctx.Error(err)
+		}
+	}
+}
+
+// abort stops the handler chain and leaves err for errorMiddleware, for middleware that turns a request away
+func abort(ctx *gin.Context, err error) {
+	ctx.Error(err)
+	ctx.Abort()
+}
+
+// errorMiddleware answers the last error the handlers left, unless they already wrote a response.
+// The error stays on the context, so loggingMiddleware still logs the cause of a 500.
+func errorMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Next()
+
+		if len(ctx.Errors) == 0 || ctx.Writer.Written() {
+			return
+		}
+		respondError(ctx, ctx.Errors.Last().Err)
+	}
+}
+
+// recoveryMiddleware turns a panic into a logged 500 problem carrying the request id, instead of gin's plain text one
+func recoveryMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			// net/http uses this panic to abort a response on purpose, it is not a bug to report
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			logging.FromContext(ctx.Request.Context()).Error("panic recovered",
+				"panic", fmt.Sprint(recovered),
+				"stack", string(debug.Stack()),
+			)
+			ctx.Error(fmt.Errorf("panic: %v", recovered))
+			if ctx.Writer.Written() {
+				ctx.Abort()
+				return
+			}
+			respondProblem(ctx, apperr.New(apperr.Internal, "internal server error"))
+		}()
+		ctx.Next()
+	}
+}
+
+// bodyLimitMiddleware refuses request bodies larger than limit bytes, up front when the client declares
+// its length and otherwise once binding reads past the limit
+func bodyLimitMiddleware(limit int64) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if limit <= 0 {
+			ctx.Next()
+			return
+		}
+		if ctx.Request.ContentLength > limit {
+			abort(ctx, apperr.New(apperr.RequestTooLarge, fmt.Sprintf("request body must not exceed %d bytes", limit)))
+			return
+		}
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, limit)
+		ctx.Next()
+	}
+}
+
+// timeoutMiddleware bounds the request context, so the store calls of a slow request are cancelled and
+// errorMiddleware answers 504. Routes listed in routeTimeouts by their pattern get that timeout instead of the default.
+func timeoutMiddleware(timeout time.Duration, routeTimeouts map[string]time.Duration) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		routeTimeout, ok := routeTimeouts[ctx.FullPath()]
+		if !ok {
+			routeTimeout = timeout
+		}
+		if routeTimeout <= 0 {
+			ctx.Next()
+			return
+		}
+
+		requestCtx, cancel := context.WithTimeout(ctx.Request.Context(), routeTimeout)
+		defer cancel()
+		ctx.Request = ctx.Request.WithContext(requestCtx)
+		ctx.Next()
+	}
+}
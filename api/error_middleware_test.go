@@ -0,0 +1,218 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/S-Devoe/golang-simple-bank/apperr"
+	mockdb "github.com/S-Devoe/golang-simple-bank/db/mock"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func requireProblem(t *testing.T, recorder *httptest.ResponseRecorder, status int, code apperr.Code) apperr.Problem {
+	require.Equal(t, status, recorder.Code)
+	require.True(t, strings.HasPrefix(recorder.Header().Get("Content-Type"), apperr.ProblemContentType))
+
+	var problem apperr.Problem
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+	require.Equal(t, code, problem.Code)
+	return problem
+}
+
+func TestErrorMiddleware(t *testing.T) {
+	testCases := []struct {
+		name   string
+		err    error
+		status int
+		code   apperr.Code
+	}{
+		{
+			name:   "AppError",
+			err:    fmt.Errorf("cannot transfer: %w", apperr.New(apperr.InsufficientFunds, "insufficient balance")),
+			status: http.StatusBadRequest,
+			code:   apperr.InsufficientFunds,
+		},
+		{
+			name:   "NotFound",
+			err:    orNotFound(db.ErrRecordNotFound, apperr.PayeeNotFound, "payee not found"),
+			status: http.StatusNotFound,
+			code:   apperr.PayeeNotFound,
+		},
+		{
+			name:   "Timeout",
+			err:    fmt.Errorf("cannot fetch account: %w", context.DeadlineExceeded),
+			status: http.StatusGatewayTimeout,
+			code:   apperr.Timeout,
+		},
+		{
+			name:   "Internal",
+			err:    orNotFound(errors.New("connection reset"), apperr.PayeeNotFound, "payee not found"),
+			status: http.StatusInternalServerError,
+			code:   apperr.Internal,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			captureLogs(t)
+			server := newTestServer(t, nil)
+			server.router.GET("/fail", handle(func(ctx *gin.Context) error {
+				return tc.err
+			}))
+
+			request, err := http.NewRequest(http.MethodGet, "/fail", nil)
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+
+			problem := requireProblem(t, recorder, tc.status, tc.code)
+			require.NotContains(t, problem.Detail, "connection reset")
+		})
+	}
+}
+
+func TestRecoveryMiddleware(t *testing.T) {
+	logs := captureLogs(t)
+	server := newTestServer(t, nil)
+	// a route registered without authMiddleware used to panic on the missing payload
+	server.router.GET("/panic", func(ctx *gin.Context) {
+		var payload map[string]string
+		payload["boom"] = "boom"
+	})
+
+	request, err := http.NewRequest(http.MethodGet, "/panic", nil)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+
+	problem := requireProblem(t, recorder, http.StatusInternalServerError, apperr.Internal)
+	require.Equal(t, "internal server error", problem.Detail)
+	require.NotEmpty(t, problem.RequestID)
+	require.Equal(t, recorder.Header().Get(logging.RequestIDHeader), problem.RequestID)
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	require.Len(t, lines, 2)
+	var panicLine, requestLine map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &panicLine))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &requestLine))
+	require.Equal(t, "panic recovered", panicLine["msg"])
+	require.Contains(t, panicLine["stack"], "TestRecoveryMiddleware")
+	require.Equal(t, problem.RequestID, panicLine["request_id"])
+	require.Equal(t, "request failed", requestLine["msg"])
+	require.Contains(t, requestLine["error"], "assignment to entry in nil map")
+}
+
+func TestMissingAuthorizationPayload(t *testing.T) {
+	captureLogs(t)
+	server := newTestServer(t, nil)
+	server.router.GET("/accounts", handle(server.listAccounts))
+
+	request, err := http.NewRequest(http.MethodGet, "/accounts", nil)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+
+	requireProblem(t, recorder, http.StatusInternalServerError, apperr.Internal)
+}
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	config := testConfig()
+	config.MaxRequestBodyBytes = 64
+	body := `{"username": "alice", "password": "` + strings.Repeat("p", 100) + `"}`
+
+	testCases := []struct {
+		name          string
+		contentLength int64
+	}{
+		{name: "DeclaredLength", contentLength: int64(len(body))},
+		// without a declared length the limit is only hit while binding reads the body
+		{name: "Chunked", contentLength: -1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			captureLogs(t)
+			server := newTestServerWithConfig(t, nil, config)
+
+			request, err := http.NewRequest(http.MethodPost, "/api/v1/login", bytes.NewReader([]byte(body)))
+			require.NoError(t, err)
+			request.ContentLength = tc.contentLength
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+
+			problem := requireProblem(t, recorder, http.StatusRequestEntityTooLarge, apperr.RequestTooLarge)
+			require.Equal(t, "request body must not exceed 64 bytes", problem.Detail)
+		})
+	}
+}
+
+func TestTimeoutMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	config := testConfig()
+	config.RequestTimeout = 20 * time.Millisecond
+	config.ReportRequestTimeout = time.Minute
+
+	user := randomUser()
+	account := randomAccount(user.Username)
+	store := mockdb.NewMockStore(ctrl)
+	// the store call blocks until the request deadline cancels it, as a stuck query would
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).
+		DoAndReturn(func(ctx context.Context, id int64) (db.Account, error) {
+			<-ctx.Done()
+			return db.Account{}, fmt.Errorf("cannot fetch account: %w", ctx.Err())
+		})
+
+	captureLogs(t)
+	server := newTestServerWithConfig(t, store, config)
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/api/v1/accounts/%d", account.ID), nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Email, time.Minute)
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+
+	requireProblem(t, recorder, http.StatusGatewayTimeout, apperr.Timeout)
+}
+
+func TestTimeoutMiddlewareRouteOverride(t *testing.T) {
+	server := newTestServer(t, nil)
+	var deadlines []time.Duration
+	server.router.GET("/slow/:id",
+		timeoutMiddleware(time.Second, map[string]time.Duration{"/slow/:id": time.Hour}),
+		func(ctx *gin.Context) {
+			deadline, ok := ctx.Request.Context().Deadline()
+			require.True(t, ok)
+			deadlines = append(deadlines, time.Until(deadline))
+		})
+	server.router.GET("/fast",
+		timeoutMiddleware(time.Second, map[string]time.Duration{"/slow/:id": time.Hour}),
+		func(ctx *gin.Context) {
+			deadline, ok := ctx.Request.Context().Deadline()
+			require.True(t, ok)
+			deadlines = append(deadlines, time.Until(deadline))
+		})
+
+	for _, path := range []string{"/slow/1", "/fast"} {
+		request, err := http.NewRequest(http.MethodGet, path, nil)
+		require.NoError(t, err)
+		server.router.ServeHTTP(httptest.NewRecorder(), request)
+	}
+
+	require.Len(t, deadlines, 2)
+	require.Greater(t, deadlines[0], time.Minute)
+	require.LessOrEqual(t, deadlines[1], time.Second)
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/gin-gonic/gin"
+)
+
+// liveness reports that the process is up and serving requests, whatever the state of its dependencies
+func (server *Server) liveness(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, util.CreateResponse(http.StatusOK, gin.H{"status": "ok"}, nil))
+}
+
+// readiness reports whether the server should receive traffic, with the outcome of each dependency check
+func (server *Server) readiness(ctx *gin.Context) {
+	report := server.health.Report()
+	if !report.Ready {
+		ctx.JSON(http.StatusServiceUnavailable, util.CreateResponse(http.StatusServiceUnavailable, report, "server is not ready"))
+		return
+	}
+	ctx.JSON(http.StatusOK, util.CreateResponse(http.StatusOK, report, nil))
+}
@@ -0,0 +1,9 @@
+package api
+
+import "github.com/gin-gonic/gin"
+
+// health probes sit outside /api/v1 so load balancers and orchestrators can reach them without a version
+func (server *Server) setUpHealthRoutes(router *gin.RouterGroup) {
+	router.GET("/healthz", server.liveness)
+	router.GET("/readyz", server.readiness)
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mockdb "github.com/S-Devoe/golang-simple-bank/db/mock"
+	"github.com/S-Devoe/golang-simple-bank/health"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestHealthAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var dbFailing error
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+	server.health.AddCheck("database", func(ctx context.Context) error { return dbFailing })
+
+	probe := func(path string) (int, health.Report) {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, path, nil)
+		require.NoError(t, err)
+		server.router.ServeHTTP(recorder, request)
+
+		var response struct {
+			Data health.Report `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		return recorder.Code, response.Data
+	}
+
+	// liveness never depends on the checks
+	code, _ := probe("/healthz")
+	require.Equal(t, http.StatusOK, code)
+
+	code, report := probe("/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.False(t, report.Ready)
+
+	server.health.CheckNow(context.Background())
+	code, report = probe("/readyz")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, map[string]string{"database": "ok"}, report.Checks)
+
+	dbFailing = errors.New("dial tcp 10.0.3.12:5432: connection refused")
+	server.health.CheckNow(context.Background())
+	code, report = probe("/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	// the cause is logged, not handed to whoever probes
+	require.Equal(t, health.StatusFailing, report.Checks["database"])
+
+	dbFailing = nil
+	server.health.CheckNow(context.Background())
+	server.health.Drain()
+	code, report = probe("/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.True(t, report.Draining)
+
+	code, _ = probe("/healthz")
+	require.Equal(t, http.StatusOK, code)
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/S-Devoe/golang-simple-bank/logging"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// loggingMiddleware tags every request with an X-Request-ID, keeping the client's when it sent a usable one,
+// carries a logger holding that id through the request context and logs the outcome once the handlers are done.
+// Only the method, route and client address are logged, never bodies, query strings or headers.
+func loggingMiddleware(logger *slog.Logger) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		requestID := logging.RequestID(ctx.GetHeader(logging.RequestIDHeader))
+		ctx.Header(logging.RequestIDHeader, requestID)
+
+		// the route pattern rather than the raw path, so ids in the url don't split one route into many
+		path := ctx.FullPath()
+		if path == "" {
+			path = ctx.Request.URL.Path
+		}
+		requestLogger := logger.With(
+			"request_id", requestID,
+			"method", ctx.Request.Method,
+			"path", path,
+		)
+		if span := trace.SpanContextFromContext(ctx.Request.Context()); span.HasTraceID() {
+			requestLogger = requestLogger.With("trace_id", span.TraceID().String())
+		}
+		requestCtx := logging.NewContext(ctx.Request.Context(), requestLogger)
+		ctx.Request = ctx.Request.WithContext(requestCtx)
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		attrs := []any{
+			"status", status,
+			"duration", time.Since(start),
+			"client_ip", ctx.ClientIP(),
+		}
+		requestLogger = logging.FromContext(requestCtx)
+		if status >= http.StatusInternalServerError {
+			if err := ctx.Errors.Last(); err != nil {
+				attrs = append(attrs, "error", err.Err)
+			}
+			requestLogger.Error("request failed", attrs...)
+			return
+		}
+		requestLogger.Info("request completed", attrs...)
+	}
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mockdb "github.com/S-Devoe/golang-simple-bank/db/mock"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+// captureLogs routes the default logger, which NewServer hands to loggingMiddleware, into a buffer for the test
+func captureLogs(t *testing.T) *bytes.Buffer {
+	var buf bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&buf, "json", slog.LevelInfo))
+	t.Cleanup(func() { slog.SetDefault(previous) })
+	return &buf
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logs := captureLogs(t)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq("alice")).Times(1).Return(db.User{}, errors.New("connection reset"))
+	server := newTestServer(t, store)
+
+	body, err := json.Marshal(gin.H{"username": "alice", "password": "hunter22"})
+	require.NoError(t, err)
+	request, err := http.NewRequest(http.MethodPost, "/api/v1/login", bytes.NewReader(body))
+	require.NoError(t, err)
+	request.Header.Set(logging.RequestIDHeader, "client-request-1")
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+	require.Equal(t, "client-request-1", recorder.Header().Get(logging.RequestIDHeader))
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &line))
+	require.Equal(t, "ERROR", line["level"])
+	require.Equal(t, "client-request-1", line["request_id"])
+	require.Equal(t, "POST", line["method"])
+	require.Equal(t, "/api/v1/login", line["path"])
+	require.Equal(t, "alice", line["username"])
+	require.Equal(t, "connection reset", line["error"])
+	require.EqualValues(t, http.StatusInternalServerError, line["status"])
+	require.NotContains(t, logs.String(), "hunter22")
+}
+
+func TestLoggingMiddlewareRequestID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logs := captureLogs(t)
+	server := newTestServer(t, mockdb.NewMockStore(ctrl))
+
+	// a request id that could forge log lines is replaced by a generated one
+	request, err := http.NewRequest(http.MethodGet, "/healthz", nil)
+	require.NoError(t, err)
+	request.Header.Set(logging.RequestIDHeader, "forged\nid")
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	requestID := recorder.Header().Get(logging.RequestIDHeader)
+	require.Len(t, requestID, 26)
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(logs.Bytes(), &line))
+	require.Equal(t, "INFO", line["level"])
+	require.Equal(t, requestID, line["request_id"])
+	require.NotContains(t, line, "error")
+}
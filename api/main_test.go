@@ -1,29 +1,76 @@
 package api
 
 import (
+	"context"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/S-Devoe/golang-simple-bank/config"
+	mockdb "github.com/S-Devoe/golang-simple-bank/db/mock"
 	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/health"
 	"github.com/S-Devoe/golang-simple-bank/util"
 	"github.com/gin-gonic/gin"
 	_ "github.com/jackc/pgx/v5"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
 )
 
 func newTestServer(t *testing.T, store db.Store) *Server {
-	config := config.Config{
+	return newTestServerWithConfig(t, store, testConfig())
+}
+
+func testConfig() config.Config {
+	return config.Config{
 		TokenSymmetricKey:   util.GenerateRandomString(32),
 		AccessTokenDuration: 1 * time.Hour,
 	}
-	server, err := NewServer(config, store)
+}
+
+func newTestServerWithConfig(t *testing.T, store db.Store, config config.Config) *Server {
+	server, err := NewServer(config, store, health.NewChecker(time.Second))
 	require.NoError(t, err)
+	if store, ok := store.(*mockdb.MockStore); ok {
+		expectLiveSessions(store)
+	}
+
+	// seed the registry cache so handlers don't reach the mock store for currency checks
+	server.currencies.ttl = time.Hour
+	server.currencies.set(testCurrencies)
 
 	return server
 }
 
+// testSessionID names the session the access tokens of addAuthorization are issued for
+func testSessionID(username string) string {
+	return "session-of-" + username
+}
+
+// expectLiveSessions answers the session lookups authMiddleware makes for the tokens of addAuthorization,
+// leaving the lookups of other session ids to the stubs of each test
+func expectLiveSessions(store *mockdb.MockStore) {
+	testSession := gomock.Cond(func(id string) bool {
+		return strings.HasPrefix(id, "session-of-")
+	})
+	store.EXPECT().GetAuthSession(gomock.Any(), testSession).AnyTimes().
+		DoAndReturn(func(_ context.Context, id string) (db.GetAuthSessionRow, error) {
+			return db.GetAuthSessionRow{Session: db.Session{
+				ID:        id,
+				Username:  strings.TrimPrefix(id, "session-of-"),
+				ExpiresAt: time.Now().Add(time.Hour),
+			}}, nil
+		})
+}
+
+var testCurrencies = []db.Currency{
+	{Code: util.USD, Exponent: 2, Symbol: "$", Enabled: true, MinTransferAmount: 1, MaxTransferAmount: 1000000, PayeeCooldownLimit: 1000},
+	{Code: util.NGN, Exponent: 2, Symbol: "₦", Enabled: true, MinTransferAmount: 1, MaxTransferAmount: 1000000, PayeeCooldownLimit: 1000},
+	{Code: util.EUR, Exponent: 2, Symbol: "€", Enabled: true, MinTransferAmount: 1, MaxTransferAmount: 1000000, PayeeCooldownLimit: 1000},
+	{Code: util.CAD, Exponent: 2, Symbol: "CA$", Enabled: true, MinTransferAmount: 1, MaxTransferAmount: 1000000, PayeeCooldownLimit: 1000},
+}
+
 func TestMain(m *testing.M) {
 	gin.SetMode(gin.TestMode)
 
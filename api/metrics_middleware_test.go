@@ -0,0 +1,63 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mockdb "github.com/S-Devoe/golang-simple-bank/db/mock"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/metrics"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq("nobody")).Times(1).Return(db.User{}, db.ErrRecordNotFound)
+	server := newTestServer(t, store)
+
+	serve := func(method, path string, body gin.H) *httptest.ResponseRecorder {
+		data, err := json.Marshal(body)
+		require.NoError(t, err)
+		request, err := http.NewRequest(method, path, bytes.NewReader(data))
+		require.NoError(t, err)
+		recorder := httptest.NewRecorder()
+		server.router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	healthz := metrics.HTTPRequests.WithLabelValues(http.MethodGet, "/healthz", "200")
+	unmatched := metrics.HTTPRequests.WithLabelValues(http.MethodGet, unmatchedRoute, "404")
+	loginFailures := metrics.Logins.WithLabelValues(metrics.TransportHTTP, metrics.LoginFailed)
+	before := []float64{testutil.ToFloat64(healthz), testutil.ToFloat64(unmatched), testutil.ToFloat64(loginFailures)}
+
+	serve(http.MethodGet, "/healthz", nil)
+	// random paths share one label
+	serve(http.MethodGet, "/wp-admin/one", nil)
+	serve(http.MethodGet, "/wp-admin/two", nil)
+	recorder := serve(http.MethodPost, "/api/v1/login", gin.H{"username": "nobody", "password": "secret123"})
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	require.Equal(t, before[0]+1, testutil.ToFloat64(healthz))
+	require.Equal(t, before[1]+2, testutil.ToFloat64(unmatched))
+	require.Equal(t, before[2]+1, testutil.ToFloat64(loginFailures))
+
+	// metrics are served on the admin listener, never next to the api
+	recorder = serve(http.MethodGet, "/metrics", nil)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Body.String(), `simplebank_http_requests_total{method="GET",route="/healthz",status="200"}`)
+	require.Contains(t, recorder.Body.String(), "simplebank_http_request_duration_seconds_bucket")
+	require.NotContains(t, recorder.Body.String(), "nobody")
+}
@@ -0,0 +1,186 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/S-Devoe/golang-simple-bank/apperr"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/gin-gonic/gin"
+)
+
+// create payee
+type createPayeeRequest struct {
+	AccountID int64  `json:"account_id" binding:"required,min=1"`
+	Nickname  string `json:"nickname" binding:"required,nickname"`
+}
+
+func (server *Server) createPayee(ctx *gin.Context) error {
+	var req createPayeeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return bindingError(err)
+	}
+
+	account, err := server.store.GetAccount(ctx, req.AccountID)
+	if err != nil {
+		return orNotFound(err, apperr.AccountNotFound, "account not found")
+	}
+
+	authPayload, err := authorizationPayload(ctx)
+	if err != nil {
+		return err
+	}
+	if account.Owner == authPayload.Username {
+		return apperr.New(apperr.SameAccount, "you cannot add your own account as a payee")
+	}
+
+	// the display name comes from the account owner, not from the request, so the payer knows who they are paying
+	counterparty, err := server.store.GetUser(ctx, account.Owner)
+	if err != nil {
+		return err
+	}
+
+	arg := db.CreatePayeeParams{
+		Owner:       authPayload.Username,
+		Nickname:    strings.TrimSpace(req.Nickname),
+		AccountID:   account.ID,
+		DisplayName: counterparty.FullName,
+	}
+	payee, err := server.store.CreatePayee(ctx, arg)
+	if err != nil {
+		if db.ErrorCode(err) == db.UniqueViolation {
+			return apperr.New(apperr.PayeeAlreadyExists, "payee with this account or nickname already exists")
+		}
+		return err
+	}
+	ctx.JSON(http.StatusCreated, util.CreateResponse(http.StatusCreated, payee, nil))
+	return nil
+}
+
+// get payee by id
+type getPayeeRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) getPayee(ctx *gin.Context) error {
+	var req getPayeeRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		return bindingError(err)
+	}
+
+	payee, err := server.ownedPayee(ctx, req.ID)
+	if err != nil {
+		return err
+	}
+	ctx.JSON(http.StatusOK, util.CreateResponse(http.StatusOK, payee, nil))
+	return nil
+}
+
+// get payees
+func (server *Server) listPayees(ctx *gin.Context) error {
+	pagination, err := util.ParsePaginationQuery(ctx)
+	if err != nil {
+		return invalidArgument(err.Error())
+	}
+
+	authPayload, err := authorizationPayload(ctx)
+	if err != nil {
+		return err
+	}
+	arg := db.ListPayeesParams{
+		Owner:  authPayload.Username,
+		Limit:  pagination.Limit,
+		Offset: pagination.Offset,
+	}
+	payees, err := server.store.ListPayees(ctx, arg)
+	if err != nil {
+		return err
+	}
+
+	totalItems, err := server.store.CountPayees(ctx, authPayload.Username)
+	if err != nil {
+		return err
+	}
+	ctx.JSON(http.StatusOK, util.CreatePaginatedResponse(http.StatusOK, payees, pagination.Page, pagination.Limit, totalItems, nil))
+	return nil
+}
+
+// update payee nickname
+type updatePayeeRequest struct {
+	Nickname string `json:"nickname" binding:"required,nickname"`
+}
+
+func (server *Server) updatePayee(ctx *gin.Context) error {
+	var uri getPayeeRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		return bindingError(err)
+	}
+	var req updatePayeeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return bindingError(err)
+	}
+
+	if _, err := server.ownedPayee(ctx, uri.ID); err != nil {
+		return err
+	}
+
+	payee, err := server.store.UpdatePayee(ctx, db.UpdatePayeeParams{
+		ID:       uri.ID,
+		Nickname: strings.TrimSpace(req.Nickname),
+	})
+	if err != nil {
+		if db.ErrorCode(err) == db.UniqueViolation {
+			return apperr.New(apperr.PayeeAlreadyExists, "payee with this nickname already exists")
+		}
+		return err
+	}
+	ctx.JSON(http.StatusOK, util.CreateResponse(http.StatusOK, payee, nil))
+	return nil
+}
+
+func (server *Server) deletePayee(ctx *gin.Context) error {
+	var req getPayeeRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		return bindingError(err)
+	}
+
+	if _, err := server.ownedPayee(ctx, req.ID); err != nil {
+		return err
+	}
+
+	if err := server.store.DeletePayee(ctx, req.ID); err != nil {
+		return err
+	}
+
+	resp := messageResponse{
+		Message: "Payee deleted",
+	}
+	ctx.JSON(http.StatusOK, util.CreateResponse(http.StatusOK, resp, nil))
+	return nil
+}
+
+// ownedPayee fetches a payee and makes sure it belongs to the authenticated user
+func (server *Server) ownedPayee(ctx *gin.Context, payeeID int64) (db.Payee, error) {
+	payee, err := server.store.GetPayee(ctx, payeeID)
+	if err != nil {
+		return payee, orNotFound(err, apperr.PayeeNotFound, "payee not found")
+	}
+
+	authPayload, err := authorizationPayload(ctx)
+	if err != nil {
+		return payee, err
+	}
+	if payee.Owner != authPayload.Username {
+		return payee, apperr.New(apperr.PayeeNotOwned, "payee does not belong to the authenticated user")
+	}
+	return payee, nil
+}
+
+// payeeCooldownError explains the cap TransferTx applied to a recipient that isn't an established payee
+func payeeCooldownError(currency db.Currency, cooldown time.Duration) error {
+	msg := fmt.Sprintf("transfers above %.2f %s are only allowed to accounts saved as a payee at least %s ago", currency.PayeeCooldownLimit, currency.Code, cooldown)
+	return apperr.New(apperr.PayeeCooldown, msg)
+}
@@ -0,0 +1,15 @@
+package api
+
+import "github.com/gin-gonic/gin"
+
+func (server *Server) setUpPayeeRoutes(router *gin.RouterGroup) {
+	payeesGroup := router.Group("/payees").Use(authMiddleware(server.tokenMaker, server.sessions))
+	{
+		// payees endpoints
+		payeesGroup.POST("", handle(server.createPayee))
+		payeesGroup.GET("/:id", handle(server.getPayee))
+		payeesGroup.GET("", handle(server.listPayees))
+		payeesGroup.PATCH("/:id", handle(server.updatePayee))
+		payeesGroup.DELETE("/:id", handle(server.deletePayee))
+	}
+}
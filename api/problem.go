@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/S-Devoe/golang-simple-bank/apperr"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+// respondError answers with the problem err describes. Errors that don't come from apperr are
+// internal: the client gets a bare 500 and err only reaches the logs through loggingMiddleware.
+func respondError(ctx *gin.Context, err error) {
+	var appErr *apperr.Error
+	switch {
+	case errors.As(err, &appErr):
+		respondProblem(ctx, appErr)
+	case errors.Is(err, context.DeadlineExceeded):
+		respondProblem(ctx, apperr.New(apperr.Timeout, "request timed out"))
+	default:
+		respondProblem(ctx, apperr.New(apperr.Internal, "internal server error"))
+	}
+}
+
+// bindingError describes a request that could not be bound, naming the offending fields when it can
+func bindingError(err error) error {
+	var validationErrors validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &validationErrors):
+		fields := make([]apperr.FieldViolation, 0, len(validationErrors))
+		for _, fieldErr := range validationErrors {
+			fields = append(fields, apperr.Field(fieldErr.Field(), describeFieldError(fieldErr)))
+		}
+		return apperr.Invalid(fields...)
+	case errors.As(err, &typeErr):
+		return apperr.Invalid(apperr.Field(typeErr.Field, "must be a "+typeErr.Type.String()))
+	case errors.As(err, &tooLarge):
+		return apperr.New(apperr.RequestTooLarge, fmt.Sprintf("request body must not exceed %d bytes", tooLarge.Limit))
+	default:
+		// syntax and conversion errors echo the input back, so they are not passed on
+		return apperr.New(apperr.InvalidArgument, "malformed request")
+	}
+}
+
+// invalidArgument is a 400 with a detail that is safe to show, such as the pagination and alias parse errors
+func invalidArgument(detail string, fields ...apperr.FieldViolation) error {
+	return &apperr.Error{Code: apperr.InvalidArgument, Message: detail, Fields: fields}
+}
+
+// orNotFound turns db.ErrRecordNotFound into the code the handler gives it, and passes any other error on
+func orNotFound(err error, code apperr.Code, message string) error {
+	if errors.Is(err, db.ErrRecordNotFound) {
+		return apperr.New(code, message)
+	}
+	return err
+}
+
+// respondProblem writes err as an application/problem+json body and stops the handler chain
+func respondProblem(ctx *gin.Context, err *apperr.Error) {
+	problem := apperr.NewProblem(err, ctx.Request.URL.Path)
+	problem.RequestID = ctx.Writer.Header().Get(logging.RequestIDHeader)
+	// gin keeps a content type that is already set
+	ctx.Header("Content-Type", apperr.ProblemContentType)
+	ctx.AbortWithStatusJSON(problem.Status, problem)
+}
+
+// requestFieldName names fields after the json, uri or form key clients send them as
+func requestFieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "uri", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// describeFieldError turns a failed binding rule into a description clients can show
+func describeFieldError(fieldErr validator.FieldError) string {
+	unit := ""
+	if fieldErr.Kind() == reflect.String {
+		unit = " characters"
+	}
+	switch fieldErr.Tag() {
+	case "required", "required_without_all":
+		return "is required"
+	case "excluded_with":
+		fields := strings.Fields(fieldErr.Param())
+		for i, field := range fields {
+			fields[i] = snakeCase(field)
+		}
+		return "must not be combined with " + strings.Join(fields, " or ")
+	case "min":
+		return fmt.Sprintf("must be at least %s%s", fieldErr.Param(), unit)
+	case "max":
+		return fmt.Sprintf("must be at most %s%s", fieldErr.Param(), unit)
+	case "len":
+		return fmt.Sprintf("must be exactly %s%s", fieldErr.Param(), unit)
+	case "gt":
+		return "must be greater than " + fieldErr.Param()
+	case "gte":
+		return "must be at least " + fieldErr.Param()
+	case "gtefield":
+		return "must not be below " + snakeCase(fieldErr.Param())
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(fieldErr.Param(), " ", ", ")
+	case "uppercase":
+		return "must be upper case"
+	case "currency":
+		return "unsupported currency"
+	}
+	if rule, ok := stringRules[fieldErr.Tag()]; ok {
+		if value, ok := fieldErr.Value().(string); ok {
+			if err := rule(value); err != nil {
+				return err.Error()
+			}
+		}
+	}
+	return "is invalid"
+}
+
+// snakeCase turns the struct field names cross-field rules refer to into the json keys clients know
+func snakeCase(name string) string {
+	var b strings.Builder
+	previousLower := false
+	for _, r := range name {
+		if unicode.IsUpper(r) && previousLower {
+			b.WriteByte('_')
+		}
+		previousLower = unicode.IsLower(r)
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/S-Devoe/golang-simple-bank/apperr"
+	mockdb "github.com/S-Devoe/golang-simple-bank/db/mock"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/logging"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestProblemResponses(t *testing.T) {
+	testCases := []struct {
+		name          string
+		path          string
+		body          string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder, problem apperr.Problem)
+	}{
+		{
+			name: "FieldViolations",
+			path: "/api/v1/users",
+			body: `{"username": "ab", "password": "secret1", "full_name": "John Smith", "email": "nope"}`,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, problem apperr.Problem) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, apperr.InvalidArgument, problem.Code)
+				require.ElementsMatch(t, []apperr.FieldViolation{
+					{Field: "username", Description: "must contain from 3-100 characters"},
+					{Field: "email", Description: "is not a valid email address"},
+				}, problem.Errors)
+			},
+		},
+		{
+			name: "RequiredField",
+			path: "/api/v1/login",
+			body: `{"username": "alice"}`,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, problem apperr.Problem) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, []apperr.FieldViolation{{Field: "password", Description: "is required"}}, problem.Errors)
+			},
+		},
+		{
+			name: "WrongType",
+			path: "/api/v1/login",
+			body: `{"username": 5, "password": "secret1"}`,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, problem apperr.Problem) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, []apperr.FieldViolation{{Field: "username", Description: "must be a string"}}, problem.Errors)
+			},
+		},
+		{
+			name: "MalformedBody",
+			path: "/api/v1/login",
+			body: `{"username": "<script>`,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, problem apperr.Problem) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Equal(t, apperr.InvalidArgument, problem.Code)
+				require.Equal(t, "malformed request", problem.Detail)
+				require.NotContains(t, recorder.Body.String(), "script")
+			},
+		},
+		{
+			name: "Coded",
+			path: "/api/v1/login",
+			body: `{"username": "alice", "password": "secret1"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Eq("alice")).Times(1).Return(db.User{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, problem apperr.Problem) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				require.Equal(t, apperr.Problem{
+					Type:      "urn:simplebank:error:USER_NOT_FOUND",
+					Title:     "User not found",
+					Status:    http.StatusNotFound,
+					Detail:    "user not found",
+					Instance:  "/api/v1/login",
+					Code:      apperr.UserNotFound,
+					RequestID: recorder.Header().Get(logging.RequestIDHeader),
+				}, problem)
+			},
+		},
+		{
+			name: "InternalErrorScrubbed",
+			path: "/api/v1/login",
+			body: `{"username": "alice", "password": "secret1"}`,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetUser(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.User{}, errors.New(`relation "users" does not exist`))
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder, problem apperr.Problem) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				require.Equal(t, apperr.Internal, problem.Code)
+				require.Equal(t, "internal server error", problem.Detail)
+				require.NotContains(t, recorder.Body.String(), "relation")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			captureLogs(t)
+			store := mockdb.NewMockStore(ctrl)
+			if tc.buildStubs != nil {
+				tc.buildStubs(store)
+			}
+			server := newTestServer(t, store)
+
+			request, err := http.NewRequest(http.MethodPost, tc.path, bytes.NewReader([]byte(tc.body)))
+			require.NoError(t, err)
+			recorder := httptest.NewRecorder()
+			server.router.ServeHTTP(recorder, request)
+
+			require.True(t, strings.HasPrefix(recorder.Header().Get("Content-Type"), apperr.ProblemContentType))
+			var problem apperr.Problem
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &problem))
+			require.Equal(t, recorder.Code, problem.Status)
+			tc.checkResponse(t, recorder, problem)
+		})
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	require.Equal(t, "min_transfer_amount", snakeCase("MinTransferAmount"))
+	require.Equal(t, "payee_id", snakeCase("PayeeID"))
+	require.Equal(t, "to_alias", snakeCase("ToAlias"))
+}
@@ -2,11 +2,19 @@ package api
 
 import (
 	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
 
 	"github.com/S-Devoe/golang-simple-bank/config"
 	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
 	_ "github.com/S-Devoe/golang-simple-bank/docs"
+	"github.com/S-Devoe/golang-simple-bank/health"
+	"github.com/S-Devoe/golang-simple-bank/mail"
+	"github.com/S-Devoe/golang-simple-bank/metrics"
+	"github.com/S-Devoe/golang-simple-bank/session"
 	"github.com/S-Devoe/golang-simple-bank/token"
+	"github.com/S-Devoe/golang-simple-bank/verification"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
@@ -20,36 +28,77 @@ type Server struct {
 	router     *gin.Engine
 	tokenMaker token.Maker
 	config     config.Config
+	currencies *currencyCache
+	sessions   *session.Service
+	emails     *verification.Service
+	health     *health.Checker
 }
 
 // Newserver creates a new http server and setup routing
-func NewServer(config config.Config, store db.Store) (*Server, error) {
+func NewServer(config config.Config, store db.Store, checker *health.Checker) (*Server, error) {
 	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
+	tokenMaker = metrics.CountTokens(tokenMaker, "paseto")
 	server := &Server{
 		store:      store,
 		tokenMaker: tokenMaker,
 		config:     config,
+		currencies: newCurrencyCache(store, config.CurrencyCacheDuration),
+		sessions:   session.NewService(store, tokenMaker, config.AccessTokenDuration),
+		emails:     newVerificationService(config, store),
+		health:     checker,
 	}
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
-		v.RegisterValidation("currency", validCurrency)
+		v.RegisterTagNameFunc(requestFieldName)
+		v.RegisterValidation("currency", server.validCurrency())
+		for tag, rule := range stringRules {
+			v.RegisterValidation(tag, validString(rule))
+		}
 	}
 	server.setUpRouter()
 
+	// gin believes X-Forwarded-For from anyone unless told which proxies to trust
+	trustedProxies, err := config.TrustedProxyPrefixes()
+	if err != nil {
+		return nil, err
+	}
+	var proxies []string
+	for _, prefix := range trustedProxies {
+		proxies = append(proxies, prefix.String())
+	}
+	if err := server.router.SetTrustedProxies(proxies); err != nil {
+		return nil, fmt.Errorf("cannot set trusted proxies: %w", err)
+	}
+
 	return server, nil
 
 }
 
-// Start runs the http server on a specific address
-func (server *Server) Start(addr string) error {
-	return server.router.Run(addr)
+func newVerificationService(config config.Config, store db.Store) *verification.Service {
+	sender := mail.NewSender(config.SMTPAddress, config.SMTPUsername, config.SMTPPassword, config.MailFrom)
+	return verification.NewService(store, sender, config.EmailVerificationURL, config.EmailVerificationDuration)
+}
+
+// Handler returns the router so the caller can serve it from its own http.Server and shut it down gracefully
+func (server *Server) Handler() http.Handler {
+	return server.router
 }
 
 func (server *Server) setUpRouter() {
-	router := gin.Default()
+	router := gin.New()
+	// handlers pass the gin context on, this lets it reach the request logger in the request context
+	router.ContextWithFallback = true
+	router.Use(
+		tracingMiddleware(),
+		loggingMiddleware(slog.Default()),
+		metricsMiddleware(),
+		recoveryMiddleware(),
+		errorMiddleware(),
+		bodyLimitMiddleware(server.config.MaxRequestBodyBytes),
+	)
 	//add swagger
 	router.GET("/docs/*any", func(c *gin.Context) {
 		if c.Request.RequestURI == "/docs/" {
@@ -58,13 +107,23 @@ func (server *Server) setUpRouter() {
 		}
 	},
 		ginSwagger.WrapHandler(swaggerFiles.Handler))
+	server.setUpHealthRoutes(&router.RouterGroup)
 
 	api := router.Group("/api/v1")
+	// the reports scan a lot of rows, so they get longer than the other routes
+	api.Use(timeoutMiddleware(server.config.RequestTimeout, map[string]time.Duration{
+		"/api/v1/accounts/:id/analytics": server.config.ReportRequestTimeout,
+		"/api/v1/transfer/search":        server.config.ReportRequestTimeout,
+	}))
 	{
 		server.setUpUserRoutes(api)
 		server.setUpAccountRoutes(api)
 		server.setUpAuthRoutes(api)
 		server.setUpTransferRoutes(api)
+		server.setUpPayeeRoutes(api)
+		server.setUpSessionRoutes(api)
+		server.setUpCurrencyRoutes(api)
+		server.setUpAliasRoutes(api)
 
 	}
 
@@ -0,0 +1,94 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/session"
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/gin-gonic/gin"
+)
+
+// sessionResponse leaves out the refresh token stored with the session
+type sessionResponse struct {
+	ID        string    `json:"id"`
+	UserAgent string    `json:"user_agent"`
+	ClientIp  string    `json:"client_ip"`
+	IsBlocked bool      `json:"is_blocked"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newSessionResponse(session db.Session) sessionResponse {
+	return sessionResponse{
+		ID:        session.ID,
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+		IsBlocked: session.IsBlocked,
+		ExpiresAt: session.ExpiresAt,
+		CreatedAt: session.CreatedAt,
+	}
+}
+
+// list the user's sessions, newest first
+func (server *Server) listSessions(ctx *gin.Context) error {
+	pagination, err := util.ParseCursorQuery(ctx)
+	if err != nil {
+		return invalidArgument(err.Error())
+	}
+
+	authPayload, err := authorizationPayload(ctx)
+	if err != nil {
+		return err
+	}
+	sessions, err := server.sessions.List(ctx, session.ListParams{
+		Username: authPayload.Username,
+		Cursor:   pagination.StringKey(session.FirstCursor),
+		Limit:    pagination.Limit + 1,
+		Backward: pagination.Backward,
+	})
+	if err != nil {
+		return err
+	}
+
+	rows := make([]sessionResponse, 0, len(sessions))
+	for _, row := range sessions {
+		rows = append(rows, newSessionResponse(row))
+	}
+	data := util.NewCursorPage(rows, pagination, func(row sessionResponse) string {
+		return row.ID
+	})
+	if pagination.WithTotal {
+		totalItems, err := server.sessions.Count(ctx, authPayload.Username)
+		if err != nil {
+			return err
+		}
+		data.Total = &totalItems
+	}
+	ctx.JSON(http.StatusOK, util.CreateCursorPaginatedResponse(http.StatusOK, data, nil))
+	return nil
+}
+
+type revokeSessionRequest struct {
+	ID string `uri:"id" binding:"required"`
+}
+
+// block one of the user's sessions so its refresh token stops working
+func (server *Server) revokeSession(ctx *gin.Context) error {
+	var req revokeSessionRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		return bindingError(err)
+	}
+
+	authPayload, err := authorizationPayload(ctx)
+	if err != nil {
+		return err
+	}
+	revoked, err := server.sessions.Revoke(ctx, authPayload.Username, req.ID)
+	if err != nil {
+		return err
+	}
+	ctx.JSON(http.StatusOK, util.CreateResponse(http.StatusOK, newSessionResponse(revoked), nil))
+	return nil
+}
@@ -0,0 +1,12 @@
+package api
+
+import "github.com/gin-gonic/gin"
+
+func (server *Server) setUpSessionRoutes(router *gin.RouterGroup) {
+	sessionGroup := router.Group("/sessions").Use(authMiddleware(server.tokenMaker, server.sessions))
+	{
+		// sessions endpoints
+		sessionGroup.GET("", handle(server.listSessions))
+		sessionGroup.DELETE("/:id", handle(server.revokeSession))
+	}
+}
@@ -0,0 +1,158 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/S-Devoe/golang-simple-bank/db/mock"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/session"
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func TestListSessionsAPI(t *testing.T) {
+	user := randomUser()
+	userSession := db.Session{
+		ID:           "01HZX3Q5V8J6N2M4K7P9R0S1T2",
+		Username:     user.Username,
+		RefreshToken: util.GenerateRandomString(32),
+		UserAgent:    "curl/8.0",
+		ClientIp:     "127.0.0.1",
+		ExpiresAt:    time.Now().Add(time.Hour).UTC().Truncate(time.Second),
+		CreatedAt:    time.Now().UTC().Truncate(time.Second),
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	arg := db.ListSessionsParams{
+		Username: user.Username,
+		Cursor:   session.FirstCursor,
+		Limit:    util.DefaultLimit + 1,
+	}
+	store.EXPECT().ListSessions(gomock.Any(), gomock.Eq(arg)).Times(1).Return([]db.Session{userSession}, nil)
+
+	server := newTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api/v1/sessions", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Email, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	data, err := io.ReadAll(recorder.Body)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), userSession.RefreshToken)
+
+	var response struct {
+		Data util.PaginatedData[sessionResponse] `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(data, &response))
+	require.Equal(t, []sessionResponse{newSessionResponse(userSession)}, response.Data.Results)
+	require.Empty(t, response.Data.NextCursor)
+}
+
+func TestRevokeSessionAPI(t *testing.T) {
+	user := randomUser()
+	userSession := db.Session{
+		ID:        "01HZX3Q5V8J6N2M4K7P9R0S1T2",
+		Username:  user.Username,
+		ExpiresAt: time.Now().Add(time.Hour),
+	}
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				blocked := userSession
+				blocked.IsBlocked = true
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(userSession.ID)).Times(1).Return(userSession, nil)
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Eq(userSession.ID)).Times(1).Return(blocked, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:     "NotOwned",
+			username: "someone-else",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(userSession.ID)).Times(1).Return(userSession, nil)
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodDelete, "/api/v1/sessions/"+userSession.ID, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, tc.username, user.Email, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestLogoutAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	user := randomUser()
+
+	refreshToken, payload, err := server.tokenMaker.CreateToken(user.Username, user.Email, "", time.Hour)
+	require.NoError(t, err)
+	userSession := db.Session{
+		ID:           payload.ID.String(),
+		Username:     user.Username,
+		RefreshToken: refreshToken,
+		ExpiresAt:    payload.ExpiredAt,
+	}
+	blocked := userSession
+	blocked.IsBlocked = true
+	store.EXPECT().GetSession(gomock.Any(), gomock.Eq(userSession.ID)).Times(1).Return(userSession, nil)
+	store.EXPECT().BlockSession(gomock.Any(), gomock.Eq(userSession.ID)).Times(1).Return(blocked, nil)
+
+	body, err := json.Marshal(gin.H{"refresh_token": refreshToken})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/api/v1/logout", bytes.NewReader(body))
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
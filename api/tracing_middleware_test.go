@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mockdb "github.com/S-Devoe/golang-simple-bank/db/mock"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/tracing"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/mock/gomock"
+)
+
+// recordSpans installs a tracer provider keeping the spans of the test in memory
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	exporter := tracetest.NewInMemoryExporter()
+	tracing.Install(tracing.NewProvider(sdktrace.WithSyncer(exporter)))
+	return exporter
+}
+
+func TestTracingMiddleware(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	exporter := recordSpans(t)
+	store := mockdb.NewMockStore(ctrl)
+	user, _ := randomUserInfo(t)
+	// the handler's context carries the request span down to the store
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).
+		DoAndReturn(func(ctx context.Context, username string) (db.User, error) {
+			require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", trace.SpanContextFromContext(ctx).TraceID().String())
+			return db.User{}, errors.New("connection reset")
+		})
+	server := newTestServer(t, store)
+
+	request, err := http.NewRequest(http.MethodGet, "/api/v1/users/"+user.Username, nil)
+	require.NoError(t, err)
+	addAuthorization(t, request, server.tokenMaker, authorizationTypeBearer, user.Username, user.Email, time.Minute)
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	request.Header.Set("traceparent", traceparent)
+
+	recorder := httptest.NewRecorder()
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	require.Equal(t, "GET /api/v1/users/:username", span.Name)
+	// the caller's trace is continued rather than a new one started
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext.TraceID().String())
+	require.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+	require.Contains(t, span.Attributes, attribute.Int("http.response.status_code", http.StatusInternalServerError))
+	require.Equal(t, codes.Error, span.Status.Code)
+}
@@ -1,85 +1,231 @@
 package api
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 
+	"github.com/S-Devoe/golang-simple-bank/apperr"
 	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
-	"github.com/S-Devoe/golang-simple-bank/token"
+	"github.com/S-Devoe/golang-simple-bank/metrics"
 	"github.com/S-Devoe/golang-simple-bank/util"
 	"github.com/gin-gonic/gin"
 )
 
 type transferRequest struct {
 	FromAccountId int64   `json:"from_account_id" binding:"required,min=1"`
-	ToAccountId   int64   `json:"to_account_id" binding:"required,min=1"`
+	ToAccountId   int64   `json:"to_account_id" binding:"required_without_all=PayeeID ToAlias,excluded_with=PayeeID ToAlias,omitempty,min=1"`
+	PayeeID       int64   `json:"payee_id" binding:"excluded_with=ToAlias,omitempty,min=1"`
+	ToAlias       string  `json:"to_alias"`
 	Currency      string  `json:"currency" binding:"required,currency"`
-	Amount        float64 `json:"amount" binding:"required,min=1"`
+	Amount        float64 `json:"amount" binding:"required,gt=0"`
+	Memo          string  `json:"memo" binding:"memo"`
+	Reference     string  `json:"reference" binding:"reference"`
+	EndToEndID    string  `json:"end_to_end_id" binding:"endtoendid"`
 }
 
 type transferSuccessResponse struct {
 	Message string `json:"message"`
 }
 
-func (server *Server) createTransfer(ctx *gin.Context) {
+func (server *Server) createTransfer(ctx *gin.Context) error {
 	var req transferRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, util.CreateResponse(http.StatusBadRequest, nil, err))
-		return
+		return bindingError(err)
 	}
-	fromAccount, valid := server.validAccount(ctx, req.FromAccountId, req.Currency)
-	if !valid {
-		return
+	currency, err := server.checkTransferAmount(ctx, req.Currency, req.Amount)
+	if err != nil {
+		return err
+	}
+	fromAccount, err := server.validAccount(ctx, req.FromAccountId, req.Currency)
+	if err != nil {
+		return err
 	}
 
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
+	authPayload, err := authorizationPayload(ctx)
+	if err != nil {
+		return err
+	}
 	if fromAccount.Owner != authPayload.Username {
-		ctx.JSON(http.StatusUnauthorized, util.CreateResponse(http.StatusUnauthorized, nil, "Account doesn not belong to this authenticated user"))
-		return
+		return apperr.New(apperr.AccountNotOwned, "account does not belong to the authenticated user")
 	}
-	_, valid = server.validAccount(ctx, req.ToAccountId, req.Currency)
-	if !valid {
-		return
+
+	toAccountID := req.ToAccountId
+	if req.PayeeID != 0 {
+		payee, err := server.ownedPayee(ctx, req.PayeeID)
+		if err != nil {
+			return err
+		}
+		toAccountID = payee.AccountID
+	}
+
+	if req.ToAlias != "" {
+		// the alias is resolved to an account in the transfer currency by TransferTx itself
+		if _, _, err := util.ParseAlias(req.ToAlias); err != nil {
+			return apperr.Invalid(apperr.Field("to_alias", err.Error()))
+		}
+	} else {
+		if _, err := server.validAccount(ctx, toAccountID, req.Currency); err != nil {
+			return err
+		}
 	}
 
 	arg := db.TransferTxParams{
 		FromAccountID: req.FromAccountId,
-		ToAccountID:   req.ToAccountId,
+		ToAccountID:   toAccountID,
 		Amount:        req.Amount,
+		ToAlias:       req.ToAlias,
+		Currency:      req.Currency,
+		Memo:          req.Memo,
+		Reference:     req.Reference,
+		EndToEndID:    req.EndToEndID,
+		// however the recipient is named, large amounts need it to be an established payee
+		CooldownLimit:  currency.PayeeCooldownLimit,
+		CooldownPeriod: server.config.PayeeCooldownDuration,
 	}
 
 	result, err := server.store.TransferTx(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, util.CreateResponse(http.StatusInternalServerError, nil, err))
-		return
-	}
-
-	if result.FromAccount.Balance < req.Amount {
-		ctx.JSON(http.StatusBadRequest, util.CreateResponse(http.StatusBadRequest, nil, "Insufficient balance"))
-		return
+		if errors.Is(err, db.ErrRecordNotFound) {
+			countTransfer(req.Currency, metrics.TransferRejected)
+			if req.ToAlias != "" {
+				return apperr.New(apperr.AliasNotFound, "no account found for this alias and currency")
+			}
+			// the account was closed after it was checked above
+			return apperr.New(apperr.AccountNotFound, "account not found")
+		}
+		if db.ErrorCode(err) == db.UniqueViolation {
+			countTransfer(req.Currency, metrics.TransferRejected)
+			return apperr.New(apperr.IdempotencyKeyReused, "a transfer with this end_to_end_id was already sent from this account")
+		}
+		if errors.Is(err, db.ErrPayeeCooldown) {
+			countTransfer(req.Currency, metrics.TransferRejected)
+			return payeeCooldownError(currency, server.config.PayeeCooldownDuration)
+		}
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			countTransfer(req.Currency, metrics.TransferInsufficientFunds)
+			return apperr.New(apperr.InsufficientFunds, err.Error())
+		}
+		if errors.Is(err, db.ErrSameAccount) {
+			countTransfer(req.Currency, metrics.TransferRejected)
+			return apperr.New(apperr.SameAccount, err.Error())
+		}
+		countTransfer(req.Currency, metrics.TransferFailed)
+		return err
 	}
+	countTransfer(req.Currency, metrics.TransferCompleted)
 
 	res := &transferSuccessResponse{
 		Message: fmt.Sprintf("Transfer of %.2f %s successful.", result.Transfer.Amount, req.Currency),
 	}
 
 	ctx.JSON(http.StatusCreated, util.CreateResponse(http.StatusCreated, res, nil))
+	return nil
 }
 
-func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
-	account, err := server.store.GetAccount(ctx, accountID)
+// search the memo, reference and end-to-end id of the user's own transfers
+type searchTransfersRequest struct {
+	Query string `form:"q" binding:"required"`
+}
+
+func (server *Server) searchTransfers(ctx *gin.Context) error {
+	var req searchTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		return bindingError(err)
+	}
+
+	pagination, err := util.ParsePaginationQuery(ctx)
+	if err != nil {
+		return invalidArgument(err.Error())
+	}
+
+	authPayload, err := authorizationPayload(ctx)
 	if err != nil {
-		if err == db.ErrRecordNotFound {
-			ctx.JSON(http.StatusNotFound, util.CreateResponse(http.StatusNotFound, nil, "Account not found"))
-			return account, false
+		return err
+	}
+	transfers, err := server.store.SearchTransfers(ctx, db.SearchTransfersParams{
+		Query:  req.Query,
+		Owner:  authPayload.Username,
+		Limit:  pagination.Limit,
+		Offset: pagination.Offset,
+	})
+	if err != nil {
+		return err
+	}
+
+	totalItems, err := server.store.CountSearchTransfers(ctx, db.CountSearchTransfersParams{
+		Query: req.Query,
+		Owner: authPayload.Username,
+	})
+	if err != nil {
+		return err
+	}
+	ctx.JSON(http.StatusOK, util.CreatePaginatedResponse(http.StatusOK, transfers, pagination.Page, pagination.Limit, totalItems, nil))
+	return nil
+}
+
+// list the transfers sent or received by the user's accounts, newest first
+func (server *Server) listTransfers(ctx *gin.Context) error {
+	pagination, err := util.ParseCursorQuery(ctx)
+	if err != nil {
+		return invalidArgument(err.Error())
+	}
+	cursor, err := pagination.Int64Key(math.MaxInt64)
+	if err != nil {
+		return invalidArgument(err.Error())
+	}
+
+	authPayload, err := authorizationPayload(ctx)
+	if err != nil {
+		return err
+	}
+	var transfers []db.Transfer
+	if pagination.Backward {
+		transfers, err = server.store.ListOwnerTransfersReverse(ctx, db.ListOwnerTransfersReverseParams{
+			Owner:  authPayload.Username,
+			Cursor: cursor,
+			Limit:  pagination.Limit + 1,
+		})
+	} else {
+		transfers, err = server.store.ListOwnerTransfers(ctx, db.ListOwnerTransfersParams{
+			Owner:  authPayload.Username,
+			Cursor: cursor,
+			Limit:  pagination.Limit + 1,
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	data := util.NewCursorPage(transfers, pagination, func(transfer db.Transfer) string {
+		return strconv.FormatInt(transfer.ID, 10)
+	})
+	if pagination.WithTotal {
+		totalItems, err := server.store.CountOwnerTransfers(ctx, authPayload.Username)
+		if err != nil {
+			return err
 		}
-		ctx.JSON(http.StatusInternalServerError, util.CreateResponse(http.StatusInternalServerError, nil, err))
-		return account, false
+		data.Total = &totalItems
+	}
+	ctx.JSON(http.StatusOK, util.CreateCursorPaginatedResponse(http.StatusOK, data, nil))
+	return nil
+}
+
+func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, error) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		return account, orNotFound(err, apperr.AccountNotFound, "account not found")
 	}
 
 	if account.Currency != currency {
-		ctx.JSON(http.StatusBadRequest, util.CreateResponse(http.StatusBadRequest, nil, "Invalid currency for the account"))
-		return account, false
+		return account, apperr.New(apperr.CurrencyMismatch, fmt.Sprintf("account %d holds %s, not %s", account.ID, account.Currency, currency))
 	}
-	return account, true
+	return account, nil
+}
+
+// countTransfer records the outcome of a transfer that reached the store
+func countTransfer(currency, outcome string) {
+	metrics.Transfers.WithLabelValues(metrics.TransportHTTP, currency, outcome).Inc()
 }
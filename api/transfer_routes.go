@@ -3,9 +3,11 @@ package api
 import "github.com/gin-gonic/gin"
 
 func (server *Server) setUpTransferRoutes(router *gin.RouterGroup) {
-	transferGroup := router.Group("/transfer").Use(authMiddleware(server.tokenMaker))
+	transferGroup := router.Group("/transfer").Use(authMiddleware(server.tokenMaker, server.sessions))
 	{
 		// transfers endpoints
-		transferGroup.POST("/transfers", server.createTransfer)
+		transferGroup.POST("/transfers", handle(server.createTransfer))
+		transferGroup.GET("/transfers", handle(server.listTransfers))
+		transferGroup.GET("/search", handle(server.searchTransfers))
 	}
 }
@@ -4,17 +4,20 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/S-Devoe/golang-simple-bank/apperr"
 	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/logging"
 	"github.com/S-Devoe/golang-simple-bank/util"
 	"github.com/S-Devoe/golang-simple-bank/util/password"
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type createUserRequest struct {
-	Username string `json:"username" binding:"required,min=3"`
-	Password string `json:"password" binding:"required,min=6"`
-	FullName string `json:"full_name" binding:"required"`
-	Email    string `json:"email" binding:"required,email"`
+	Username string `json:"username" binding:"required,username"`
+	Password string `json:"password" binding:"required,password"`
+	FullName string `json:"full_name" binding:"required,fullname"`
+	Email    string `json:"email" binding:"required,emailaddress"`
 }
 
 type userResponse struct {
@@ -23,7 +26,7 @@ type userResponse struct {
 	Email             string    `json:"email"`
 	CreatedAt         time.Time `json:"created_at"`
 	PasswordChangedAt time.Time `json:"password_changed_at,omitempty"` // Omit if empty
-
+	IsEmailVerified   bool      `json:"is_email_verified"`
 }
 
 func newUserResponse(user db.User) userResponse {
@@ -33,106 +36,218 @@ func newUserResponse(user db.User) userResponse {
 		Email:             user.Email,
 		CreatedAt:         user.CreatedAt,
 		PasswordChangedAt: user.PasswordChangedAt,
+		IsEmailVerified:   user.IsEmailVerified,
 	}
 }
 
 // CreateUser godoc
 // @Summary Create User
-// @Description Create a new user
+// @Description Create a new user, and mail them the link verifying their email address
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param loginRequest body createUserRequest true "Create User Request"
 // @Success 200 {object} util.Response{data=userResponse} "Success"
-// @Failure 400 {object} util.Response "Bad Request"
-// @Failure 403 {object} util.Response "Forbidden"
-// @Failure 500 {object} util.Response "Internal Server Error"
+// @Failure 400 {object} apperr.Problem "Bad Request"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 500 {object} apperr.Problem "Internal Server Error"
 // @Router /signup [post]
-func (s *Server) createUser(ctx *gin.Context) {
+func (s *Server) createUser(ctx *gin.Context) error {
 	var req createUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, util.CreateResponse(http.StatusBadRequest, nil, err))
-		return
+		return bindingError(err)
 	}
+	logging.With(ctx.Request.Context(), "username", req.Username)
 
 	hashedPassword, err := password.GeneratePasswordHash(req.Password)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, util.CreateResponse(http.StatusInternalServerError, nil, err))
-		return
+		return err
+	}
+
+	verification, err := s.emails.New()
+	if err != nil {
+		return err
 	}
 
-	arg := db.CreateUserParams{
-		Username:       req.Username,
-		Email:          req.Email,
-		FullName:       req.FullName,
-		HashedPassword: hashedPassword,
+	arg := db.CreateUserTxParams{
+		CreateUserParams: db.CreateUserParams{
+			Username:       req.Username,
+			Email:          req.Email,
+			FullName:       req.FullName,
+			HashedPassword: hashedPassword,
+		},
+		Verification: verification,
 	}
 
-	user, err := s.store.CreateUser(ctx, arg)
+	result, err := s.store.CreateUserTx(ctx, arg)
 	if err != nil {
 		if db.ErrorCode(err) == db.UniqueViolation {
-			ctx.JSON(http.StatusForbidden, util.CreateResponse(http.StatusForbidden, nil, "Username or Email already exists"))
-			return
+			return apperr.New(apperr.UserAlreadyExists, "username or email already exists")
 		}
-		ctx.JSON(http.StatusInternalServerError, util.CreateResponse(http.StatusInternalServerError, nil, err))
-		return
+		return err
 
 	}
-	resp := newUserResponse(user)
+	s.emails.Send(ctx.Request.Context(), result.VerifyEmail)
+	resp := newUserResponse(result.User)
 	ctx.JSON(http.StatusCreated, util.CreateResponse(http.StatusCreated, resp, nil))
+	return nil
 }
 
 type getUserRequest struct {
 	Username string `uri:"username" binding:"required"`
 }
 
-func (s *Server) getUser(ctx *gin.Context) {
+func (s *Server) getUser(ctx *gin.Context) error {
 	var req getUserRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, util.CreateResponse(http.StatusBadRequest, nil, err))
-		return
+		return bindingError(err)
 	}
 
 	user, err := s.store.GetUser(ctx, req.Username)
+	if err != nil {
+		return orNotFound(err, apperr.UserNotFound, "user not found")
+	}
+
+	ctx.JSON(http.StatusOK, util.CreateResponse(http.StatusOK, newUserResponse(user), nil))
+	return nil
+}
+
+// updateUserRequest only changes the fields that are present in the body
+type updateUserRequest struct {
+	FullName *string `json:"full_name" binding:"omitempty,fullname"`
+	Email    *string `json:"email" binding:"omitempty,emailaddress"`
+	Password *string `json:"password" binding:"omitempty,password"`
+}
+
+// UpdateUser godoc
+// @Summary Update User
+// @Description Change the authenticated user's full name, email or password. A new password ends every existing session and a new email has to be verified again with the link mailed to it
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param username path string true "Username"
+// @Param updateRequest body updateUserRequest true "Fields to change"
+// @Success 200 {object} util.Response{data=userResponse} "Success"
+// @Failure 400 {object} apperr.Problem "Bad Request"
+// @Failure 401 {object} apperr.Problem "Unauthorized"
+// @Failure 403 {object} apperr.Problem "Forbidden"
+// @Failure 404 {object} apperr.Problem "Not Found"
+// @Failure 500 {object} apperr.Problem "Internal Server Error"
+// @Router /users/{username} [patch]
+func (s *Server) updateUser(ctx *gin.Context) error {
+	var uri getUserRequest
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		return bindingError(err)
+	}
+	var req updateUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		return bindingError(err)
+	}
+	if req.FullName == nil && req.Email == nil && req.Password == nil {
+		return invalidArgument("provide at least one of full_name, email or password")
+	}
+
+	authPayload, err := authorizationPayload(ctx)
+	if err != nil {
+		return err
+	}
+	if uri.Username != authPayload.Username {
+		return apperr.New(apperr.PermissionDenied, "cannot update another user")
+	}
+
+	arg := db.UpdateUserTxParams{Username: uri.Username}
+	if req.FullName != nil {
+		arg.FullName = pgtype.Text{String: *req.FullName, Valid: true}
+	}
+	if req.Email != nil {
+		arg.Email = pgtype.Text{String: *req.Email, Valid: true}
+		arg.Verification, err = s.emails.New()
+		if err != nil {
+			return err
+		}
+	}
+	if req.Password != nil {
+		hashedPassword, err := password.GeneratePasswordHash(*req.Password)
+		if err != nil {
+			return err
+		}
+		arg.HashedPassword = pgtype.Text{String: hashedPassword, Valid: true}
+	}
+
+	result, err := s.store.UpdateUserTx(ctx, arg)
 	if err != nil {
 		if err == db.ErrRecordNotFound {
-			ctx.JSON(http.StatusNotFound, util.CreateResponse(http.StatusNotFound, nil, "User not found"))
-			return
+			return apperr.New(apperr.UserNotFound, "user not found")
+		}
+		if db.ErrorCode(err) == db.UniqueViolation {
+			return apperr.New(apperr.EmailTaken, "email already exists")
 		}
-		ctx.JSON(http.StatusInternalServerError, util.CreateResponse(http.StatusInternalServerError, nil, err))
-		return
+		return err
+	}
+	if result.VerifyEmail.ID != 0 {
+		s.emails.Send(ctx.Request.Context(), result.VerifyEmail)
 	}
 
-	ctx.JSON(http.StatusOK, util.CreateResponse(http.StatusOK, newUserResponse(user), nil))
+	ctx.JSON(http.StatusOK, util.CreateResponse(http.StatusOK, newUserResponse(result.User), nil))
+	return nil
+}
+
+type verifyEmailRequest struct {
+	EmailID    int64  `form:"email_id" binding:"required,min=1"`
+	SecretCode string `form:"secret_code" binding:"required"`
+}
+
+type verifyEmailResponse struct {
+	IsVerified bool `json:"is_verified"`
+}
+
+// VerifyEmail godoc
+// @Summary Verify Email
+// @Description Confirm a user's email address with the code in the link mailed to it at signup or after changing it
+// @Tags auth
+// @Produce json
+// @Param email_id query int true "Email ID"
+// @Param secret_code query string true "Secret code"
+// @Success 200 {object} util.Response{data=verifyEmailResponse} "Success"
+// @Failure 400 {object} apperr.Problem "Bad Request"
+// @Failure 500 {object} apperr.Problem "Internal Server Error"
+// @Router /verify_email [get]
+func (s *Server) verifyEmail(ctx *gin.Context) error {
+	var req verifyEmailRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		return bindingError(err)
+	}
+
+	user, err := s.emails.Verify(ctx, req.EmailID, req.SecretCode)
+	if err != nil {
+		return err
+	}
+	logging.With(ctx.Request.Context(), "username", user.Username)
+
+	ctx.JSON(http.StatusOK, util.CreateResponse(http.StatusOK, verifyEmailResponse{IsVerified: user.IsEmailVerified}, nil))
+	return nil
 }
 
 type messageResponse struct {
 	Message string `json:"message"`
 }
 
-func (s *Server) deleteUser(ctx *gin.Context) {
+func (s *Server) deleteUser(ctx *gin.Context) error {
 	var req getUserRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, util.CreateResponse(http.StatusBadRequest, nil, err))
-		return
+		return bindingError(err)
 	}
 
 	// First, check if the user exists
 	_, err := s.store.GetUser(ctx, req.Username)
 	if err != nil {
-		if err == db.ErrRecordNotFound {
-			ctx.JSON(http.StatusNotFound, util.CreateResponse(http.StatusNotFound, nil, "User not found"))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, util.CreateResponse(http.StatusInternalServerError, nil, err))
-		return
+		return orNotFound(err, apperr.UserNotFound, "user not found")
 	}
 
 	// Now, proceed to delete the user
 	err = s.store.DeleteUser(ctx, req.Username)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, util.CreateResponse(http.StatusInternalServerError, nil, err))
-		return
+		return err
 	}
 
 	// Successfully deleted
@@ -140,4 +255,5 @@ func (s *Server) deleteUser(ctx *gin.Context) {
 		Message: "User deleted",
 	}
 	ctx.JSON(http.StatusOK, util.CreateResponse(http.StatusOK, resp, nil))
+	return nil
 }
@@ -6,11 +6,12 @@ func (server *Server) setUpUserRoutes(router *gin.RouterGroup) {
 	usersGroup := router.Group("/users")
 
 	{ //create user routes doesnt need token
-		usersGroup.POST("", server.createUser) // create user
+		usersGroup.POST("", handle(server.createUser)) // create user
 
-		authUsersGroup := usersGroup.Group("").Use(authMiddleware(server.tokenMaker))
+		authUsersGroup := usersGroup.Group("").Use(authMiddleware(server.tokenMaker, server.sessions))
 
-		authUsersGroup.GET("/:username", server.getUser)       // get user by username
-		authUsersGroup.DELETE("/:username", server.deleteUser) // delete user by username
+		authUsersGroup.GET("/:username", handle(server.getUser))       // get user by username
+		authUsersGroup.PATCH("/:username", handle(server.updateUser))  // update the authenticated user
+		authUsersGroup.DELETE("/:username", handle(server.deleteUser)) // delete user by username
 	}
 }
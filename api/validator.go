@@ -1,15 +1,40 @@
 package api
 
 import (
-	"github.com/S-Devoe/golang-simple-bank/util"
+	"context"
+
+	"github.com/S-Devoe/golang-simple-bank/val"
 	"github.com/go-playground/validator/v10"
 )
 
-var validCurrency validator.Func = func(fieldLevel validator.FieldLevel) bool {
-	if currency, ok := fieldLevel.Field().Interface().(string); ok {
-		// check if currency is supported
-		return util.IsSupportedCurrency(currency)
+// stringRules maps binding tags to the val rules the gRPC handlers apply to the same fields
+var stringRules = map[string]func(string) error{
+	"username":     val.ValidateUsername,
+	"fullname":     val.ValidateFullName,
+	"password":     val.ValidatePassword,
+	"emailaddress": val.ValidateEmail,
+	"memo":         val.ValidateMemo,
+	"reference":    val.ValidateReference,
+	"endtoendid":   val.ValidateEndToEndID,
+	"nickname":     val.ValidateNickname,
+}
+
+// validString adapts a val rule to a binding tag
+func validString(rule func(string) error) validator.Func {
+	return func(fieldLevel validator.FieldLevel) bool {
+		value, ok := fieldLevel.Field().Interface().(string)
+		return ok && rule(value) == nil
+	}
+}
 
+// validCurrency accepts currencies that are enabled in the cached currency registry
+func (server *Server) validCurrency() validator.Func {
+	return func(fieldLevel validator.FieldLevel) bool {
+		if currency, ok := fieldLevel.Field().Interface().(string); ok {
+			// check if currency is supported
+			_, ok := server.currencies.get(context.Background(), currency)
+			return ok
+		}
+		return false
 	}
-	return false
 }
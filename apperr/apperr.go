@@ -0,0 +1,155 @@
+// Package apperr is the error taxonomy shared by the REST and gRPC handlers: every failure a client can see
+// carries a stable Code, which maps to an http status for problem responses and to a gRPC status code.
+package apperr
+
+import (
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+)
+
+// Code is the machine-readable name of a failure, clients switch on it rather than on messages
+type Code string
+
+const (
+	InvalidArgument      Code = "INVALID_ARGUMENT"
+	Unauthenticated      Code = "UNAUTHENTICATED"
+	InvalidCredentials   Code = "INVALID_CREDENTIALS"
+	PermissionDenied     Code = "PERMISSION_DENIED"
+	UserNotFound         Code = "USER_NOT_FOUND"
+	UserAlreadyExists    Code = "USER_ALREADY_EXISTS"
+	EmailTaken           Code = "EMAIL_TAKEN"
+	InvalidEmailCode     Code = "INVALID_EMAIL_CODE"
+	AccountNotFound      Code = "ACCOUNT_NOT_FOUND"
+	AccountNotOwned      Code = "ACCOUNT_NOT_OWNED"
+	AccountAlreadyExists Code = "ACCOUNT_ALREADY_EXISTS"
+	CurrencyMismatch     Code = "CURRENCY_MISMATCH"
+	InsufficientFunds    Code = "INSUFFICIENT_FUNDS"
+	SameAccount          Code = "SAME_ACCOUNT"
+	IdempotencyKeyReused Code = "IDEMPOTENCY_KEY_REUSED"
+	PayeeNotFound        Code = "PAYEE_NOT_FOUND"
+	PayeeNotOwned        Code = "PAYEE_NOT_OWNED"
+	PayeeAlreadyExists   Code = "PAYEE_ALREADY_EXISTS"
+	PayeeCooldown        Code = "PAYEE_COOLDOWN"
+	AliasNotFound        Code = "ALIAS_NOT_FOUND"
+	AliasNotOwned        Code = "ALIAS_NOT_OWNED"
+	AliasTaken           Code = "ALIAS_TAKEN"
+	CurrencyNotFound     Code = "CURRENCY_NOT_FOUND"
+	CurrencyExists       Code = "CURRENCY_ALREADY_EXISTS"
+	InvalidRefreshToken  Code = "INVALID_REFRESH_TOKEN"
+	SessionNotFound      Code = "SESSION_NOT_FOUND"
+	SessionBlocked       Code = "SESSION_BLOCKED"
+	SessionUserMismatch  Code = "SESSION_USER_MISMATCH"
+	SessionTokenMismatch Code = "SESSION_TOKEN_MISMATCH"
+	SessionExpired       Code = "SESSION_EXPIRED"
+	RequestTooLarge      Code = "REQUEST_TOO_LARGE"
+	Timeout              Code = "TIMEOUT"
+	Unavailable          Code = "UNAVAILABLE"
+	Internal             Code = "INTERNAL"
+)
+
+type kind struct {
+	status int
+	grpc   codes.Code
+	title  string
+}
+
+// kinds keeps the http statuses the REST api answered with before the codes existed, so clients don't break
+var kinds = map[Code]kind{
+	InvalidArgument:      {http.StatusBadRequest, codes.InvalidArgument, "Invalid argument"},
+	Unauthenticated:      {http.StatusUnauthorized, codes.Unauthenticated, "Unauthenticated"},
+	InvalidCredentials:   {http.StatusUnauthorized, codes.Unauthenticated, "Invalid credentials"},
+	PermissionDenied:     {http.StatusForbidden, codes.PermissionDenied, "Permission denied"},
+	UserNotFound:         {http.StatusNotFound, codes.NotFound, "User not found"},
+	UserAlreadyExists:    {http.StatusForbidden, codes.AlreadyExists, "User already exists"},
+	EmailTaken:           {http.StatusForbidden, codes.AlreadyExists, "Email already taken"},
+	InvalidEmailCode:     {http.StatusBadRequest, codes.FailedPrecondition, "Invalid email verification code"},
+	AccountNotFound:      {http.StatusNotFound, codes.NotFound, "Account not found"},
+	AccountNotOwned:      {http.StatusUnauthorized, codes.PermissionDenied, "Account not owned"},
+	AccountAlreadyExists: {http.StatusForbidden, codes.AlreadyExists, "Account already exists"},
+	CurrencyMismatch:     {http.StatusBadRequest, codes.FailedPrecondition, "Currency mismatch"},
+	InsufficientFunds:    {http.StatusBadRequest, codes.FailedPrecondition, "Insufficient funds"},
+	SameAccount:          {http.StatusBadRequest, codes.InvalidArgument, "Same account"},
+	IdempotencyKeyReused: {http.StatusConflict, codes.AlreadyExists, "Idempotency key reused"},
+	PayeeNotFound:        {http.StatusNotFound, codes.NotFound, "Payee not found"},
+	PayeeNotOwned:        {http.StatusUnauthorized, codes.PermissionDenied, "Payee not owned"},
+	PayeeAlreadyExists:   {http.StatusForbidden, codes.AlreadyExists, "Payee already exists"},
+	PayeeCooldown:        {http.StatusForbidden, codes.FailedPrecondition, "Payee in cooldown"},
+	AliasNotFound:        {http.StatusNotFound, codes.NotFound, "Alias not found"},
+	AliasNotOwned:        {http.StatusUnauthorized, codes.PermissionDenied, "Alias not owned"},
+	AliasTaken:           {http.StatusForbidden, codes.AlreadyExists, "Alias taken"},
+	CurrencyNotFound:     {http.StatusNotFound, codes.NotFound, "Currency not found"},
+	CurrencyExists:       {http.StatusForbidden, codes.AlreadyExists, "Currency already exists"},
+	InvalidRefreshToken:  {http.StatusUnauthorized, codes.Unauthenticated, "Invalid refresh token"},
+	SessionNotFound:      {http.StatusNotFound, codes.NotFound, "Session not found"},
+	SessionBlocked:       {http.StatusUnauthorized, codes.Unauthenticated, "Session blocked"},
+	SessionUserMismatch:  {http.StatusUnauthorized, codes.Unauthenticated, "Session user mismatch"},
+	SessionTokenMismatch: {http.StatusUnauthorized, codes.Unauthenticated, "Session token mismatch"},
+	SessionExpired:       {http.StatusUnauthorized, codes.Unauthenticated, "Session expired"},
+	RequestTooLarge:      {http.StatusRequestEntityTooLarge, codes.ResourceExhausted, "Request too large"},
+	Timeout:              {http.StatusGatewayTimeout, codes.DeadlineExceeded, "Request timed out"},
+	Unavailable:          {http.StatusServiceUnavailable, codes.Unavailable, "Service unavailable"},
+	Internal:             {http.StatusInternalServerError, codes.Internal, "Internal server error"},
+}
+
+// HTTPStatus is the status problem responses carrying code are sent with
+func (code Code) HTTPStatus() int {
+	if k, ok := kinds[code]; ok {
+		return k.status
+	}
+	return http.StatusInternalServerError
+}
+
+// GRPCCode is the status code gRPC errors carrying code are sent with
+func (code Code) GRPCCode() codes.Code {
+	if k, ok := kinds[code]; ok {
+		return k.grpc
+	}
+	return codes.Internal
+}
+
+// Title is the short, human-readable summary of code
+func (code Code) Title() string {
+	if k, ok := kinds[code]; ok {
+		return k.title
+	}
+	return kinds[Internal].title
+}
+
+// FieldViolation names a request field and what is wrong with it
+type FieldViolation struct {
+	Field       string `json:"field"`
+	Description string `json:"description"`
+}
+
+// Error is a failure that is safe to show to clients as it is
+type Error struct {
+	Code     Code
+	Message  string
+	Fields   []FieldViolation
+	Metadata map[string]string // Metadata: ids and values that help the client act on the error
+}
+
+func New(code Code, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// Invalid builds an INVALID_ARGUMENT error listing the offending fields
+func Invalid(fields ...FieldViolation) *Error {
+	return &Error{Code: InvalidArgument, Message: "invalid parameters", Fields: fields}
+}
+
+func Field(field string, description string) FieldViolation {
+	return FieldViolation{Field: field, Description: description}
+}
+
+// WithMetadata returns a copy of err carrying metadata
+func (err *Error) WithMetadata(metadata map[string]string) *Error {
+	copied := *err
+	copied.Metadata = metadata
+	return &copied
+}
+
+func (err *Error) Error() string {
+	return err.Message
+}
@@ -0,0 +1,48 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestCodes(t *testing.T) {
+	for code, k := range kinds {
+		require.Equal(t, k.status, code.HTTPStatus(), code)
+		require.Equal(t, k.grpc, code.GRPCCode(), code)
+		require.NotEmpty(t, code.Title(), code)
+	}
+
+	// a code missing from the table is treated as internal rather than leaking a zero status
+	unknown := Code("NOT_A_CODE")
+	require.Equal(t, http.StatusInternalServerError, unknown.HTTPStatus())
+	require.Equal(t, codes.Internal, unknown.GRPCCode())
+}
+
+func TestErrorAs(t *testing.T) {
+	notFound := New(AccountNotFound, "account not found")
+	err := fmt.Errorf("cannot fetch account: %w", notFound.WithMetadata(map[string]string{"account_id": "7"}))
+
+	var appErr *Error
+	require.True(t, errors.As(err, &appErr))
+	require.Equal(t, AccountNotFound, appErr.Code)
+	require.Equal(t, "7", appErr.Metadata["account_id"])
+	require.Nil(t, notFound.Metadata)
+}
+
+func TestNewProblem(t *testing.T) {
+	problem := NewProblem(Invalid(Field("amount", "must be at least 1")), "/api/v1/transfers")
+	require.Equal(t, Problem{
+		Type:     "urn:simplebank:error:INVALID_ARGUMENT",
+		Title:    "Invalid argument",
+		Status:   http.StatusBadRequest,
+		Detail:   "invalid parameters",
+		Instance: "/api/v1/transfers",
+		Code:     InvalidArgument,
+		Errors:   []FieldViolation{{Field: "amount", Description: "must be at least 1"}},
+	}, problem)
+}
@@ -0,0 +1,34 @@
+package apperr
+
+// ProblemContentType is the media type of RFC 7807 problem details
+const ProblemContentType = "application/problem+json"
+
+// typePrefix makes the code a URI, as RFC 7807 wants the problem type to be
+const typePrefix = "urn:simplebank:error:"
+
+// Problem is the RFC 7807 body the REST api answers failures with
+type Problem struct {
+	Type      string            `json:"type" example:"urn:simplebank:error:INSUFFICIENT_FUNDS"`
+	Title     string            `json:"title" example:"Insufficient funds"`
+	Status    int               `json:"status" example:"400"`
+	Detail    string            `json:"detail,omitempty" example:"insufficient balance"`
+	Instance  string            `json:"instance,omitempty" example:"/api/v1/transfers"`
+	Code      Code              `json:"code" example:"INSUFFICIENT_FUNDS"`
+	RequestID string            `json:"request_id,omitempty"`
+	Errors    []FieldViolation  `json:"errors,omitempty"`
+	Metadata  map[string]string `json:"metadata,omitempty"`
+}
+
+// NewProblem describes err; instance is the path of the request that failed
+func NewProblem(err *Error, instance string) Problem {
+	return Problem{
+		Type:     typePrefix + string(err.Code),
+		Title:    err.Code.Title(),
+		Status:   err.Code.HTTPStatus(),
+		Detail:   err.Message,
+		Instance: instance,
+		Code:     err.Code,
+		Errors:   err.Fields,
+		Metadata: err.Metadata,
+	}
+}
@@ -0,0 +1,234 @@
+// Package config holds the settings every command runs with. Each setting is named by the env tag of its
+// field, and Load reads it from the defaults, a yaml config file, the environment and the command line flags.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/S-Devoe/golang-simple-bank/logging"
+)
+
+// Config is typed, a setting that doesn't parse is a load error rather than a silent fallback to its default.
+// Fields tagged secret are redacted by Dump.
+type Config struct {
+	// Environment is development or production, production refuses the conveniences meant for local use
+	Environment string `env:"ENVIRONMENT" default:"development"`
+	// DBSource, when set, is the postgres connection string as it is, instead of the one built from the DB_* fields
+	DBSource string `env:"DB_SOURCE" secret:"password"`
+	DBHost   string `env:"DB_HOST" default:"localhost"`
+	DBPort   int    `env:"DB_PORT" default:"5432"`
+	DBName   string `env:"DB_NAME" default:"simple_bank"`
+	DBUser   string `env:"DB_USER" default:"root"`
+	// DBPassword has no default, a deployment that forgets it fails to start instead of trying a well-known one
+	DBPassword string `env:"DB_PASSWORD" secret:"true"`
+	DBSSLMode  string `env:"DB_SSL_MODE" default:"disable"`
+	// how long opening a connection may take, and how long a single statement may run (0 for no limit)
+	DBConnectTimeout   time.Duration `env:"DB_CONNECT_TIMEOUT" default:"5s"`
+	DBStatementTimeout time.Duration `env:"DB_STATEMENT_TIMEOUT" default:"0s"`
+	// size and recycling of the connection pool, serving opens one more connection of its own for account notifications
+	DBMaxConns        int32         `env:"DB_MAX_CONNS" default:"10"`
+	DBMinConns        int32         `env:"DB_MIN_CONNS" default:"0"`
+	DBMaxConnLifetime time.Duration `env:"DB_MAX_CONN_LIFETIME" default:"1h"`
+	DBMaxConnIdleTime time.Duration `env:"DB_MAX_CONN_IDLE_TIME" default:"30m"`
+
+	TokenSymmetricKey    string        `env:"TOKEN_SYMMETRIC_KEY" secret:"true"`
+	AccessTokenDuration  time.Duration `env:"ACCESS_TOKEN_DURATION" default:"24h"`
+	RefreshTokenDuration time.Duration `env:"REFRESH_TOKEN_DURATION" default:"48h"`
+	HttpServerAddress    string        `env:"HTTP_SERVER_ADDRESS"`
+	GrpcServerAddress    string        `env:"GRPC_SERVER_ADDRESS"`
+	// the admin listener serving /metrics, kept off the api listeners and on loopback unless set otherwise.
+	// Without METRICS_SERVER_ADDRESS metrics are collected but not served.
+	MetricsServerAddress string `env:"METRICS_SERVER_ADDRESS" default:"127.0.0.1:9091"`
+	// transfers above the currency's payee_cooldown_limit need the recipient saved as a payee at least this long ago
+	PayeeCooldownDuration time.Duration `env:"PAYEE_COOLDOWN_DURATION" default:"24h"`
+	// TrustedProxies lists the addresses and CIDR ranges of the proxies in front of the servers, comma separated.
+	// The client address is the right-most X-Forwarded-For hop that isn't one of them, or the peer address
+	// when the request doesn't come through one. Without it X-Forwarded-For is ignored.
+	TrustedProxies string `env:"TRUSTED_PROXIES"`
+	// the codes confirming a user's email address are mailed as links to EmailVerificationURL and expire after
+	// EmailVerificationDuration
+	EmailVerificationURL      string        `env:"EMAIL_VERIFICATION_URL" default:"http://localhost:8080/api/v1/verify_email"`
+	EmailVerificationDuration time.Duration `env:"EMAIL_VERIFICATION_DURATION" default:"24h"`
+	// the SMTP server mail goes out through, without SMTP_ADDRESS mail is only logged, which production refuses
+	SMTPAddress  string `env:"SMTP_ADDRESS"`
+	SMTPUsername string `env:"SMTP_USERNAME"`
+	SMTPPassword string `env:"SMTP_PASSWORD" secret:"true"`
+	MailFrom     string `env:"MAIL_FROM" default:"no-reply@simplebank.local"`
+	// how long the api keeps its copy of the currency registry before reloading it
+	CurrencyCacheDuration time.Duration `env:"CURRENCY_CACHE_DURATION" default:"5m"`
+	// apply pending migrations when the server starts instead of only checking the schema is current
+	MigrateOnStartup bool `env:"MIGRATE_ON_STARTUP" default:"false"`
+	// how long servers wait for in-flight requests to finish after SIGTERM before closing connections
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" default:"30s"`
+	// how long servers keep serving after SIGTERM marks them not ready, so load balancers stop routing to them first
+	ShutdownDrainDelay time.Duration `env:"SHUTDOWN_DRAIN_DELAY" default:"5s"`
+	// how often the server pings the database and checks the schema version to decide whether it is ready
+	HealthCheckInterval time.Duration `env:"HEALTH_CHECK_INTERVAL" default:"10s"`
+	// LogLevel is debug, info, warn or error and LogFormat is json or text
+	LogLevel  string `env:"LOG_LEVEL" default:"info"`
+	LogFormat string `env:"LOG_FORMAT" default:"json"`
+	// where spans go: none or stdout
+	TracingExporter string `env:"TRACING_EXPORTER" default:"none"`
+	// the http api refuses request bodies above MaxRequestBodyBytes, and cancels requests running past
+	// RequestTimeout, or ReportRequestTimeout for the analytics and search routes
+	MaxRequestBodyBytes  int64         `env:"MAX_REQUEST_BODY_BYTES" default:"1048576"`
+	RequestTimeout       time.Duration `env:"REQUEST_TIMEOUT" default:"10s"`
+	ReportRequestTimeout time.Duration `env:"REPORT_REQUEST_TIMEOUT" default:"30s"`
+}
+
+// DSN is the postgres connection string commands connect with: DB_SOURCE if it is set, otherwise the url
+// built from the DB_* fields. Pool settings are not part of it, migrations open a plain connection with it.
+func (config Config) DSN() string {
+	if config.DBSource != "" {
+		return config.DBSource
+	}
+
+	query := url.Values{}
+	query.Set("sslmode", config.DBSSLMode)
+	if config.DBConnectTimeout > 0 {
+		// libpq counts whole seconds, and 0 would mean no timeout at all
+		query.Set("connect_timeout", strconv.Itoa(max(1, int(config.DBConnectTimeout/time.Second))))
+	}
+	dsn := url.URL{
+		Scheme:   "postgresql",
+		User:     url.UserPassword(config.DBUser, config.DBPassword),
+		Host:     fmt.Sprintf("%s:%d", config.DBHost, config.DBPort),
+		Path:     "/" + config.DBName,
+		RawQuery: query.Encode(),
+	}
+	return dsn.String()
+}
+
+// TrustedProxyPrefixes parses TrustedProxies, a single address is a prefix of its full length
+func (config Config) TrustedProxyPrefixes() ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for _, entry := range strings.Split(config.TrustedProxies, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if prefix, err := netip.ParsePrefix(entry); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+			continue
+		}
+		addr, err := netip.ParseAddr(entry)
+		if err != nil {
+			return nil, fmt.Errorf("TRUSTED_PROXIES: %q is neither an address nor a CIDR range", entry)
+		}
+		prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+	}
+	return prefixes, nil
+}
+
+// Validate checks the settings every command relies on
+func (config Config) Validate() error {
+	var errs []error
+	if config.DBSource != "" {
+		if source, err := url.Parse(config.DBSource); err != nil || (source.Scheme != "postgres" && source.Scheme != "postgresql") {
+			errs = append(errs, errors.New("DB_SOURCE must be a postgresql:// url"))
+		}
+	} else {
+		if config.DBHost == "" || config.DBName == "" || config.DBUser == "" {
+			errs = append(errs, errors.New("DB_HOST, DB_NAME and DB_USER must be set when DB_SOURCE is not"))
+		}
+		if config.DBPassword == "" {
+			errs = append(errs, errors.New("DB_PASSWORD must be set when DB_SOURCE is not"))
+		}
+		if config.DBPort <= 0 || config.DBPort > 65535 {
+			errs = append(errs, fmt.Errorf("DB_PORT must be between 1 and 65535, got %d", config.DBPort))
+		}
+	}
+	if config.DBConnectTimeout < 0 || config.DBStatementTimeout < 0 {
+		errs = append(errs, errors.New("DB_CONNECT_TIMEOUT and DB_STATEMENT_TIMEOUT must not be negative"))
+	}
+	if config.DBMaxConns <= 0 {
+		errs = append(errs, errors.New("DB_MAX_CONNS must be positive"))
+	}
+	if config.DBMinConns < 0 || config.DBMinConns > config.DBMaxConns {
+		errs = append(errs, fmt.Errorf("DB_MIN_CONNS must be between 0 and DB_MAX_CONNS, got %d", config.DBMinConns))
+	}
+	if config.DBMaxConnLifetime <= 0 || config.DBMaxConnIdleTime <= 0 {
+		errs = append(errs, errors.New("DB_MAX_CONN_LIFETIME and DB_MAX_CONN_IDLE_TIME must be positive"))
+	}
+	if config.CurrencyCacheDuration <= 0 {
+		errs = append(errs, errors.New("CURRENCY_CACHE_DURATION must be positive"))
+	}
+	if _, err := logging.ParseLevel(config.LogLevel); err != nil {
+		errs = append(errs, fmt.Errorf("LOG_LEVEL: %w", err))
+	}
+	if config.LogFormat != "json" && config.LogFormat != "text" {
+		errs = append(errs, fmt.Errorf("LOG_FORMAT must be json or text, got %q", config.LogFormat))
+	}
+	if config.Environment != "development" && config.Environment != "production" {
+		errs = append(errs, fmt.Errorf("ENVIRONMENT must be development or production, got %q", config.Environment))
+	}
+	if config.TracingExporter != "none" && config.TracingExporter != "stdout" {
+		errs = append(errs, fmt.Errorf("TRACING_EXPORTER must be none or stdout, got %q", config.TracingExporter))
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateServer checks the settings needed to serve the http and/or grpc api
+func (config Config) ValidateServer(http, grpc bool) error {
+	var errs []error
+	if len(config.TokenSymmetricKey) != 32 {
+		errs = append(errs, fmt.Errorf("TOKEN_SYMMETRIC_KEY must be exactly 32 characters, got %d", len(config.TokenSymmetricKey)))
+	}
+	if config.AccessTokenDuration <= 0 || config.RefreshTokenDuration <= 0 {
+		errs = append(errs, errors.New("ACCESS_TOKEN_DURATION and REFRESH_TOKEN_DURATION must be positive"))
+	}
+	if config.ShutdownTimeout <= 0 {
+		errs = append(errs, errors.New("SHUTDOWN_TIMEOUT must be positive"))
+	}
+	if config.ShutdownDrainDelay < 0 {
+		errs = append(errs, errors.New("SHUTDOWN_DRAIN_DELAY must not be negative"))
+	}
+	if config.HealthCheckInterval <= 0 {
+		errs = append(errs, errors.New("HEALTH_CHECK_INTERVAL must be positive"))
+	}
+	if link, err := url.Parse(config.EmailVerificationURL); err != nil || (link.Scheme != "http" && link.Scheme != "https") || link.Host == "" {
+		errs = append(errs, errors.New("EMAIL_VERIFICATION_URL must be an http:// or https:// url"))
+	}
+	if config.EmailVerificationDuration <= 0 {
+		errs = append(errs, errors.New("EMAIL_VERIFICATION_DURATION must be positive"))
+	}
+	if _, err := config.TrustedProxyPrefixes(); err != nil {
+		errs = append(errs, err)
+	}
+	if config.SMTPAddress == "" && config.Environment == "production" {
+		errs = append(errs, errors.New("SMTP_ADDRESS must be set in production, verification codes would go nowhere"))
+	}
+	if config.SMTPAddress != "" {
+		if _, _, err := net.SplitHostPort(config.SMTPAddress); err != nil {
+			errs = append(errs, fmt.Errorf("SMTP_ADDRESS must be host:port, got %q", config.SMTPAddress))
+		}
+		if config.MailFrom == "" {
+			errs = append(errs, errors.New("MAIL_FROM must be set when SMTP_ADDRESS is"))
+		}
+	}
+	if config.MetricsServerAddress != "" {
+		if _, _, err := net.SplitHostPort(config.MetricsServerAddress); err != nil {
+			errs = append(errs, fmt.Errorf("METRICS_SERVER_ADDRESS must be host:port, got %q", config.MetricsServerAddress))
+		}
+	}
+	if http && config.HttpServerAddress == "" {
+		errs = append(errs, errors.New("HTTP_SERVER_ADDRESS must be set"))
+	}
+	if http && config.MaxRequestBodyBytes <= 0 {
+		errs = append(errs, errors.New("MAX_REQUEST_BODY_BYTES must be positive"))
+	}
+	if http && (config.RequestTimeout <= 0 || config.ReportRequestTimeout <= 0) {
+		errs = append(errs, errors.New("REQUEST_TIMEOUT and REPORT_REQUEST_TIMEOUT must be positive"))
+	}
+	if grpc && config.GrpcServerAddress == "" {
+		errs = append(errs, errors.New("GRPC_SERVER_ADDRESS must be set"))
+	}
+	return errors.Join(errs...)
+}
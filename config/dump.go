@@ -0,0 +1,74 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "REDACTED"
+
+// Dump writes the effective config as a yaml config file, with the secrets replaced by REDACTED
+func (config Config) Dump(w io.Writer) error {
+	document := &yaml.Node{Kind: yaml.MappingNode}
+	for _, s := range settings {
+		value := formatValue(reflect.ValueOf(config).Field(s.index))
+		document.Content = append(document.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: s.fileKey()},
+			&yaml.Node{Kind: yaml.ScalarNode, Value: redact(s, value)},
+		)
+	}
+
+	encoder := yaml.NewEncoder(w)
+	if err := encoder.Encode(document); err != nil {
+		return fmt.Errorf("cannot write config: %w", err)
+	}
+	return encoder.Close()
+}
+
+// formatValue is the inverse of parseInto
+func formatValue(field reflect.Value) string {
+	if duration, ok := field.Interface().(time.Duration); ok {
+		return duration.String()
+	}
+	switch field.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(field.Bool())
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(field.Int(), 10)
+	case reflect.Float64:
+		return strconv.FormatFloat(field.Float(), 'f', -1, 64)
+	}
+	return field.String()
+}
+
+// redact hides a secret setting entirely, or only the password of a secret url, leaving empty values visible
+func redact(s setting, value string) string {
+	if value == "" {
+		return value
+	}
+	switch s.secret {
+	case "true":
+		return redacted
+	case "password":
+		source, err := url.Parse(value)
+		if err != nil {
+			return redacted
+		}
+		if _, ok := source.User.Password(); ok {
+			source.User = url.UserPassword(source.User.Username(), redacted)
+		}
+		// the query string can carry a password too
+		if query := source.Query(); query.Has("password") {
+			query.Set("password", redacted)
+			source.RawQuery = query.Encode()
+		}
+		return source.String()
+	}
+	return value
+}
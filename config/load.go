@@ -0,0 +1,190 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFileEnv names the yaml config file when the -config flag doesn't
+const ConfigFileEnv = "CONFIG_FILE"
+
+// setting is one Config field, known as NAME in the environment, name in the config file and -name on the command line
+type setting struct {
+	index    int
+	env      string
+	fallback string
+	secret   string
+}
+
+func (s setting) fileKey() string {
+	return strings.ToLower(s.env)
+}
+
+func (s setting) flagName() string {
+	return strings.ReplaceAll(s.fileKey(), "_", "-")
+}
+
+var settings = func() []setting {
+	configType := reflect.TypeOf(Config{})
+	var list []setting
+	for i := range configType.NumField() {
+		field := configType.Field(i)
+		list = append(list, setting{
+			index:    i,
+			env:      field.Tag.Get("env"),
+			fallback: field.Tag.Get("default"),
+			secret:   field.Tag.Get("secret"),
+		})
+	}
+	return list
+}()
+
+// Load builds the config from, lowest precedence first: the defaults, the yaml file named by -config or
+// CONFIG_FILE, a .env file in the working directory, the environment and the flags at the start of args.
+// It returns the arguments left after the flags, and an error listing every setting that doesn't parse or validate.
+func Load(args []string, lookupEnv func(string) (string, bool)) (Config, []string, error) {
+	flags := flag.NewFlagSet("simple-bank", flag.ContinueOnError)
+	configFile := flags.String("config", "", "yaml config file, overriding "+ConfigFileEnv)
+	flagValues := map[string]string{}
+	for _, s := range settings {
+		flags.Func(s.flagName(), "overrides "+s.env, func(value string) error {
+			flagValues[s.env] = value
+			return nil
+		})
+	}
+	// the caller prints its own usage, the settings are listed by Dump
+	flags.SetOutput(io.Discard)
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return Config{}, nil, err
+		}
+		return Config{}, nil, fmt.Errorf("cannot parse flags: %w", err)
+	}
+
+	var config Config
+	var errs []error
+	set := func(s setting, value, source string) {
+		if err := parseInto(&config, s, value); err != nil {
+			errs = append(errs, fmt.Errorf("%s from %s: %w", s.env, source, err))
+		}
+	}
+
+	for _, s := range settings {
+		set(s, s.fallback, "default")
+	}
+
+	if *configFile == "" {
+		*configFile, _ = lookupEnv(ConfigFileEnv)
+	}
+	if *configFile != "" {
+		// unknown keys are listed with the other errors, the keys that are known still apply
+		fileValues, err := readConfigFile(*configFile)
+		errs = append(errs, err)
+		for _, s := range settings {
+			if value, ok := fileValues[s.fileKey()]; ok {
+				set(s, value, *configFile)
+			}
+		}
+	}
+
+	// a missing .env is fine, deployments set the real environment instead
+	dotEnv, err := godotenv.Read()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Config{}, nil, fmt.Errorf("cannot read .env: %w", err)
+	}
+	for _, s := range settings {
+		if value, ok := lookupEnv(s.env); ok {
+			set(s, value, "env")
+		} else if value, ok := dotEnv[s.env]; ok {
+			set(s, value, ".env")
+		}
+	}
+
+	for _, s := range settings {
+		if value, ok := flagValues[s.env]; ok {
+			set(s, value, "-"+s.flagName())
+		}
+	}
+
+	// a value that doesn't parse leaves the previous one in place, so it isn't reported twice here
+	errs = append(errs, config.Validate())
+	return config, flags.Args(), errors.Join(errs...)
+}
+
+// readConfigFile returns the settings in a yaml file as the strings the environment would hold them as
+func readConfigFile(path string) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read config file: %w", err)
+	}
+	var nodes map[string]yaml.Node
+	if err := yaml.Unmarshal(content, &nodes); err != nil {
+		return nil, fmt.Errorf("cannot parse config file %s: %w", path, err)
+	}
+
+	known := map[string]bool{}
+	for _, s := range settings {
+		known[s.fileKey()] = true
+	}
+	values := map[string]string{}
+	var errs []error
+	for key, node := range nodes {
+		switch {
+		case !known[key]:
+			errs = append(errs, fmt.Errorf("%s: unknown setting %q", path, key))
+		case node.Kind != yaml.ScalarNode:
+			errs = append(errs, fmt.Errorf("%s: %s must be a single value", path, key))
+		default:
+			values[key] = node.Value
+		}
+	}
+	return values, errors.Join(errs...)
+}
+
+func parseInto(config *Config, s setting, value string) error {
+	field := reflect.ValueOf(config).Elem().Field(s.index)
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		duration, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		field.SetInt(int64(duration))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		field.SetBool(b)
+	case reflect.Int, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(n)
+	case reflect.Float64:
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported setting type %s", field.Type())
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+CREATE TABLE "payees" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "nickname" varchar NOT NULL,
+  "account_id" bigint NOT NULL,
+  "display_name" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "payees" ("owner");
+
+COMMENT ON COLUMN "payees"."display_name" IS 'verified full name of the target account owner';
+
+ALTER TABLE "payees" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "payees" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "payees" ADD CONSTRAINT "owner_account_key" UNIQUE ("owner", "account_id");
+
+ALTER TABLE "payees" ADD CONSTRAINT "owner_nickname_key" UNIQUE ("owner", "nickname");
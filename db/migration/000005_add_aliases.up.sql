@@ -0,0 +1,14 @@
+CREATE TABLE "aliases" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar NOT NULL,
+  "alias" varchar UNIQUE NOT NULL,
+  "kind" varchar NOT NULL,
+  "is_verified" boolean NOT NULL DEFAULT false,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "aliases" ("username");
+
+COMMENT ON COLUMN "aliases"."alias" IS 'normalized handle: lower-case email, @tag or E.164-style phone number';
+
+ALTER TABLE "aliases" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
@@ -0,0 +1,11 @@
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "transfer_id";
+
+DROP INDEX IF EXISTS "transfer_search_idx";
+
+DROP INDEX IF EXISTS "transfer_end_to_end_id_key";
+
+ALTER TABLE IF EXISTS "transfer" DROP COLUMN IF EXISTS "end_to_end_id";
+
+ALTER TABLE IF EXISTS "transfer" DROP COLUMN IF EXISTS "reference";
+
+ALTER TABLE IF EXISTS "transfer" DROP COLUMN IF EXISTS "memo";
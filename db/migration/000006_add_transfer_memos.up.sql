@@ -0,0 +1,15 @@
+ALTER TABLE "transfer" ADD COLUMN "memo" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "transfer" ADD COLUMN "reference" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "transfer" ADD COLUMN "end_to_end_id" varchar NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN "transfer"."end_to_end_id" IS 'client supplied id, unique per sending account';
+
+CREATE UNIQUE INDEX "transfer_end_to_end_id_key" ON "transfer" ("from_account_id", "end_to_end_id") WHERE "end_to_end_id" <> '';
+
+CREATE INDEX "transfer_search_idx" ON "transfer" USING GIN (to_tsvector('simple', "memo" || ' ' || "reference" || ' ' || "end_to_end_id"));
+
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint;
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfer" ("id");
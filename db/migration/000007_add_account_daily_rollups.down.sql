@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS "transfer_created_at_idx";
+
+DROP TABLE IF EXISTS "account_daily_rollups";
@@ -0,0 +1,40 @@
+CREATE TABLE "account_daily_rollups" (
+  "account_id" bigint NOT NULL,
+  "day" timestamptz NOT NULL,
+  "inflow" float NOT NULL DEFAULT 0,
+  "outflow" float NOT NULL DEFAULT 0,
+  "closing_balance" float NOT NULL,
+  "entry_count" bigint NOT NULL DEFAULT 0,
+  PRIMARY KEY ("account_id", "day")
+);
+
+COMMENT ON COLUMN "account_daily_rollups"."day" IS 'midnight UTC of the day the entries were booked';
+
+COMMENT ON COLUMN "account_daily_rollups"."outflow" IS 'outflow is stored as a positive amount';
+
+ALTER TABLE "account_daily_rollups" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+CREATE INDEX ON "transfer" ("created_at");
+
+-- backfill from the existing entries, walking balances back from the current one
+WITH daily AS (
+  SELECT
+    account_id,
+    date_trunc('day', created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' AS day,
+    SUM(GREATEST(amount, 0)) AS inflow,
+    SUM(GREATEST(-amount, 0)) AS outflow,
+    SUM(amount) AS net,
+    COUNT(*) AS entry_count
+  FROM entries
+  GROUP BY 1, 2
+)
+INSERT INTO "account_daily_rollups" ("account_id", "day", "inflow", "outflow", "closing_balance", "entry_count")
+SELECT
+  d.account_id,
+  d.day,
+  d.inflow,
+  d.outflow,
+  a.balance - COALESCE(SUM(d.net) OVER (PARTITION BY d.account_id ORDER BY d.day DESC ROWS BETWEEN UNBOUNDED PRECEDING AND 1 PRECEDING), 0),
+  d.entry_count
+FROM daily d
+JOIN accounts a ON a.id = d.account_id;
@@ -0,0 +1,22 @@
+CREATE TABLE "currencies" (
+  "code" varchar(3) PRIMARY KEY,
+  "exponent" smallint NOT NULL DEFAULT 2,
+  "symbol" varchar NOT NULL,
+  "enabled" boolean NOT NULL DEFAULT true,
+  "min_transfer_amount" float8 NOT NULL DEFAULT 1,
+  "max_transfer_amount" float8 NOT NULL DEFAULT 1000000,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  CONSTRAINT "currencies_exponent_check" CHECK ("exponent" BETWEEN 0 AND 4),
+  CONSTRAINT "currencies_transfer_limits_check" CHECK ("min_transfer_amount" > 0 AND "max_transfer_amount" >= "min_transfer_amount")
+);
+
+COMMENT ON COLUMN "currencies"."exponent" IS 'number of minor-unit decimals, e.g. 2 for USD and 0 for JPY';
+
+INSERT INTO "currencies" ("code", "exponent", "symbol") VALUES
+  ('USD', 2, '$'),
+  ('NGN', 2, '₦'),
+  ('EUR', 2, '€'),
+  ('CAD', 2, 'CA$');
+
+ALTER TABLE "users" ADD COLUMN "role" varchar NOT NULL DEFAULT 'depositor';
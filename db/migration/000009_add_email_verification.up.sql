@@ -0,0 +1,2 @@
+-- emails are unverified until the user confirms them, including after changing their address
+ALTER TABLE "users" ADD COLUMN "is_email_verified" boolean NOT NULL DEFAULT false;
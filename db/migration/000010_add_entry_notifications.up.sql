@@ -0,0 +1,11 @@
+-- every new entry wakes the account's watchers; postgres delivers the notification once the transaction commits
+CREATE FUNCTION notify_account_entry() RETURNS trigger AS $$
+BEGIN
+  PERFORM pg_notify('account_entries', NEW.account_id::text);
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "entries_notify_account"
+AFTER INSERT ON "entries"
+FOR EACH ROW EXECUTE FUNCTION notify_account_entry();
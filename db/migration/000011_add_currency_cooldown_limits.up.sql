@@ -0,0 +1,7 @@
+-- the cap on transfers to recipients that aren't an established payee, in the currency's own units
+ALTER TABLE "currencies" ADD COLUMN "payee_cooldown_limit" float8 NOT NULL DEFAULT 1000;
+
+ALTER TABLE "currencies" ADD CONSTRAINT "currencies_payee_cooldown_limit_check" CHECK ("payee_cooldown_limit" > 0);
+
+-- roughly the same value as 1000 USD
+UPDATE "currencies" SET "payee_cooldown_limit" = 1500000 WHERE "code" = 'NGN';
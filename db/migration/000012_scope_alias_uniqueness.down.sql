@@ -0,0 +1,14 @@
+ALTER TABLE "aliases" DROP CONSTRAINT IF EXISTS "aliases_username_alias_key";
+
+DROP INDEX IF EXISTS "aliases_verified_alias_key";
+
+-- keep one row per alias, a verified one if there is one, so the global constraint can come back
+DELETE FROM "aliases" a
+WHERE NOT a."is_verified" AND EXISTS (
+  SELECT 1 FROM "aliases" b
+  WHERE b."alias" = a."alias" AND b."id" <> a."id" AND (b."is_verified" OR b."id" < a."id")
+);
+
+ALTER TABLE "aliases" ADD CONSTRAINT "aliases_alias_key" UNIQUE ("alias");
+
+COMMENT ON COLUMN "aliases"."alias" IS 'normalized handle: lower-case email, @tag or E.164-style phone number';
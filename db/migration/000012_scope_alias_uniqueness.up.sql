@@ -0,0 +1,8 @@
+-- only verified aliases are reserved, so registering someone else's email doesn't lock them out of it
+ALTER TABLE "aliases" DROP CONSTRAINT IF EXISTS "aliases_alias_key";
+
+CREATE UNIQUE INDEX "aliases_verified_alias_key" ON "aliases" ("alias") WHERE "is_verified";
+
+ALTER TABLE "aliases" ADD CONSTRAINT "aliases_username_alias_key" UNIQUE ("username", "alias");
+
+COMMENT ON COLUMN "aliases"."alias" IS 'normalized handle: lower-case email, @tag or +E.164 phone number';
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "counterparty_daily_rollups";
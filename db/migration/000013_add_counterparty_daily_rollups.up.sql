@@ -0,0 +1,34 @@
+CREATE TABLE "counterparty_daily_rollups" (
+  "account_id" bigint NOT NULL,
+  "counterparty_account_id" bigint NOT NULL,
+  "day" timestamptz NOT NULL,
+  "received" float NOT NULL DEFAULT 0,
+  "sent" float NOT NULL DEFAULT 0,
+  "transfer_count" bigint NOT NULL DEFAULT 0,
+  PRIMARY KEY ("account_id", "day", "counterparty_account_id")
+);
+
+COMMENT ON COLUMN "counterparty_daily_rollups"."day" IS 'midnight UTC of the day the transfers were made';
+
+ALTER TABLE "counterparty_daily_rollups" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "counterparty_daily_rollups" ADD FOREIGN KEY ("counterparty_account_id") REFERENCES "accounts" ("id");
+
+-- backfill from the existing transfers, one row for each side of them
+WITH sides AS (
+  SELECT from_account_id AS account_id, to_account_id AS counterparty_account_id, created_at, 0 AS received, amount AS sent
+  FROM transfer
+  UNION ALL
+  SELECT to_account_id, from_account_id, created_at, amount, 0
+  FROM transfer
+)
+INSERT INTO "counterparty_daily_rollups" ("account_id", "counterparty_account_id", "day", "received", "sent", "transfer_count")
+SELECT
+  account_id,
+  counterparty_account_id,
+  date_trunc('day', created_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC',
+  SUM(received),
+  SUM(sent),
+  COUNT(*)
+FROM sides
+GROUP BY 1, 2, 3;
@@ -0,0 +1,12 @@
+-- one-time codes confirming a user owns an email address, created at signup and whenever the address changes
+CREATE TABLE "verify_emails" (
+    "id" bigserial PRIMARY KEY,
+    "username" varchar NOT NULL REFERENCES "users" ("username") ON DELETE CASCADE,
+    "email" varchar NOT NULL,
+    "secret_code" varchar NOT NULL,
+    "is_used" boolean NOT NULL DEFAULT false,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    "expired_at" timestamptz NOT NULL
+);
+
+CREATE INDEX ON "verify_emails" ("username");
@@ -0,0 +1,176 @@
+// Package migration embeds the schema migrations so the binary can apply them without the migrate CLI.
+package migration
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/pgx/v5"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+//go:embed *.sql
+var files embed.FS
+
+// versionTable is where golang-migrate records the applied version, its default for postgres
+const versionTable = "schema_migrations"
+
+var (
+	ErrDirty  = errors.New("database schema is dirty, fix the failed migration and run `migrate force`")
+	ErrBehind = errors.New("database schema is behind the binary, run `migrate up`")
+)
+
+// Status describes where the database schema stands against the embedded migrations
+type Status struct {
+	Version uint `json:"version"` // Version: last applied migration, 0 when none has run
+	Dirty   bool `json:"dirty"`   // Dirty: the last migration failed halfway
+	Latest  uint `json:"latest"`  // Latest: newest migration embedded in the binary
+}
+
+// Migrator applies the embedded migrations to one database.
+// The pgx driver holds a Postgres advisory lock while migrating, so replicas starting together run them one at a time.
+type Migrator struct {
+	m      *migrate.Migrate
+	latest uint
+}
+
+// New creates a migrator for a postgres:// or postgresql:// connection string
+func New(dbSource string) (*Migrator, error) {
+	source, err := iofs.New(files, ".")
+	if err != nil {
+		return nil, fmt.Errorf("cannot read embedded migrations: %w", err)
+	}
+	latest, err := latestVersion(files)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := migrate.NewWithSourceInstance("iofs", source, driverURL(dbSource))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create migrator: %w", err)
+	}
+	return &Migrator{m: m, latest: latest}, nil
+}
+
+// Up applies every pending migration
+func (migrator *Migrator) Up() error {
+	if err := migrator.m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// UpSteps applies the given number of pending migrations
+func (migrator *Migrator) UpSteps(steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("steps must be positive, got %d", steps)
+	}
+	if err := migrator.m.Steps(steps); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// Down rolls back the given number of migrations
+func (migrator *Migrator) Down(steps int) error {
+	if steps <= 0 {
+		return fmt.Errorf("steps must be positive, got %d", steps)
+	}
+	if err := migrator.m.Steps(-steps); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
+	}
+	return nil
+}
+
+// Force marks version as applied and clean without running anything, to recover from a failed migration
+func (migrator *Migrator) Force(version int) error {
+	return migrator.m.Force(version)
+}
+
+// Status reports the applied and embedded versions
+func (migrator *Migrator) Status() (Status, error) {
+	version, dirty, err := migrator.m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return Status{}, err
+	}
+	return Status{Version: version, Dirty: dirty, Latest: migrator.latest}, nil
+}
+
+// CheckCurrent fails when the schema is dirty or has not caught up with the embedded migrations
+func (migrator *Migrator) CheckCurrent() error {
+	status, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	return status.check()
+}
+
+// CheckPool is CheckCurrent over a connection pool the caller already holds, for periodic health checks
+func CheckPool(ctx context.Context, pool *pgxpool.Pool) error {
+	latest, err := latestVersion(files)
+	if err != nil {
+		return err
+	}
+
+	status := Status{Latest: latest}
+	err = pool.QueryRow(ctx, "SELECT version, dirty FROM "+versionTable+" LIMIT 1").Scan(&status.Version, &status.Dirty)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("cannot read schema version: %w", err)
+	}
+	return status.check()
+}
+
+func (status Status) check() error {
+	if status.Dirty {
+		return fmt.Errorf("%w (version %d)", ErrDirty, status.Version)
+	}
+	if status.Version < status.Latest {
+		return fmt.Errorf("%w (at %d, want %d)", ErrBehind, status.Version, status.Latest)
+	}
+	return nil
+}
+
+// Close releases the database connection held by the migrator
+func (migrator *Migrator) Close() error {
+	sourceErr, dbErr := migrator.m.Close()
+	return errors.Join(sourceErr, dbErr)
+}
+
+// driverURL points the connection string at the pgx v5 driver
+func driverURL(dbSource string) string {
+	for _, scheme := range []string{"postgresql://", "postgres://"} {
+		if strings.HasPrefix(dbSource, scheme) {
+			return "pgx5://" + strings.TrimPrefix(dbSource, scheme)
+		}
+	}
+	return dbSource
+}
+
+// latestVersion returns the highest version among the embedded up migrations
+func latestVersion(fsys fs.FS) (uint, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return 0, fmt.Errorf("cannot read embedded migrations: %w", err)
+	}
+	var latest uint
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), ".up.sql") {
+			continue
+		}
+		var version uint
+		if _, err := fmt.Sscanf(entry.Name(), "%d_", &version); err != nil {
+			return 0, fmt.Errorf("invalid migration file name %s: %w", entry.Name(), err)
+		}
+		if version > latest {
+			latest = version
+		}
+	}
+	return latest, nil
+}
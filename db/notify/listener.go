@@ -0,0 +1,151 @@
+// Package notify turns the Postgres notifications raised by the entries trigger into per-account wakeups.
+package notify
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Channel is the notification channel the entries trigger publishes account ids on
+const Channel = "account_entries"
+
+// reconnectDelay is how long Run waits before listening again after losing its connection
+const reconnectDelay = time.Second
+
+// Listener holds one connection listening on Channel and wakes the subscribers of each notified account.
+// The connection is its own rather than one taken from the pool, so it doesn't eat into DB_MAX_CONNS.
+// A wakeup only says something changed: subscribers read the entries they missed from the database,
+// so notifications dropped while a subscriber is busy or the connection is down lose nothing.
+type Listener struct {
+	connConfig  *pgx.ConnConfig
+	mu          sync.Mutex
+	subscribers map[int64]map[chan struct{}]struct{}
+	closed      bool
+}
+
+// NewListener connects with connConfig, usually the pool's Config().ConnConfig
+func NewListener(connConfig *pgx.ConnConfig) *Listener {
+	return &Listener{
+		connConfig:  connConfig,
+		subscribers: make(map[int64]map[chan struct{}]struct{}),
+	}
+}
+
+// Subscribe returns a channel that receives a value whenever accountID gets a new entry, and a func to stop receiving.
+// The channel is closed once Run returns, so watchers can end their streams during shutdown.
+func (l *Listener) Subscribe(accountID int64) (<-chan struct{}, func()) {
+	wakeup := make(chan struct{}, 1)
+
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		close(wakeup)
+		return wakeup, func() {}
+	}
+	if l.subscribers[accountID] == nil {
+		l.subscribers[accountID] = make(map[chan struct{}]struct{})
+	}
+	l.subscribers[accountID][wakeup] = struct{}{}
+	l.mu.Unlock()
+
+	unsubscribe := func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		delete(l.subscribers[accountID], wakeup)
+		if len(l.subscribers[accountID]) == 0 {
+			delete(l.subscribers, accountID)
+		}
+	}
+	return wakeup, unsubscribe
+}
+
+// Run listens until ctx is done, reconnecting whenever the connection drops
+func (l *Listener) Run(ctx context.Context) error {
+	defer l.close()
+	for {
+		err := l.listen(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		slog.Warn("account notification listener disconnected, reconnecting", "error", err)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+func (l *Listener) listen(ctx context.Context) error {
+	conn, err := pgx.ConnectConfig(ctx, l.connConfig)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+Channel); err != nil {
+		return err
+	}
+	// anything committed while we were disconnected went unnoticed
+	l.wakeAll()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		l.dispatch(notification.Payload)
+	}
+}
+
+// dispatch wakes the subscribers of the account id in payload
+func (l *Listener) dispatch(payload string) {
+	accountID, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil {
+		slog.Warn("ignoring notification with an invalid payload", "channel", Channel, "payload", payload)
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for wakeup := range l.subscribers[accountID] {
+		wake(wakeup)
+	}
+}
+
+func (l *Listener) wakeAll() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, subscribers := range l.subscribers {
+		for wakeup := range subscribers {
+			wake(wakeup)
+		}
+	}
+}
+
+// close ends every subscription
+func (l *Listener) close() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, subscribers := range l.subscribers {
+		for wakeup := range subscribers {
+			close(wakeup)
+		}
+	}
+	l.subscribers = make(map[int64]map[chan struct{}]struct{})
+	l.closed = true
+}
+
+// wake never blocks: a wakeup already pending covers this one too
+func wake(wakeup chan struct{}) {
+	select {
+	case wakeup <- struct{}{}:
+	default:
+	}
+}
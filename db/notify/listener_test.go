@@ -0,0 +1,49 @@
+package notify
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDispatch(t *testing.T) {
+	listener := NewListener(nil)
+
+	wakeup1, unsubscribe1 := listener.Subscribe(1)
+	wakeup2, unsubscribe2 := listener.Subscribe(2)
+	defer unsubscribe2()
+
+	// notifications coalesce while a subscriber is busy
+	listener.dispatch("1")
+	listener.dispatch("1")
+	require.Len(t, wakeup1, 1)
+	require.Len(t, wakeup2, 0)
+
+	listener.dispatch("not-an-id")
+	require.Len(t, wakeup2, 0)
+
+	listener.wakeAll()
+	require.Len(t, wakeup1, 1)
+	require.Len(t, wakeup2, 1)
+
+	<-wakeup1
+	unsubscribe1()
+	listener.dispatch("1")
+	require.Len(t, wakeup1, 0)
+	require.NotContains(t, listener.subscribers, int64(1))
+}
+
+func TestClose(t *testing.T) {
+	listener := NewListener(nil)
+	wakeup, unsubscribe := listener.Subscribe(1)
+
+	listener.close()
+	_, ok := <-wakeup
+	require.False(t, ok)
+	unsubscribe()
+
+	// subscribing after shutdown gets a closed channel straight away
+	wakeup, _ = listener.Subscribe(1)
+	_, ok = <-wakeup
+	require.False(t, ok)
+}
@@ -10,11 +10,22 @@ RETURNING *;
 SELECT * FROM accounts
 WHERE id = $1 LIMIT 1;
 
+-- name: GetAccountByOwnerAndCurrency :one
+SELECT * FROM accounts
+WHERE owner = $1 AND currency = $2
+LIMIT 1;
+
 -- name: ListAccounts :many
 SELECT * FROM accounts
-WHERE owner = $1
+WHERE owner = sqlc.arg(owner) AND id > sqlc.arg(cursor)
 ORDER BY id
-LIMIT $2 OFFSET $3;
+LIMIT sqlc.arg('limit');
+
+-- name: ListAccountsReverse :many
+SELECT * FROM accounts
+WHERE owner = sqlc.arg(owner) AND id < sqlc.arg(cursor)
+ORDER BY id DESC
+LIMIT sqlc.arg('limit');
 
 -- NOTE FOR ME: balance is $2 and id is $1 in the UDEMY course.
 -- i want to see what happens if i change the order of the variables in the query. 
@@ -40,5 +51,13 @@ SET balance = balance + sqlc.arg(amount)
 WHERE id = sqlc.arg(id)
 RETURNING *;
 
+-- name: DebitAccountBalance :one
+-- the balance is checked under the row lock the update takes, so concurrent debits can't overdraw the account
+UPDATE accounts
+SET balance = balance - sqlc.arg(amount)
+WHERE id = sqlc.arg(id) AND balance >= sqlc.arg(amount)
+RETURNING *;
+
 -- name: CountAccounts :one
-SELECT COUNT(*) FROM accounts;
\ No newline at end of file
+SELECT COUNT(*) FROM accounts
+WHERE owner = $1;
\ No newline at end of file
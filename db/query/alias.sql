@@ -0,0 +1,41 @@
+-- name: CreateAlias :one
+INSERT INTO aliases (
+  username, alias, kind, is_verified
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetAlias :one
+SELECT * FROM aliases
+WHERE id = $1 LIMIT 1;
+
+-- name: GetVerifiedAlias :one
+SELECT * FROM aliases
+WHERE alias = $1 AND is_verified = true
+LIMIT 1;
+
+-- name: ListAliases :many
+SELECT * FROM aliases
+WHERE username = $1
+ORDER BY id;
+
+-- name: DeleteAlias :exec
+DELETE FROM aliases
+WHERE id = $1;
+
+-- name: VerifyEmailAlias :execrows
+-- verifies the user's email alias for an address they just verified, unless someone else holds it verified
+UPDATE aliases SET is_verified = true
+WHERE aliases.username = sqlc.arg(username) AND aliases.kind = 'email'
+  AND aliases.alias = lower(sqlc.arg(email)::text) AND NOT aliases.is_verified
+  AND NOT EXISTS (
+    SELECT 1 FROM aliases AS verified
+    WHERE verified.alias = aliases.alias AND verified.is_verified
+  );
+
+-- name: UnverifyEmailAlias :execrows
+-- releases the user's email alias for an address that is no longer theirs, so it stops resolving to them
+UPDATE aliases SET is_verified = false
+WHERE username = sqlc.arg(username) AND kind = 'email'
+  AND alias = lower(sqlc.arg(email)::text) AND is_verified;
@@ -0,0 +1,92 @@
+-- name: UpsertDailyRollup :exec
+INSERT INTO account_daily_rollups (
+  account_id, day, inflow, outflow, closing_balance, entry_count
+) VALUES (
+  sqlc.arg(account_id),
+  date_trunc('day', now() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC',
+  sqlc.arg(inflow),
+  sqlc.arg(outflow),
+  sqlc.arg(closing_balance),
+  1
+)
+ON CONFLICT (account_id, day) DO UPDATE
+SET inflow = account_daily_rollups.inflow + EXCLUDED.inflow,
+    outflow = account_daily_rollups.outflow + EXCLUDED.outflow,
+    closing_balance = EXCLUDED.closing_balance,
+    entry_count = account_daily_rollups.entry_count + 1;
+
+-- name: UpsertCounterpartyRollup :exec
+INSERT INTO counterparty_daily_rollups (
+  account_id, counterparty_account_id, day, received, sent, transfer_count
+) VALUES (
+  sqlc.arg(account_id),
+  sqlc.arg(counterparty_account_id),
+  date_trunc('day', now() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC',
+  sqlc.arg(received),
+  sqlc.arg(sent),
+  1
+)
+ON CONFLICT (account_id, day, counterparty_account_id) DO UPDATE
+SET received = counterparty_daily_rollups.received + EXCLUDED.received,
+    sent = counterparty_daily_rollups.sent + EXCLUDED.sent,
+    transfer_count = counterparty_daily_rollups.transfer_count + 1;
+
+-- name: GetAccountAnalytics :many
+-- every day from the account's opening up to today counts towards the average balance, a day without
+-- entries closing on the balance of the last day that had some, or before the first one, on its opening balance
+WITH days AS (
+  SELECT d.day
+  FROM generate_series(
+    date_trunc('day', sqlc.arg(start_time)::timestamptz, 'UTC'),
+    sqlc.arg(end_time)::timestamptz,
+    interval '1 day'
+  ) AS d(day)
+  JOIN accounts a ON a.id = sqlc.arg(account_id)
+  WHERE d.day < sqlc.arg(end_time)::timestamptz
+    AND d.day >= date_trunc('day', a.created_at, 'UTC')
+    AND d.day <= now()
+),
+daily AS (
+  SELECT
+    days.day,
+    COALESCE(r.inflow, 0) AS inflow,
+    COALESCE(r.outflow, 0) AS outflow,
+    COALESCE(r.entry_count, 0) AS entry_count,
+    COALESCE(
+      (SELECT p.closing_balance FROM account_daily_rollups p
+       WHERE p.account_id = sqlc.arg(account_id) AND p.day <= days.day
+       ORDER BY p.day DESC LIMIT 1),
+      (SELECT n.closing_balance - n.inflow + n.outflow FROM account_daily_rollups n
+       WHERE n.account_id = sqlc.arg(account_id) AND n.day > days.day
+       ORDER BY n.day LIMIT 1),
+      (SELECT balance FROM accounts WHERE id = sqlc.arg(account_id))
+    ) AS closing_balance
+  FROM days
+  LEFT JOIN account_daily_rollups r ON r.account_id = sqlc.arg(account_id) AND r.day = days.day
+)
+SELECT
+  date_trunc(sqlc.arg(interval)::text, day, 'UTC')::timestamptz AS period,
+  SUM(inflow)::float8 AS inflow,
+  SUM(outflow)::float8 AS outflow,
+  SUM(inflow - outflow)::float8 AS net,
+  AVG(closing_balance)::float8 AS average_balance,
+  SUM(entry_count)::bigint AS entry_count
+FROM daily
+GROUP BY period
+ORDER BY period;
+
+-- name: ListTopCounterparties :many
+SELECT
+  u.full_name AS counterparty_name,
+  SUM(r.received)::float8 AS received,
+  SUM(r.sent)::float8 AS sent,
+  SUM(r.transfer_count)::bigint AS transfer_count
+FROM counterparty_daily_rollups r
+JOIN accounts a ON a.id = r.counterparty_account_id
+JOIN users u ON u.username = a.owner
+WHERE r.account_id = sqlc.arg(account_id)
+  AND r.day >= date_trunc('day', sqlc.arg(start_time)::timestamptz, 'UTC')
+  AND r.day < sqlc.arg(end_time)
+GROUP BY a.owner, u.full_name
+ORDER BY SUM(r.received + r.sent) DESC
+LIMIT sqlc.arg('limit');
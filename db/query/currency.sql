@@ -0,0 +1,32 @@
+-- name: CreateCurrency :one
+INSERT INTO currencies (
+  code, exponent, symbol, enabled, min_transfer_amount, max_transfer_amount, payee_cooldown_limit
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: GetCurrency :one
+SELECT * FROM currencies
+WHERE code = $1 LIMIT 1;
+
+-- name: ListCurrencies :many
+SELECT * FROM currencies
+ORDER BY code;
+
+-- name: ListEnabledCurrencies :many
+SELECT * FROM currencies
+WHERE enabled = true
+ORDER BY code;
+
+-- name: UpdateCurrency :one
+UPDATE currencies
+SET
+  symbol = COALESCE(sqlc.narg(symbol), symbol),
+  enabled = COALESCE(sqlc.narg(enabled), enabled),
+  min_transfer_amount = COALESCE(sqlc.narg(min_transfer_amount), min_transfer_amount),
+  max_transfer_amount = COALESCE(sqlc.narg(max_transfer_amount), max_transfer_amount),
+  payee_cooldown_limit = COALESCE(sqlc.narg(payee_cooldown_limit), payee_cooldown_limit),
+  updated_at = now()
+WHERE code = sqlc.arg(code)
+RETURNING *;
@@ -8,8 +8,34 @@ ORDER BY created_at DESC;
 
 -- name: CreateEntry :one
 INSERT INTO entries (
-  account_id, amount
+  account_id, amount, transfer_id
 ) VALUES (
-  $1, $2
+  $1, $2, $3
 )
 RETURNING *;
+
+-- name: ListAccountEntries :many
+SELECT e.*,
+  COALESCE(t.memo, '')::varchar AS memo,
+  COALESCE(t.reference, '')::varchar AS reference,
+  COALESCE(t.end_to_end_id, '')::varchar AS end_to_end_id
+FROM entries e
+LEFT JOIN transfer t ON t.id = e.transfer_id
+WHERE e.account_id = sqlc.arg(account_id) AND e.id < sqlc.arg(cursor)
+ORDER BY e.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListAccountEntriesReverse :many
+SELECT e.*,
+  COALESCE(t.memo, '')::varchar AS memo,
+  COALESCE(t.reference, '')::varchar AS reference,
+  COALESCE(t.end_to_end_id, '')::varchar AS end_to_end_id
+FROM entries e
+LEFT JOIN transfer t ON t.id = e.transfer_id
+WHERE e.account_id = sqlc.arg(account_id) AND e.id > sqlc.arg(cursor)
+ORDER BY e.id
+LIMIT sqlc.arg('limit');
+
+-- name: CountAccountEntries :one
+SELECT COUNT(*) FROM entries
+WHERE account_id = $1;
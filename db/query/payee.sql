@@ -0,0 +1,42 @@
+-- name: CreatePayee :one
+INSERT INTO payees (
+  owner, nickname, account_id, display_name
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetPayee :one
+SELECT * FROM payees
+WHERE id = $1 LIMIT 1;
+
+-- name: ListPayees :many
+SELECT * FROM payees
+WHERE owner = $1
+ORDER BY id
+LIMIT $2 OFFSET $3;
+
+-- name: CountPayees :one
+SELECT COUNT(*) FROM payees
+WHERE owner = $1;
+
+-- name: UpdatePayee :one
+UPDATE payees
+SET nickname = $1
+WHERE id = $2
+RETURNING *;
+
+-- name: DeletePayee :exec
+DELETE FROM payees
+WHERE id = $1;
+
+
+-- name: IsEstablishedPayee :one
+-- whether the owner of the sending account saved the receiving account as a payee at least cooldown_seconds ago
+SELECT EXISTS (
+  SELECT 1 FROM payees
+  JOIN accounts ON accounts.owner = payees.owner
+  WHERE accounts.id = sqlc.arg(from_account_id)
+    AND payees.account_id = sqlc.arg(to_account_id)
+    AND payees.created_at <= now() - make_interval(secs => sqlc.arg(cooldown_seconds)::float8)
+);
@@ -0,0 +1,13 @@
+-- name: ListUnbalancedAccounts :many
+-- accounts whose balance differs from the sum of their ledger entries by at least a minor unit
+SELECT
+  a.id,
+  a.owner,
+  a.currency,
+  a.balance,
+  COALESCE(SUM(e.amount), 0)::float8 AS entries_total
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+GROUP BY a.id
+HAVING abs(a.balance - COALESCE(SUM(e.amount), 0)) >= 0.005
+ORDER BY a.id;
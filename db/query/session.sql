@@ -13,4 +13,36 @@ INSERT INTO sessions (
 
 -- name: GetSession :one
 SELECT * FROM sessions
-WHERE id = $1 LIMIT 1;
\ No newline at end of file
+WHERE id = $1 LIMIT 1;
+
+-- name: GetAuthSession :one
+SELECT sqlc.embed(sessions), users.password_changed_at
+FROM sessions
+JOIN users ON users.username = sessions.username
+WHERE sessions.id = $1 LIMIT 1;
+
+-- name: ListSessions :many
+SELECT * FROM sessions
+WHERE username = sqlc.arg(username) AND id < sqlc.arg(cursor)
+ORDER BY id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListSessionsReverse :many
+SELECT * FROM sessions
+WHERE username = sqlc.arg(username) AND id > sqlc.arg(cursor)
+ORDER BY id
+LIMIT sqlc.arg('limit');
+
+-- name: CountSessions :one
+SELECT COUNT(*) FROM sessions
+WHERE username = $1;
+-- name: BlockSession :one
+UPDATE sessions
+SET is_blocked = true
+WHERE id = $1
+RETURNING *;
+
+-- name: BlockUserSessions :execrows
+UPDATE sessions
+SET is_blocked = true
+WHERE username = $1 AND is_blocked = false;
@@ -8,8 +8,61 @@ ORDER BY created_at DESC;
 
 -- name: CreateTransfer :one
 INSERT INTO transfer (
-  from_account_id, to_account_id, amount
+  from_account_id, to_account_id, amount, memo, reference, end_to_end_id
 ) VALUES (
-  $1, $2 , $3
+  $1, $2 , $3, $4, $5, $6
 )
-RETURNING *;
\ No newline at end of file
+RETURNING *;
+
+-- name: ListOwnerTransfers :many
+SELECT t.* FROM transfer t
+WHERE (
+  t.from_account_id IN (SELECT a.id FROM accounts a WHERE a.owner = sqlc.arg(owner))
+  OR t.to_account_id IN (SELECT a.id FROM accounts a WHERE a.owner = sqlc.arg(owner))
+)
+AND t.id < sqlc.arg(cursor)
+ORDER BY t.id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListOwnerTransfersReverse :many
+SELECT t.* FROM transfer t
+WHERE (
+  t.from_account_id IN (SELECT a.id FROM accounts a WHERE a.owner = sqlc.arg(owner))
+  OR t.to_account_id IN (SELECT a.id FROM accounts a WHERE a.owner = sqlc.arg(owner))
+)
+AND t.id > sqlc.arg(cursor)
+ORDER BY t.id
+LIMIT sqlc.arg('limit');
+
+-- name: CountOwnerTransfers :one
+SELECT COUNT(*) FROM transfer t
+WHERE t.from_account_id IN (SELECT a.id FROM accounts a WHERE a.owner = sqlc.arg(owner))
+  OR t.to_account_id IN (SELECT a.id FROM accounts a WHERE a.owner = sqlc.arg(owner));
+
+-- name: SearchTransfers :many
+SELECT t.*,
+  ts_rank(
+    to_tsvector('simple', t.memo || ' ' || t.reference || ' ' || t.end_to_end_id),
+    plainto_tsquery('simple', sqlc.arg(query))
+  )::float8 AS rank
+FROM transfer t
+WHERE (
+  t.from_account_id IN (SELECT a.id FROM accounts a WHERE a.owner = sqlc.arg(owner))
+  OR t.to_account_id IN (SELECT a.id FROM accounts a WHERE a.owner = sqlc.arg(owner))
+)
+AND to_tsvector('simple', t.memo || ' ' || t.reference || ' ' || t.end_to_end_id) @@ plainto_tsquery('simple', sqlc.arg(query))
+ORDER BY rank DESC, t.id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountSearchTransfers :one
+SELECT COUNT(*) FROM transfer t
+WHERE (
+  t.from_account_id IN (SELECT a.id FROM accounts a WHERE a.owner = sqlc.arg(owner))
+  OR t.to_account_id IN (SELECT a.id FROM accounts a WHERE a.owner = sqlc.arg(owner))
+)
+AND to_tsvector('simple', t.memo || ' ' || t.reference || ' ' || t.end_to_end_id) @@ plainto_tsquery('simple', sqlc.arg(query));
+
+-- name: GetTransferByEndToEndID :one
+SELECT * FROM transfer
+WHERE from_account_id = $1 AND end_to_end_id = $2
+LIMIT 1;
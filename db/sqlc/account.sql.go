@@ -36,10 +36,11 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 
 const countAccounts = `-- name: CountAccounts :one
 SELECT COUNT(*) FROM accounts
+WHERE owner = $1
 `
 
-func (q *Queries) CountAccounts(ctx context.Context) (int64, error) {
-	row := q.db.QueryRow(ctx, countAccounts)
+func (q *Queries) CountAccounts(ctx context.Context, owner string) (int64, error) {
+	row := q.db.QueryRow(ctx, countAccounts, owner)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -73,6 +74,32 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 	return i, err
 }
 
+const debitAccountBalance = `-- name: DebitAccountBalance :one
+UPDATE accounts
+SET balance = balance - $1
+WHERE id = $2 AND balance >= $1
+RETURNING id, owner, balance, currency, created_at
+`
+
+type DebitAccountBalanceParams struct {
+	Amount float64 `json:"amount"`
+	ID     int64   `json:"id"`
+}
+
+// the balance is checked under the row lock the update takes, so concurrent debits can't overdraw the account
+func (q *Queries) DebitAccountBalance(ctx context.Context, arg DebitAccountBalanceParams) (Account, error) {
+	row := q.db.QueryRow(ctx, debitAccountBalance, arg.Amount, arg.ID)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const deleteAccount = `-- name: DeleteAccount :exec
 DELETE FROM accounts
 WHERE id = $1
@@ -101,6 +128,30 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 	return i, err
 }
 
+const getAccountByOwnerAndCurrency = `-- name: GetAccountByOwnerAndCurrency :one
+SELECT id, owner, balance, currency, created_at FROM accounts
+WHERE owner = $1 AND currency = $2
+LIMIT 1
+`
+
+type GetAccountByOwnerAndCurrencyParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+}
+
+func (q *Queries) GetAccountByOwnerAndCurrency(ctx context.Context, arg GetAccountByOwnerAndCurrencyParams) (Account, error) {
+	row := q.db.QueryRow(ctx, getAccountByOwnerAndCurrency, arg.Owner, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
 SELECT id, owner, balance, currency, created_at FROM accounts
 WHERE id = $1 LIMIT 1
@@ -122,19 +173,58 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 
 const listAccounts = `-- name: ListAccounts :many
 SELECT id, owner, balance, currency, created_at FROM accounts
-WHERE owner = $1
+WHERE owner = $1 AND id > $2
 ORDER BY id
-LIMIT $2 OFFSET $3
+LIMIT $3
 `
 
 type ListAccountsParams struct {
 	Owner  string `json:"owner"`
+	Cursor int64  `json:"cursor"`
 	Limit  int32  `json:"limit"`
-	Offset int32  `json:"offset"`
 }
 
 func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
-	rows, err := q.db.Query(ctx, listAccounts, arg.Owner, arg.Limit, arg.Offset)
+	rows, err := q.db.Query(ctx, listAccounts, arg.Owner, arg.Cursor, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountsReverse = `-- name: ListAccountsReverse :many
+SELECT id, owner, balance, currency, created_at FROM accounts
+WHERE owner = $1 AND id < $2
+ORDER BY id DESC
+LIMIT $3
+`
+
+type ListAccountsReverseParams struct {
+	Owner  string `json:"owner"`
+	Cursor int64  `json:"cursor"`
+	Limit  int32  `json:"limit"`
+}
+
+func (q *Queries) ListAccountsReverse(ctx context.Context, arg ListAccountsReverseParams) ([]Account, error) {
+	rows, err := q.db.Query(ctx, listAccountsReverse, arg.Owner, arg.Cursor, arg.Limit)
 	if err != nil {
 		return nil, err
 	}
@@ -33,9 +33,10 @@ func createRandomUser(t *testing.T) User {
 // instead of typing the code for creating account in each test all the time, i can just call this
 func createRandomAccount(t *testing.T) Account {
 	user := createRandomUser(t)
+	// enough balance for the few transfers a test makes out of it
 	arg := CreateAccountParams{
 		Owner:    user.Username,
-		Balance:  util.RandomMoney(),
+		Balance:  100 + util.RandomMoney(),
 		Currency: util.GenerateRandomCurrency(),
 	}
 
@@ -135,8 +136,8 @@ func TestListAccounts(t *testing.T) {
 
 	arg := ListAccountsParams{
 		Owner:  lastAccount.Owner,
+		Cursor: 0,
 		Limit:  5,
-		Offset: 0,
 	}
 
 	accounts, err := testStore.ListAccounts(context.Background(), arg)
@@ -149,3 +150,39 @@ func TestListAccounts(t *testing.T) {
 	}
 
 }
+
+func TestListAccountsCursor(t *testing.T) {
+	user := createRandomUser(t)
+	var created []Account
+	for _, currency := range []string{"USD", "EUR", "CAD"} {
+		account, err := testStore.CreateAccount(context.Background(), CreateAccountParams{
+			Owner:    user.Username,
+			Balance:  0,
+			Currency: currency,
+		})
+		require.NoError(t, err)
+		created = append(created, account)
+	}
+
+	// forward from the first account skips it
+	accounts, err := testStore.ListAccounts(context.Background(), ListAccountsParams{
+		Owner:  user.Username,
+		Cursor: created[0].ID,
+		Limit:  5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, created[1:], accounts)
+
+	// backward from the last account returns the closest one first
+	accounts, err = testStore.ListAccountsReverse(context.Background(), ListAccountsReverseParams{
+		Owner:  user.Username,
+		Cursor: created[2].ID,
+		Limit:  5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, []Account{created[1], created[0]}, accounts)
+
+	total, err := testStore.CountAccounts(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), total)
+}
@@ -0,0 +1,171 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: alias.sql
+
+package db
+
+import (
+	"context"
+)
+
+const createAlias = `-- name: CreateAlias :one
+INSERT INTO aliases (
+  username, alias, kind, is_verified
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING id, username, alias, kind, is_verified, created_at
+`
+
+type CreateAliasParams struct {
+	Username   string `json:"username"`
+	Alias      string `json:"alias"`
+	Kind       string `json:"kind"`
+	IsVerified bool   `json:"is_verified"`
+}
+
+func (q *Queries) CreateAlias(ctx context.Context, arg CreateAliasParams) (Alias, error) {
+	row := q.db.QueryRow(ctx, createAlias,
+		arg.Username,
+		arg.Alias,
+		arg.Kind,
+		arg.IsVerified,
+	)
+	var i Alias
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Alias,
+		&i.Kind,
+		&i.IsVerified,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteAlias = `-- name: DeleteAlias :exec
+DELETE FROM aliases
+WHERE id = $1
+`
+
+func (q *Queries) DeleteAlias(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, deleteAlias, id)
+	return err
+}
+
+const getAlias = `-- name: GetAlias :one
+SELECT id, username, alias, kind, is_verified, created_at FROM aliases
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetAlias(ctx context.Context, id int64) (Alias, error) {
+	row := q.db.QueryRow(ctx, getAlias, id)
+	var i Alias
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Alias,
+		&i.Kind,
+		&i.IsVerified,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getVerifiedAlias = `-- name: GetVerifiedAlias :one
+SELECT id, username, alias, kind, is_verified, created_at FROM aliases
+WHERE alias = $1 AND is_verified = true
+LIMIT 1
+`
+
+func (q *Queries) GetVerifiedAlias(ctx context.Context, alias string) (Alias, error) {
+	row := q.db.QueryRow(ctx, getVerifiedAlias, alias)
+	var i Alias
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Alias,
+		&i.Kind,
+		&i.IsVerified,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const listAliases = `-- name: ListAliases :many
+SELECT id, username, alias, kind, is_verified, created_at FROM aliases
+WHERE username = $1
+ORDER BY id
+`
+
+func (q *Queries) ListAliases(ctx context.Context, username string) ([]Alias, error) {
+	rows, err := q.db.Query(ctx, listAliases, username)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Alias{}
+	for rows.Next() {
+		var i Alias
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.Alias,
+			&i.Kind,
+			&i.IsVerified,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const unverifyEmailAlias = `-- name: UnverifyEmailAlias :execrows
+UPDATE aliases SET is_verified = false
+WHERE username = $1 AND kind = 'email'
+  AND alias = lower($2::text) AND is_verified
+`
+
+type UnverifyEmailAliasParams struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// releases the user's email alias for an address that is no longer theirs, so it stops resolving to them
+func (q *Queries) UnverifyEmailAlias(ctx context.Context, arg UnverifyEmailAliasParams) (int64, error) {
+	result, err := q.db.Exec(ctx, unverifyEmailAlias, arg.Username, arg.Email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const verifyEmailAlias = `-- name: VerifyEmailAlias :execrows
+UPDATE aliases SET is_verified = true
+WHERE aliases.username = $1 AND aliases.kind = 'email'
+  AND aliases.alias = lower($2::text) AND NOT aliases.is_verified
+  AND NOT EXISTS (
+    SELECT 1 FROM aliases AS verified
+    WHERE verified.alias = aliases.alias AND verified.is_verified
+  )
+`
+
+type VerifyEmailAliasParams struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// verifies the user's email alias for an address they just verified, unless someone else holds it verified
+func (q *Queries) VerifyEmailAlias(ctx context.Context, arg VerifyEmailAliasParams) (int64, error) {
+	result, err := q.db.Exec(ctx, verifyEmailAlias, arg.Username, arg.Email)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
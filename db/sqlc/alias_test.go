@@ -0,0 +1,104 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAlias(t *testing.T, user User, verified bool) Alias {
+	arg := CreateAliasParams{
+		Username:   user.Username,
+		Alias:      "@" + strings.ToLower(util.GenerateRandomString(12)),
+		Kind:       util.AliasTag,
+		IsVerified: verified,
+	}
+
+	alias, err := testStore.CreateAlias(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.NotZero(t, alias.ID)
+	require.Equal(t, arg.Username, alias.Username)
+	require.Equal(t, arg.Alias, alias.Alias)
+	require.Equal(t, arg.Kind, alias.Kind)
+	require.Equal(t, arg.IsVerified, alias.IsVerified)
+	require.NotZero(t, alias.CreatedAt)
+
+	return alias
+}
+
+func TestCreateAlias(t *testing.T) {
+	createRandomAlias(t, createRandomUser(t), true)
+}
+
+func TestGetVerifiedAlias(t *testing.T) {
+	user := createRandomUser(t)
+	verified := createRandomAlias(t, user, true)
+	unverified := createRandomAlias(t, user, false)
+
+	alias, err := testStore.GetVerifiedAlias(context.Background(), verified.Alias)
+	require.NoError(t, err)
+	require.Equal(t, verified.ID, alias.ID)
+
+	_, err = testStore.GetVerifiedAlias(context.Background(), unverified.Alias)
+	require.EqualError(t, err, ErrRecordNotFound.Error())
+}
+
+func TestOnlyVerifiedAliasesAreReserved(t *testing.T) {
+	claimed := createRandomAlias(t, createRandomUser(t), false)
+	arg := CreateAliasParams{
+		Username:   createRandomUser(t).Username,
+		Alias:      claimed.Alias,
+		Kind:       claimed.Kind,
+		IsVerified: true,
+	}
+
+	// an unverified claim doesn't keep the real owner from verifying it
+	owned, err := testStore.CreateAlias(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, owned.IsVerified)
+
+	// but once verified, nobody else can verify it too
+	arg.Username = createRandomUser(t).Username
+	_, err = testStore.CreateAlias(context.Background(), arg)
+	require.Equal(t, UniqueViolation, ErrorCode(err))
+}
+
+func TestListAndDeleteAliases(t *testing.T) {
+	user := createRandomUser(t)
+	alias1 := createRandomAlias(t, user, true)
+	createRandomAlias(t, user, false)
+
+	aliases, err := testStore.ListAliases(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, aliases, 2)
+
+	err = testStore.DeleteAlias(context.Background(), alias1.ID)
+	require.NoError(t, err)
+
+	_, err = testStore.GetAlias(context.Background(), alias1.ID)
+	require.EqualError(t, err, ErrRecordNotFound.Error())
+}
+
+func TestResolveAlias(t *testing.T) {
+	account := createRandomAccount(t)
+	owner, err := testStore.GetUser(context.Background(), account.Owner)
+	require.NoError(t, err)
+	alias := createRandomAlias(t, owner, true)
+
+	result, err := testStore.ResolveAlias(context.Background(), alias.Alias, account.Currency)
+	require.NoError(t, err)
+	require.Equal(t, account.ID, result.Account.ID)
+	require.Equal(t, owner.FullName, result.Owner.FullName)
+
+	// usernames resolve without being registered
+	result, err = testStore.ResolveAlias(context.Background(), owner.Username, account.Currency)
+	require.NoError(t, err)
+	require.Equal(t, account.ID, result.Account.ID)
+
+	_, err = testStore.ResolveAlias(context.Background(), "@"+strings.ToLower(util.GenerateRandomString(12)), account.Currency)
+	require.EqualError(t, err, ErrRecordNotFound.Error())
+}
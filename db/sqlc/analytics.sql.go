@@ -0,0 +1,234 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: analytics.sql
+
+package db
+
+import (
+	"context"
+	"time"
+)
+
+const getAccountAnalytics = `-- name: GetAccountAnalytics :many
+WITH days AS (
+  SELECT d.day
+  FROM generate_series(
+    date_trunc('day', $2::timestamptz, 'UTC'),
+    $3::timestamptz,
+    interval '1 day'
+  ) AS d(day)
+  JOIN accounts a ON a.id = $4
+  WHERE d.day < $3::timestamptz
+    AND d.day >= date_trunc('day', a.created_at, 'UTC')
+    AND d.day <= now()
+),
+daily AS (
+  SELECT
+    days.day,
+    COALESCE(r.inflow, 0) AS inflow,
+    COALESCE(r.outflow, 0) AS outflow,
+    COALESCE(r.entry_count, 0) AS entry_count,
+    COALESCE(
+      (SELECT p.closing_balance FROM account_daily_rollups p
+       WHERE p.account_id = $4 AND p.day <= days.day
+       ORDER BY p.day DESC LIMIT 1),
+      (SELECT n.closing_balance - n.inflow + n.outflow FROM account_daily_rollups n
+       WHERE n.account_id = $4 AND n.day > days.day
+       ORDER BY n.day LIMIT 1),
+      (SELECT balance FROM accounts WHERE id = $4)
+    ) AS closing_balance
+  FROM days
+  LEFT JOIN account_daily_rollups r ON r.account_id = $4 AND r.day = days.day
+)
+SELECT
+  date_trunc($1::text, day, 'UTC')::timestamptz AS period,
+  SUM(inflow)::float8 AS inflow,
+  SUM(outflow)::float8 AS outflow,
+  SUM(inflow - outflow)::float8 AS net,
+  AVG(closing_balance)::float8 AS average_balance,
+  SUM(entry_count)::bigint AS entry_count
+FROM daily
+GROUP BY period
+ORDER BY period
+`
+
+type GetAccountAnalyticsParams struct {
+	Interval  string    `json:"interval"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	AccountID int64     `json:"account_id"`
+}
+
+type GetAccountAnalyticsRow struct {
+	Period         time.Time `json:"period"`
+	Inflow         float64   `json:"inflow"`
+	Outflow        float64   `json:"outflow"`
+	Net            float64   `json:"net"`
+	AverageBalance float64   `json:"average_balance"`
+	EntryCount     int64     `json:"entry_count"`
+}
+
+// every day from the account's opening up to today counts towards the average balance, a day without
+// entries closing on the balance of the last day that had some, or before the first one, on its opening balance
+func (q *Queries) GetAccountAnalytics(ctx context.Context, arg GetAccountAnalyticsParams) ([]GetAccountAnalyticsRow, error) {
+	rows, err := q.db.Query(ctx, getAccountAnalytics,
+		arg.Interval,
+		arg.StartTime,
+		arg.EndTime,
+		arg.AccountID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetAccountAnalyticsRow{}
+	for rows.Next() {
+		var i GetAccountAnalyticsRow
+		if err := rows.Scan(
+			&i.Period,
+			&i.Inflow,
+			&i.Outflow,
+			&i.Net,
+			&i.AverageBalance,
+			&i.EntryCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTopCounterparties = `-- name: ListTopCounterparties :many
+SELECT
+  u.full_name AS counterparty_name,
+  SUM(r.received)::float8 AS received,
+  SUM(r.sent)::float8 AS sent,
+  SUM(r.transfer_count)::bigint AS transfer_count
+FROM counterparty_daily_rollups r
+JOIN accounts a ON a.id = r.counterparty_account_id
+JOIN users u ON u.username = a.owner
+WHERE r.account_id = $1
+  AND r.day >= date_trunc('day', $2::timestamptz, 'UTC')
+  AND r.day < $3
+GROUP BY a.owner, u.full_name
+ORDER BY SUM(r.received + r.sent) DESC
+LIMIT $4
+`
+
+type ListTopCounterpartiesParams struct {
+	AccountID int64     `json:"account_id"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Limit     int32     `json:"limit"`
+}
+
+type ListTopCounterpartiesRow struct {
+	CounterpartyName string  `json:"counterparty_name"`
+	Received         float64 `json:"received"`
+	Sent             float64 `json:"sent"`
+	TransferCount    int64   `json:"transfer_count"`
+}
+
+func (q *Queries) ListTopCounterparties(ctx context.Context, arg ListTopCounterpartiesParams) ([]ListTopCounterpartiesRow, error) {
+	rows, err := q.db.Query(ctx, listTopCounterparties,
+		arg.AccountID,
+		arg.StartTime,
+		arg.EndTime,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListTopCounterpartiesRow{}
+	for rows.Next() {
+		var i ListTopCounterpartiesRow
+		if err := rows.Scan(
+			&i.CounterpartyName,
+			&i.Received,
+			&i.Sent,
+			&i.TransferCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const upsertCounterpartyRollup = `-- name: UpsertCounterpartyRollup :exec
+INSERT INTO counterparty_daily_rollups (
+  account_id, counterparty_account_id, day, received, sent, transfer_count
+) VALUES (
+  $1,
+  $2,
+  date_trunc('day', now() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC',
+  $3,
+  $4,
+  1
+)
+ON CONFLICT (account_id, day, counterparty_account_id) DO UPDATE
+SET received = counterparty_daily_rollups.received + EXCLUDED.received,
+    sent = counterparty_daily_rollups.sent + EXCLUDED.sent,
+    transfer_count = counterparty_daily_rollups.transfer_count + 1
+`
+
+type UpsertCounterpartyRollupParams struct {
+	AccountID             int64   `json:"account_id"`
+	CounterpartyAccountID int64   `json:"counterparty_account_id"`
+	Received              float64 `json:"received"`
+	Sent                  float64 `json:"sent"`
+}
+
+func (q *Queries) UpsertCounterpartyRollup(ctx context.Context, arg UpsertCounterpartyRollupParams) error {
+	_, err := q.db.Exec(ctx, upsertCounterpartyRollup,
+		arg.AccountID,
+		arg.CounterpartyAccountID,
+		arg.Received,
+		arg.Sent,
+	)
+	return err
+}
+
+const upsertDailyRollup = `-- name: UpsertDailyRollup :exec
+INSERT INTO account_daily_rollups (
+  account_id, day, inflow, outflow, closing_balance, entry_count
+) VALUES (
+  $1,
+  date_trunc('day', now() AT TIME ZONE 'UTC') AT TIME ZONE 'UTC',
+  $2,
+  $3,
+  $4,
+  1
+)
+ON CONFLICT (account_id, day) DO UPDATE
+SET inflow = account_daily_rollups.inflow + EXCLUDED.inflow,
+    outflow = account_daily_rollups.outflow + EXCLUDED.outflow,
+    closing_balance = EXCLUDED.closing_balance,
+    entry_count = account_daily_rollups.entry_count + 1
+`
+
+type UpsertDailyRollupParams struct {
+	AccountID      int64   `json:"account_id"`
+	Inflow         float64 `json:"inflow"`
+	Outflow        float64 `json:"outflow"`
+	ClosingBalance float64 `json:"closing_balance"`
+}
+
+func (q *Queries) UpsertDailyRollup(ctx context.Context, arg UpsertDailyRollupParams) error {
+	_, err := q.db.Exec(ctx, upsertDailyRollup,
+		arg.AccountID,
+		arg.Inflow,
+		arg.Outflow,
+		arg.ClosingBalance,
+	)
+	return err
+}
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccountAnalytics(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	for _, amount := range []float64{10, 20} {
+		_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        amount,
+		})
+		require.NoError(t, err)
+	}
+
+	now := time.Now()
+	arg := GetAccountAnalyticsParams{
+		Interval:  "day",
+		AccountID: account1.ID,
+		StartTime: now.Add(-48 * time.Hour),
+		EndTime:   now.Add(24 * time.Hour),
+	}
+
+	periods, err := testStore.GetAccountAnalytics(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, periods, 1)
+	require.Equal(t, float64(0), periods[0].Inflow)
+	require.Equal(t, float64(30), periods[0].Outflow)
+	require.Equal(t, float64(-30), periods[0].Net)
+	require.InDelta(t, account1.Balance-30, periods[0].AverageBalance, 0.001)
+	require.Equal(t, int64(2), periods[0].EntryCount)
+
+	arg.AccountID = account2.ID
+	periods, err = testStore.GetAccountAnalytics(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, periods, 1)
+	require.Equal(t, float64(30), periods[0].Inflow)
+
+	counterparties, err := testStore.ListTopCounterparties(context.Background(), ListTopCounterpartiesParams{
+		AccountID: account1.ID,
+		StartTime: arg.StartTime,
+		EndTime:   arg.EndTime,
+		Limit:     5,
+	})
+	require.NoError(t, err)
+	require.Len(t, counterparties, 1)
+	owner2, err := testStore.GetUser(context.Background(), account2.Owner)
+	require.NoError(t, err)
+	require.Equal(t, owner2.FullName, counterparties[0].CounterpartyName)
+	require.Equal(t, float64(30), counterparties[0].Sent)
+	require.Equal(t, float64(0), counterparties[0].Received)
+	require.Equal(t, int64(2), counterparties[0].TransferCount)
+}
+
+func TestAccountAnalyticsCarriesBalanceForward(t *testing.T) {
+	account := createRandomAccount(t)
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+
+	// opened three days ago, with entries on the first day and yesterday only
+	_, err := testPool.Exec(context.Background(), `UPDATE accounts SET created_at = $2 WHERE id = $1`, account.ID, today.AddDate(0, 0, -3))
+	require.NoError(t, err)
+	_, err = testPool.Exec(context.Background(), `
+		INSERT INTO account_daily_rollups (account_id, day, inflow, outflow, closing_balance, entry_count)
+		VALUES ($1, $2, 100, 0, 100, 1), ($1, $3, 100, 0, 200, 1)`,
+		account.ID, today.AddDate(0, 0, -3), today.AddDate(0, 0, -1))
+	require.NoError(t, err)
+
+	arg := GetAccountAnalyticsParams{
+		Interval:  "day",
+		AccountID: account.ID,
+		StartTime: today.AddDate(0, 0, -10),
+		EndTime:   today.AddDate(0, 0, 5),
+	}
+	periods, err := testStore.GetAccountAnalytics(context.Background(), arg)
+	require.NoError(t, err)
+
+	// nothing before the account was opened or after today
+	require.Len(t, periods, 4)
+	require.Equal(t, []float64{100, 100, 200, 200}, []float64{
+		periods[0].AverageBalance, periods[1].AverageBalance, periods[2].AverageBalance, periods[3].AverageBalance,
+	})
+	require.Zero(t, periods[1].Inflow)
+	require.Zero(t, periods[1].EntryCount)
+}
@@ -0,0 +1,195 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: currency.sql
+
+package db
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const createCurrency = `-- name: CreateCurrency :one
+INSERT INTO currencies (
+  code, exponent, symbol, enabled, min_transfer_amount, max_transfer_amount, payee_cooldown_limit
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING code, exponent, symbol, enabled, min_transfer_amount, max_transfer_amount, created_at, updated_at, payee_cooldown_limit
+`
+
+type CreateCurrencyParams struct {
+	Code               string  `json:"code"`
+	Exponent           int16   `json:"exponent"`
+	Symbol             string  `json:"symbol"`
+	Enabled            bool    `json:"enabled"`
+	MinTransferAmount  float64 `json:"min_transfer_amount"`
+	MaxTransferAmount  float64 `json:"max_transfer_amount"`
+	PayeeCooldownLimit float64 `json:"payee_cooldown_limit"`
+}
+
+func (q *Queries) CreateCurrency(ctx context.Context, arg CreateCurrencyParams) (Currency, error) {
+	row := q.db.QueryRow(ctx, createCurrency,
+		arg.Code,
+		arg.Exponent,
+		arg.Symbol,
+		arg.Enabled,
+		arg.MinTransferAmount,
+		arg.MaxTransferAmount,
+		arg.PayeeCooldownLimit,
+	)
+	var i Currency
+	err := row.Scan(
+		&i.Code,
+		&i.Exponent,
+		&i.Symbol,
+		&i.Enabled,
+		&i.MinTransferAmount,
+		&i.MaxTransferAmount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PayeeCooldownLimit,
+	)
+	return i, err
+}
+
+const getCurrency = `-- name: GetCurrency :one
+SELECT code, exponent, symbol, enabled, min_transfer_amount, max_transfer_amount, created_at, updated_at, payee_cooldown_limit FROM currencies
+WHERE code = $1 LIMIT 1
+`
+
+func (q *Queries) GetCurrency(ctx context.Context, code string) (Currency, error) {
+	row := q.db.QueryRow(ctx, getCurrency, code)
+	var i Currency
+	err := row.Scan(
+		&i.Code,
+		&i.Exponent,
+		&i.Symbol,
+		&i.Enabled,
+		&i.MinTransferAmount,
+		&i.MaxTransferAmount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PayeeCooldownLimit,
+	)
+	return i, err
+}
+
+const listCurrencies = `-- name: ListCurrencies :many
+SELECT code, exponent, symbol, enabled, min_transfer_amount, max_transfer_amount, created_at, updated_at, payee_cooldown_limit FROM currencies
+ORDER BY code
+`
+
+func (q *Queries) ListCurrencies(ctx context.Context) ([]Currency, error) {
+	rows, err := q.db.Query(ctx, listCurrencies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Currency{}
+	for rows.Next() {
+		var i Currency
+		if err := rows.Scan(
+			&i.Code,
+			&i.Exponent,
+			&i.Symbol,
+			&i.Enabled,
+			&i.MinTransferAmount,
+			&i.MaxTransferAmount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.PayeeCooldownLimit,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEnabledCurrencies = `-- name: ListEnabledCurrencies :many
+SELECT code, exponent, symbol, enabled, min_transfer_amount, max_transfer_amount, created_at, updated_at, payee_cooldown_limit FROM currencies
+WHERE enabled = true
+ORDER BY code
+`
+
+func (q *Queries) ListEnabledCurrencies(ctx context.Context) ([]Currency, error) {
+	rows, err := q.db.Query(ctx, listEnabledCurrencies)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Currency{}
+	for rows.Next() {
+		var i Currency
+		if err := rows.Scan(
+			&i.Code,
+			&i.Exponent,
+			&i.Symbol,
+			&i.Enabled,
+			&i.MinTransferAmount,
+			&i.MaxTransferAmount,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.PayeeCooldownLimit,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updateCurrency = `-- name: UpdateCurrency :one
+UPDATE currencies
+SET
+  symbol = COALESCE($1, symbol),
+  enabled = COALESCE($2, enabled),
+  min_transfer_amount = COALESCE($3, min_transfer_amount),
+  max_transfer_amount = COALESCE($4, max_transfer_amount),
+  payee_cooldown_limit = COALESCE($5, payee_cooldown_limit),
+  updated_at = now()
+WHERE code = $6
+RETURNING code, exponent, symbol, enabled, min_transfer_amount, max_transfer_amount, created_at, updated_at, payee_cooldown_limit
+`
+
+type UpdateCurrencyParams struct {
+	Symbol             pgtype.Text   `json:"symbol"`
+	Enabled            pgtype.Bool   `json:"enabled"`
+	MinTransferAmount  pgtype.Float8 `json:"min_transfer_amount"`
+	MaxTransferAmount  pgtype.Float8 `json:"max_transfer_amount"`
+	PayeeCooldownLimit pgtype.Float8 `json:"payee_cooldown_limit"`
+	Code               string        `json:"code"`
+}
+
+func (q *Queries) UpdateCurrency(ctx context.Context, arg UpdateCurrencyParams) (Currency, error) {
+	row := q.db.QueryRow(ctx, updateCurrency,
+		arg.Symbol,
+		arg.Enabled,
+		arg.MinTransferAmount,
+		arg.MaxTransferAmount,
+		arg.PayeeCooldownLimit,
+		arg.Code,
+	)
+	var i Currency
+	err := row.Scan(
+		&i.Code,
+		&i.Exponent,
+		&i.Symbol,
+		&i.Enabled,
+		&i.MinTransferAmount,
+		&i.MaxTransferAmount,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PayeeCooldownLimit,
+	)
+	return i, err
+}
@@ -0,0 +1,73 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomCurrency(t *testing.T) Currency {
+	arg := CreateCurrencyParams{
+		Code:               strings.ToUpper(util.GenerateRandomString(3)),
+		Exponent:           0,
+		Symbol:             "¤",
+		Enabled:            false,
+		MinTransferAmount:  100,
+		MaxTransferAmount:  100000,
+		PayeeCooldownLimit: 10000,
+	}
+
+	currency, err := testStore.CreateCurrency(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.Code, currency.Code)
+	require.Equal(t, arg.Exponent, currency.Exponent)
+	require.Equal(t, arg.Symbol, currency.Symbol)
+	require.False(t, currency.Enabled)
+	require.Equal(t, arg.MinTransferAmount, currency.MinTransferAmount)
+	require.Equal(t, arg.MaxTransferAmount, currency.MaxTransferAmount)
+	require.Equal(t, arg.PayeeCooldownLimit, currency.PayeeCooldownLimit)
+	require.NotZero(t, currency.CreatedAt)
+
+	return currency
+}
+
+func TestSeededCurrencies(t *testing.T) {
+	for _, code := range []string{util.USD, util.NGN, util.EUR, util.CAD} {
+		currency, err := testStore.GetCurrency(context.Background(), code)
+		require.NoError(t, err)
+		require.True(t, currency.Enabled)
+		require.Equal(t, int16(2), currency.Exponent)
+	}
+}
+
+func TestUpdateCurrency(t *testing.T) {
+	currency := createRandomCurrency(t)
+
+	enabled, err := testStore.ListEnabledCurrencies(context.Background())
+	require.NoError(t, err)
+	for _, c := range enabled {
+		require.NotEqual(t, currency.Code, c.Code)
+	}
+
+	updated, err := testStore.UpdateCurrency(context.Background(), UpdateCurrencyParams{
+		Code:    currency.Code,
+		Enabled: pgtype.Bool{Bool: true, Valid: true},
+	})
+	require.NoError(t, err)
+	require.True(t, updated.Enabled)
+	// fields left out of the update keep their values
+	require.Equal(t, currency.Symbol, updated.Symbol)
+	require.Equal(t, currency.MaxTransferAmount, updated.MaxTransferAmount)
+
+	// the limits constraint rejects a maximum below the minimum
+	_, err = testStore.UpdateCurrency(context.Background(), UpdateCurrencyParams{
+		Code:              currency.Code,
+		MaxTransferAmount: pgtype.Float8{Float64: 1, Valid: true},
+	})
+	require.Error(t, err)
+	require.Equal(t, CheckViolation, ErrorCode(err))
+}
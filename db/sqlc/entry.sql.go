@@ -7,36 +7,53 @@ package db
 
 import (
 	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countAccountEntries = `-- name: CountAccountEntries :one
+SELECT COUNT(*) FROM entries
+WHERE account_id = $1
+`
+
+func (q *Queries) CountAccountEntries(ctx context.Context, accountID int64) (int64, error) {
+	row := q.db.QueryRow(ctx, countAccountEntries, accountID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
-  account_id, amount
+  account_id, amount, transfer_id
 ) VALUES (
-  $1, $2
+  $1, $2, $3
 )
-RETURNING id, account_id, amount, created_at
+RETURNING id, account_id, amount, created_at, transfer_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64   `json:"account_id"`
-	Amount    float64 `json:"amount"`
+	AccountID  int64       `json:"account_id"`
+	Amount     float64     `json:"amount"`
+	TransferID pgtype.Int8 `json:"transfer_id"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRow(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRow(ctx, createEntry, arg.AccountID, arg.Amount, arg.TransferID)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
 	)
 	return i, err
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
 WHERE id = $1 LIMIT 1
 `
 
@@ -48,12 +65,129 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
 	)
 	return i, err
 }
 
+const listAccountEntries = `-- name: ListAccountEntries :many
+SELECT e.id, e.account_id, e.amount, e.created_at, e.transfer_id,
+  COALESCE(t.memo, '')::varchar AS memo,
+  COALESCE(t.reference, '')::varchar AS reference,
+  COALESCE(t.end_to_end_id, '')::varchar AS end_to_end_id
+FROM entries e
+LEFT JOIN transfer t ON t.id = e.transfer_id
+WHERE e.account_id = $1 AND e.id < $2
+ORDER BY e.id DESC
+LIMIT $3
+`
+
+type ListAccountEntriesParams struct {
+	AccountID int64 `json:"account_id"`
+	Cursor    int64 `json:"cursor"`
+	Limit     int32 `json:"limit"`
+}
+
+type ListAccountEntriesRow struct {
+	ID         int64       `json:"id"`
+	AccountID  int64       `json:"account_id"`
+	Amount     float64     `json:"amount"`
+	CreatedAt  time.Time   `json:"created_at"`
+	TransferID pgtype.Int8 `json:"transfer_id"`
+	Memo       string      `json:"memo"`
+	Reference  string      `json:"reference"`
+	EndToEndID string      `json:"end_to_end_id"`
+}
+
+func (q *Queries) ListAccountEntries(ctx context.Context, arg ListAccountEntriesParams) ([]ListAccountEntriesRow, error) {
+	rows, err := q.db.Query(ctx, listAccountEntries, arg.AccountID, arg.Cursor, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAccountEntriesRow{}
+	for rows.Next() {
+		var i ListAccountEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.Memo,
+			&i.Reference,
+			&i.EndToEndID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountEntriesReverse = `-- name: ListAccountEntriesReverse :many
+SELECT e.id, e.account_id, e.amount, e.created_at, e.transfer_id,
+  COALESCE(t.memo, '')::varchar AS memo,
+  COALESCE(t.reference, '')::varchar AS reference,
+  COALESCE(t.end_to_end_id, '')::varchar AS end_to_end_id
+FROM entries e
+LEFT JOIN transfer t ON t.id = e.transfer_id
+WHERE e.account_id = $1 AND e.id > $2
+ORDER BY e.id
+LIMIT $3
+`
+
+type ListAccountEntriesReverseParams struct {
+	AccountID int64 `json:"account_id"`
+	Cursor    int64 `json:"cursor"`
+	Limit     int32 `json:"limit"`
+}
+
+type ListAccountEntriesReverseRow struct {
+	ID         int64       `json:"id"`
+	AccountID  int64       `json:"account_id"`
+	Amount     float64     `json:"amount"`
+	CreatedAt  time.Time   `json:"created_at"`
+	TransferID pgtype.Int8 `json:"transfer_id"`
+	Memo       string      `json:"memo"`
+	Reference  string      `json:"reference"`
+	EndToEndID string      `json:"end_to_end_id"`
+}
+
+func (q *Queries) ListAccountEntriesReverse(ctx context.Context, arg ListAccountEntriesReverseParams) ([]ListAccountEntriesReverseRow, error) {
+	rows, err := q.db.Query(ctx, listAccountEntriesReverse, arg.AccountID, arg.Cursor, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAccountEntriesReverseRow{}
+	for rows.Next() {
+		var i ListAccountEntriesReverseRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.Memo,
+			&i.Reference,
+			&i.EndToEndID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id FROM entries
 ORDER BY created_at DESC
 `
 
@@ -71,6 +205,7 @@ func (q *Queries) ListEntries(ctx context.Context) ([]Entry, error) {
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
 		); err != nil {
 			return nil, err
 		}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -54,3 +55,35 @@ func TestGetEntry(t *testing.T) {
 	require.Equal(t, entryGot.Amount, entry.Amount)
 
 }
+
+func TestListAccountEntries(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Memo:          "dinner",
+		Reference:     "REF-1",
+	})
+	require.NoError(t, err)
+
+	// the memo shows up on the entries of both accounts
+	for _, account := range []Account{account1, account2} {
+		entries, err := testStore.ListAccountEntries(context.Background(), ListAccountEntriesParams{
+			AccountID: account.ID,
+			Cursor:    math.MaxInt64,
+			Limit:     5,
+		})
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.True(t, entries[0].TransferID.Valid)
+		require.Equal(t, "dinner", entries[0].Memo)
+		require.Equal(t, "REF-1", entries[0].Reference)
+
+		count, err := testStore.CountAccountEntries(context.Background(), account.ID)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count)
+	}
+}
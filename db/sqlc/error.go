@@ -10,9 +10,20 @@ import (
 const (
 	ForeignKeyViolation = "23503"
 	UniqueViolation     = "23505"
+	CheckViolation      = "23514"
+	// postgres aborts one of the conflicting transactions with these, running it again usually succeeds
+	SerializationFailure = "40001"
+	DeadlockDetected     = "40P01"
 )
 
 var ErrRecordNotFound = pgx.ErrNoRows
+var ErrSameAccount = errors.New("cannot transfer to the same account")
+
+// ErrPayeeCooldown refuses a transfer above the cool-down limit to an account that isn't an established payee of the sender
+var ErrPayeeCooldown = errors.New("transfer exceeds the limit for recipients that are not an established payee")
+
+// ErrInsufficientFunds refuses a transfer larger than the sender's balance at the time it is debited
+var ErrInsufficientFunds = errors.New("insufficient balance")
 var ErrUniqueViolation = &pgconn.PgError{
 	Code: UniqueViolation,
 }
@@ -3,16 +3,53 @@ package db
 import (
 	"context"
 	"fmt"
+
+	"github.com/S-Devoe/golang-simple-bank/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) error {
+// maxTxAttempts bounds how many times execTx runs a transaction postgres keeps aborting over conflicts
+const maxTxAttempts = 3
+
+// retryReasons are the errors execTx retries a transaction on, with the reason it tells the TxObserver
+var retryReasons = map[string]string{
+	SerializationFailure: "serialization_failure",
+	DeadlockDetected:     "deadlock",
+}
+
+// execTx runs fn in a transaction, running it again in a fresh one when postgres aborted it to resolve a conflict.
+// Each attempt is a span, fn must run its queries with the ctx it is given so they show up under it.
+func (store *SQLStore) execTx(ctx context.Context, fn func(context.Context, *Queries) error) error {
+	for attempt := 1; ; attempt++ {
+		err := store.runTx(ctx, attempt, fn)
+		reason, retryable := retryReasons[ErrorCode(err)]
+		if !retryable || attempt == maxTxAttempts || ctx.Err() != nil {
+			return err
+		}
+		store.observer.TxRetried(reason)
+	}
+}
+
+func (store *SQLStore) runTx(ctx context.Context, attempt int, fn func(context.Context, *Queries) error) (err error) {
+	ctx, span := tracing.Tracer().Start(ctx, "db.tx", trace.WithAttributes(attribute.Int("db.tx.attempt", attempt)))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
 	tx, err := store.connPool.Begin(ctx)
 	if err != nil {
 		return err
 	}
 	q := New(tx)
-	err = fn(q)
+	err = fn(ctx, q)
 	if err != nil {
+		store.observer.TxRolledBack()
 		if rbErr := tx.Rollback(ctx); rbErr != nil {
 			return fmt.Errorf("ts err: %s, rb err: %s ", err, rbErr)
 		}
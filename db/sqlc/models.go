@@ -6,6 +6,8 @@ package db
 
 import (
 	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Account struct {
@@ -16,12 +18,67 @@ type Account struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type AccountDailyRollup struct {
+	AccountID int64 `json:"account_id"`
+	// midnight UTC of the day the entries were booked
+	Day    time.Time `json:"day"`
+	Inflow float64   `json:"inflow"`
+	// outflow is stored as a positive amount
+	Outflow        float64 `json:"outflow"`
+	ClosingBalance float64 `json:"closing_balance"`
+	EntryCount     int64   `json:"entry_count"`
+}
+
+type Alias struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// normalized handle: lower-case email, @tag or +E.164 phone number
+	Alias      string    `json:"alias"`
+	Kind       string    `json:"kind"`
+	IsVerified bool      `json:"is_verified"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type CounterpartyDailyRollup struct {
+	AccountID             int64 `json:"account_id"`
+	CounterpartyAccountID int64 `json:"counterparty_account_id"`
+	// midnight UTC of the day the transfers were made
+	Day           time.Time `json:"day"`
+	Received      float64   `json:"received"`
+	Sent          float64   `json:"sent"`
+	TransferCount int64     `json:"transfer_count"`
+}
+
+type Currency struct {
+	Code string `json:"code"`
+	// number of minor-unit decimals, e.g. 2 for USD and 0 for JPY
+	Exponent           int16     `json:"exponent"`
+	Symbol             string    `json:"symbol"`
+	Enabled            bool      `json:"enabled"`
+	MinTransferAmount  float64   `json:"min_transfer_amount"`
+	MaxTransferAmount  float64   `json:"max_transfer_amount"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+	PayeeCooldownLimit float64   `json:"payee_cooldown_limit"`
+}
+
 type Entry struct {
 	ID        int64 `json:"id"`
 	AccountID int64 `json:"account_id"`
 	// amount can be negative or positive
-	Amount    float64   `json:"amount"`
-	CreatedAt time.Time `json:"created_at"`
+	Amount     float64     `json:"amount"`
+	CreatedAt  time.Time   `json:"created_at"`
+	TransferID pgtype.Int8 `json:"transfer_id"`
+}
+
+type Payee struct {
+	ID        int64  `json:"id"`
+	Owner     string `json:"owner"`
+	Nickname  string `json:"nickname"`
+	AccountID int64  `json:"account_id"`
+	// verified full name of the target account owner
+	DisplayName string    `json:"display_name"`
+	CreatedAt   time.Time `json:"created_at"`
 }
 
 type Session struct {
@@ -42,6 +99,10 @@ type Transfer struct {
 	// amount must be positive
 	Amount    float64   `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	Memo      string    `json:"memo"`
+	Reference string    `json:"reference"`
+	// client supplied id, unique per sending account
+	EndToEndID string `json:"end_to_end_id"`
 }
 
 type User struct {
@@ -51,4 +112,16 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	Role              string    `json:"role"`
+	IsEmailVerified   bool      `json:"is_email_verified"`
+}
+
+type VerifyEmail struct {
+	ID         int64     `json:"id"`
+	Username   string    `json:"username"`
+	Email      string    `json:"email"`
+	SecretCode string    `json:"secret_code"`
+	IsUsed     bool      `json:"is_used"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiredAt  time.Time `json:"expired_at"`
 }
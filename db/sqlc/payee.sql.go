@@ -0,0 +1,176 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: payee.sql
+
+package db
+
+import (
+	"context"
+)
+
+const countPayees = `-- name: CountPayees :one
+SELECT COUNT(*) FROM payees
+WHERE owner = $1
+`
+
+func (q *Queries) CountPayees(ctx context.Context, owner string) (int64, error) {
+	row := q.db.QueryRow(ctx, countPayees, owner)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createPayee = `-- name: CreatePayee :one
+INSERT INTO payees (
+  owner, nickname, account_id, display_name
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING id, owner, nickname, account_id, display_name, created_at
+`
+
+type CreatePayeeParams struct {
+	Owner       string `json:"owner"`
+	Nickname    string `json:"nickname"`
+	AccountID   int64  `json:"account_id"`
+	DisplayName string `json:"display_name"`
+}
+
+func (q *Queries) CreatePayee(ctx context.Context, arg CreatePayeeParams) (Payee, error) {
+	row := q.db.QueryRow(ctx, createPayee,
+		arg.Owner,
+		arg.Nickname,
+		arg.AccountID,
+		arg.DisplayName,
+	)
+	var i Payee
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Nickname,
+		&i.AccountID,
+		&i.DisplayName,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deletePayee = `-- name: DeletePayee :exec
+DELETE FROM payees
+WHERE id = $1
+`
+
+func (q *Queries) DeletePayee(ctx context.Context, id int64) error {
+	_, err := q.db.Exec(ctx, deletePayee, id)
+	return err
+}
+
+const getPayee = `-- name: GetPayee :one
+SELECT id, owner, nickname, account_id, display_name, created_at FROM payees
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetPayee(ctx context.Context, id int64) (Payee, error) {
+	row := q.db.QueryRow(ctx, getPayee, id)
+	var i Payee
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Nickname,
+		&i.AccountID,
+		&i.DisplayName,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const isEstablishedPayee = `-- name: IsEstablishedPayee :one
+SELECT EXISTS (
+  SELECT 1 FROM payees
+  JOIN accounts ON accounts.owner = payees.owner
+  WHERE accounts.id = $1
+    AND payees.account_id = $2
+    AND payees.created_at <= now() - make_interval(secs => $3::float8)
+)
+`
+
+type IsEstablishedPayeeParams struct {
+	FromAccountID   int64   `json:"from_account_id"`
+	ToAccountID     int64   `json:"to_account_id"`
+	CooldownSeconds float64 `json:"cooldown_seconds"`
+}
+
+// whether the owner of the sending account saved the receiving account as a payee at least cooldown_seconds ago
+func (q *Queries) IsEstablishedPayee(ctx context.Context, arg IsEstablishedPayeeParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isEstablishedPayee, arg.FromAccountID, arg.ToAccountID, arg.CooldownSeconds)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const listPayees = `-- name: ListPayees :many
+SELECT id, owner, nickname, account_id, display_name, created_at FROM payees
+WHERE owner = $1
+ORDER BY id
+LIMIT $2 OFFSET $3
+`
+
+type ListPayeesParams struct {
+	Owner  string `json:"owner"`
+	Limit  int32  `json:"limit"`
+	Offset int32  `json:"offset"`
+}
+
+func (q *Queries) ListPayees(ctx context.Context, arg ListPayeesParams) ([]Payee, error) {
+	rows, err := q.db.Query(ctx, listPayees, arg.Owner, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Payee{}
+	for rows.Next() {
+		var i Payee
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Nickname,
+			&i.AccountID,
+			&i.DisplayName,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updatePayee = `-- name: UpdatePayee :one
+UPDATE payees
+SET nickname = $1
+WHERE id = $2
+RETURNING id, owner, nickname, account_id, display_name, created_at
+`
+
+type UpdatePayeeParams struct {
+	Nickname string `json:"nickname"`
+	ID       int64  `json:"id"`
+}
+
+func (q *Queries) UpdatePayee(ctx context.Context, arg UpdatePayeeParams) (Payee, error) {
+	row := q.db.QueryRow(ctx, updatePayee, arg.Nickname, arg.ID)
+	var i Payee
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Nickname,
+		&i.AccountID,
+		&i.DisplayName,
+		&i.CreatedAt,
+	)
+	return i, err
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPayee(t *testing.T, owner User) Payee {
+	account := createRandomAccount(t)
+	arg := CreatePayeeParams{
+		Owner:       owner.Username,
+		Nickname:    util.GenerateRandomString(8),
+		AccountID:   account.ID,
+		DisplayName: util.GenerateRandomName(),
+	}
+
+	payee, err := testStore.CreatePayee(context.Background(), arg)
+
+	require.NoError(t, err)
+	require.NotEmpty(t, payee)
+	require.Equal(t, arg.Owner, payee.Owner)
+	require.Equal(t, arg.Nickname, payee.Nickname)
+	require.Equal(t, arg.AccountID, payee.AccountID)
+	require.Equal(t, arg.DisplayName, payee.DisplayName)
+
+	require.NotZero(t, payee.ID)
+	require.NotZero(t, payee.CreatedAt)
+
+	return payee
+}
+
+func TestCreatePayee(t *testing.T) {
+	createRandomPayee(t, createRandomUser(t))
+}
+
+func TestCreateDuplicatePayee(t *testing.T) {
+	user := createRandomUser(t)
+	payee := createRandomPayee(t, user)
+
+	_, err := testStore.CreatePayee(context.Background(), CreatePayeeParams{
+		Owner:       user.Username,
+		Nickname:    util.GenerateRandomString(8),
+		AccountID:   payee.AccountID,
+		DisplayName: payee.DisplayName,
+	})
+	require.Error(t, err)
+	require.Equal(t, UniqueViolation, ErrorCode(err))
+}
+
+func TestUpdatePayee(t *testing.T) {
+	payee1 := createRandomPayee(t, createRandomUser(t))
+
+	arg := UpdatePayeeParams{
+		ID:       payee1.ID,
+		Nickname: util.GenerateRandomString(8),
+	}
+
+	payee2, err := testStore.UpdatePayee(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, payee1.ID, payee2.ID)
+	require.Equal(t, arg.Nickname, payee2.Nickname)
+	require.Equal(t, payee1.AccountID, payee2.AccountID)
+}
+
+func TestDeletePayee(t *testing.T) {
+	payee := createRandomPayee(t, createRandomUser(t))
+
+	err := testStore.DeletePayee(context.Background(), payee.ID)
+	require.NoError(t, err)
+
+	_, err = testStore.GetPayee(context.Background(), payee.ID)
+	require.EqualError(t, err, ErrRecordNotFound.Error())
+}
+
+func TestListPayees(t *testing.T) {
+	user := createRandomUser(t)
+	for i := 0; i < 4; i++ {
+		createRandomPayee(t, user)
+	}
+
+	payees, err := testStore.ListPayees(context.Background(), ListPayeesParams{
+		Owner:  user.Username,
+		Limit:  3,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, payees, 3)
+	for _, payee := range payees {
+		require.Equal(t, user.Username, payee.Owner)
+	}
+
+	count, err := testStore.CountPayees(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(4), count)
+}
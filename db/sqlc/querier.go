@@ -10,26 +10,83 @@ import (
 
 type Querier interface {
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
-	CountAccounts(ctx context.Context) (int64, error)
+	BlockSession(ctx context.Context, id string) (Session, error)
+	BlockUserSessions(ctx context.Context, username string) (int64, error)
+	CountAccountEntries(ctx context.Context, accountID int64) (int64, error)
+	CountAccounts(ctx context.Context, owner string) (int64, error)
+	CountOwnerTransfers(ctx context.Context, owner string) (int64, error)
+	CountPayees(ctx context.Context, owner string) (int64, error)
+	CountSearchTransfers(ctx context.Context, arg CountSearchTransfersParams) (int64, error)
+	CountSessions(ctx context.Context, username string) (int64, error)
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAlias(ctx context.Context, arg CreateAliasParams) (Alias, error)
+	CreateCurrency(ctx context.Context, arg CreateCurrencyParams) (Currency, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreatePayee(ctx context.Context, arg CreatePayeeParams) (Payee, error)
 	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateVerifyEmail(ctx context.Context, arg CreateVerifyEmailParams) (VerifyEmail, error)
+	// the balance is checked under the row lock the update takes, so concurrent debits can't overdraw the account
+	DebitAccountBalance(ctx context.Context, arg DebitAccountBalanceParams) (Account, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteAlias(ctx context.Context, id int64) error
+	DeletePayee(ctx context.Context, id int64) error
 	DeleteUser(ctx context.Context, username string) error
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	// every day from the account's opening up to today counts towards the average balance, a day without
+	// entries closing on the balance of the last day that had some, or before the first one, on its opening balance
+	GetAccountAnalytics(ctx context.Context, arg GetAccountAnalyticsParams) ([]GetAccountAnalyticsRow, error)
+	GetAccountByOwnerAndCurrency(ctx context.Context, arg GetAccountByOwnerAndCurrencyParams) (Account, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAlias(ctx context.Context, id int64) (Alias, error)
+	GetAuthSession(ctx context.Context, id string) (GetAuthSessionRow, error)
+	GetCurrency(ctx context.Context, code string) (Currency, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetPayee(ctx context.Context, id int64) (Payee, error)
 	GetSession(ctx context.Context, id string) (Session, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	GetTransferByEndToEndID(ctx context.Context, arg GetTransferByEndToEndIDParams) (Transfer, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserForUpdate(ctx context.Context, username string) (User, error)
+	GetVerifiedAlias(ctx context.Context, alias string) (Alias, error)
+	// whether the owner of the sending account saved the receiving account as a payee at least cooldown_seconds ago
+	IsEstablishedPayee(ctx context.Context, arg IsEstablishedPayeeParams) (bool, error)
+	ListAccountEntries(ctx context.Context, arg ListAccountEntriesParams) ([]ListAccountEntriesRow, error)
+	ListAccountEntriesReverse(ctx context.Context, arg ListAccountEntriesReverseParams) ([]ListAccountEntriesReverseRow, error)
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAccountsReverse(ctx context.Context, arg ListAccountsReverseParams) ([]Account, error)
+	ListAliases(ctx context.Context, username string) ([]Alias, error)
+	ListCurrencies(ctx context.Context) ([]Currency, error)
+	ListEnabledCurrencies(ctx context.Context) ([]Currency, error)
 	ListEntries(ctx context.Context) ([]Entry, error)
+	ListOwnerTransfers(ctx context.Context, arg ListOwnerTransfersParams) ([]Transfer, error)
+	ListOwnerTransfersReverse(ctx context.Context, arg ListOwnerTransfersReverseParams) ([]Transfer, error)
+	ListPayees(ctx context.Context, arg ListPayeesParams) ([]Payee, error)
+	ListSessions(ctx context.Context, arg ListSessionsParams) ([]Session, error)
+	ListSessionsReverse(ctx context.Context, arg ListSessionsReverseParams) ([]Session, error)
+	ListTopCounterparties(ctx context.Context, arg ListTopCounterpartiesParams) ([]ListTopCounterpartiesRow, error)
 	ListTransfers(ctx context.Context) ([]Transfer, error)
+	// accounts whose balance differs from the sum of their ledger entries by at least a minor unit
+	ListUnbalancedAccounts(ctx context.Context) ([]ListUnbalancedAccountsRow, error)
+	SearchTransfers(ctx context.Context, arg SearchTransfersParams) ([]SearchTransfersRow, error)
+	// releases the user's email alias for an address that is no longer theirs, so it stops resolving to them
+	UnverifyEmailAlias(ctx context.Context, arg UnverifyEmailAliasParams) (int64, error)
 	// NOTE FOR ME: balance is $2 and id is $1 in the UDEMY course.
 	// i want to see what happens if i change the order of the variables in the query.
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateCurrency(ctx context.Context, arg UpdateCurrencyParams) (Currency, error)
+	UpdatePayee(ctx context.Context, arg UpdatePayeeParams) (Payee, error)
+	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error)
+	UpsertCounterpartyRollup(ctx context.Context, arg UpsertCounterpartyRollupParams) error
+	UpsertDailyRollup(ctx context.Context, arg UpsertDailyRollupParams) error
+	// a code works once, before it expires
+	UseVerifyEmail(ctx context.Context, arg UseVerifyEmailParams) (VerifyEmail, error)
+	// verifies the user's email alias for an address they just verified, unless someone else holds it verified
+	VerifyEmailAlias(ctx context.Context, arg VerifyEmailAliasParams) (int64, error)
+	// only confirms the address the code was sent to, a code for an address the user has since changed does nothing
+	VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,59 @@
+// Code generated by sqlc. DO NOT EDIT.
+// versions:
+//   sqlc v1.27.0
+// source: reconcile.sql
+
+package db
+
+import (
+	"context"
+)
+
+const listUnbalancedAccounts = `-- name: ListUnbalancedAccounts :many
+SELECT
+  a.id,
+  a.owner,
+  a.currency,
+  a.balance,
+  COALESCE(SUM(e.amount), 0)::float8 AS entries_total
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+GROUP BY a.id
+HAVING abs(a.balance - COALESCE(SUM(e.amount), 0)) >= 0.005
+ORDER BY a.id
+`
+
+type ListUnbalancedAccountsRow struct {
+	ID           int64   `json:"id"`
+	Owner        string  `json:"owner"`
+	Currency     string  `json:"currency"`
+	Balance      float64 `json:"balance"`
+	EntriesTotal float64 `json:"entries_total"`
+}
+
+// accounts whose balance differs from the sum of their ledger entries by at least a minor unit
+func (q *Queries) ListUnbalancedAccounts(ctx context.Context) ([]ListUnbalancedAccountsRow, error) {
+	rows, err := q.db.Query(ctx, listUnbalancedAccounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUnbalancedAccountsRow{}
+	for rows.Next() {
+		var i ListUnbalancedAccountsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Currency,
+			&i.Balance,
+			&i.EntriesTotal,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -10,6 +10,55 @@ import (
 	"time"
 )
 
+const blockSession = `-- name: BlockSession :one
+UPDATE sessions
+SET is_blocked = true
+WHERE id = $1
+RETURNING id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at
+`
+
+func (q *Queries) BlockSession(ctx context.Context, id string) (Session, error) {
+	row := q.db.QueryRow(ctx, blockSession, id)
+	var i Session
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.RefreshToken,
+		&i.UserAgent,
+		&i.ClientIp,
+		&i.IsBlocked,
+		&i.ExpiresAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const blockUserSessions = `-- name: BlockUserSessions :execrows
+UPDATE sessions
+SET is_blocked = true
+WHERE username = $1 AND is_blocked = false
+`
+
+func (q *Queries) BlockUserSessions(ctx context.Context, username string) (int64, error) {
+	result, err := q.db.Exec(ctx, blockUserSessions, username)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const countSessions = `-- name: CountSessions :one
+SELECT COUNT(*) FROM sessions
+WHERE username = $1
+`
+
+func (q *Queries) CountSessions(ctx context.Context, username string) (int64, error) {
+	row := q.db.QueryRow(ctx, countSessions, username)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createSession = `-- name: CreateSession :one
 INSERT INTO sessions (
     id,
@@ -58,6 +107,35 @@ func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) (S
 	return i, err
 }
 
+const getAuthSession = `-- name: GetAuthSession :one
+SELECT sessions.id, sessions.username, sessions.refresh_token, sessions.user_agent, sessions.client_ip, sessions.is_blocked, sessions.expires_at, sessions.created_at, users.password_changed_at
+FROM sessions
+JOIN users ON users.username = sessions.username
+WHERE sessions.id = $1 LIMIT 1
+`
+
+type GetAuthSessionRow struct {
+	Session           Session   `json:"session"`
+	PasswordChangedAt time.Time `json:"password_changed_at"`
+}
+
+func (q *Queries) GetAuthSession(ctx context.Context, id string) (GetAuthSessionRow, error) {
+	row := q.db.QueryRow(ctx, getAuthSession, id)
+	var i GetAuthSessionRow
+	err := row.Scan(
+		&i.Session.ID,
+		&i.Session.Username,
+		&i.Session.RefreshToken,
+		&i.Session.UserAgent,
+		&i.Session.ClientIp,
+		&i.Session.IsBlocked,
+		&i.Session.ExpiresAt,
+		&i.Session.CreatedAt,
+		&i.PasswordChangedAt,
+	)
+	return i, err
+}
+
 const getSession = `-- name: GetSession :one
 SELECT id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at FROM sessions
 WHERE id = $1 LIMIT 1
@@ -78,3 +156,87 @@ func (q *Queries) GetSession(ctx context.Context, id string) (Session, error) {
 	)
 	return i, err
 }
+
+const listSessions = `-- name: ListSessions :many
+SELECT id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at FROM sessions
+WHERE username = $1 AND id < $2
+ORDER BY id DESC
+LIMIT $3
+`
+
+type ListSessionsParams struct {
+	Username string `json:"username"`
+	Cursor   string `json:"cursor"`
+	Limit    int32  `json:"limit"`
+}
+
+func (q *Queries) ListSessions(ctx context.Context, arg ListSessionsParams) ([]Session, error) {
+	rows, err := q.db.Query(ctx, listSessions, arg.Username, arg.Cursor, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Session{}
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.RefreshToken,
+			&i.UserAgent,
+			&i.ClientIp,
+			&i.IsBlocked,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSessionsReverse = `-- name: ListSessionsReverse :many
+SELECT id, username, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at FROM sessions
+WHERE username = $1 AND id > $2
+ORDER BY id
+LIMIT $3
+`
+
+type ListSessionsReverseParams struct {
+	Username string `json:"username"`
+	Cursor   string `json:"cursor"`
+	Limit    int32  `json:"limit"`
+}
+
+func (q *Queries) ListSessionsReverse(ctx context.Context, arg ListSessionsReverseParams) ([]Session, error) {
+	rows, err := q.db.Query(ctx, listSessionsReverse, arg.Username, arg.Cursor, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Session{}
+	for rows.Next() {
+		var i Session
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.RefreshToken,
+			&i.UserAgent,
+			&i.ClientIp,
+			&i.IsBlocked,
+			&i.ExpiresAt,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomSession(t *testing.T, user User) Session {
+	id, err := util.GenerateULID()
+	require.NoError(t, err)
+
+	session, err := testStore.CreateSession(context.Background(), CreateSessionParams{
+		ID:           id.String(),
+		Username:     user.Username,
+		RefreshToken: util.GenerateRandomString(32),
+		UserAgent:    "go-test",
+		ClientIp:     "127.0.0.1",
+		ExpiresAt:    time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	require.False(t, session.IsBlocked)
+	return session
+}
+
+func TestBlockSession(t *testing.T) {
+	session := createRandomSession(t, createRandomUser(t))
+
+	blocked, err := testStore.BlockSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+
+	_, err = testStore.BlockSession(context.Background(), "missing")
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func TestBlockUserSessions(t *testing.T) {
+	user := createRandomUser(t)
+	for i := 0; i < 3; i++ {
+		createRandomSession(t, user)
+	}
+
+	revoked, err := testStore.BlockUserSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), revoked)
+
+	// already blocked sessions are not counted again
+	revoked, err = testStore.BlockUserSessions(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Zero(t, revoked)
+}
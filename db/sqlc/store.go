@@ -2,7 +2,11 @@ package db
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
@@ -10,18 +14,42 @@ import (
 type SQLStore struct {
 	*Queries
 	connPool *pgxpool.Pool
+	observer TxObserver
 }
 
+// TxObserver is told about the transactions the store retries and rolls back, metrics.TxObserver counts them
+type TxObserver interface {
+	// TxRetried is called before a transaction postgres aborted over a conflict runs again
+	TxRetried(reason string)
+	// TxRolledBack is called when a transaction is rolled back because it failed
+	TxRolledBack()
+}
+
+// nopTxObserver is the TxObserver of a store given none
+type nopTxObserver struct{}
+
+func (nopTxObserver) TxRetried(string) {}
+func (nopTxObserver) TxRolledBack()    {}
+
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	ResolveAlias(ctx context.Context, alias string, currency string) (ResolveAliasResult, error)
+	DepositTx(ctx context.Context, arg DepositTxParams) (DepositTxResult, error)
+	CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error)
+	UpdateUserTx(ctx context.Context, arg UpdateUserTxParams) (UpdateUserTxResult, error)
+	VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (VerifyEmailTxResult, error)
 }
 
-// NewStore creates a new store
-func NewStore(connPool *pgxpool.Pool) Store {
+// NewStore creates a new store, observer may be nil
+func NewStore(connPool *pgxpool.Pool, observer TxObserver) Store {
+	if observer == nil {
+		observer = nopTxObserver{}
+	}
 	return &SQLStore{
 		connPool: connPool,
 		Queries:  New(connPool),
+		observer: observer,
 	}
 }
 
@@ -29,6 +57,16 @@ type TransferTxParams struct {
 	FromAccountID int64   `json:"from_account_id"`
 	ToAccountID   int64   `json:"to_account_id"`
 	Amount        float64 `json:"amount"`
+	// ToAlias, when set, is resolved to the recipient's account in Currency inside the transaction
+	ToAlias    string `json:"to_alias"`
+	Currency   string `json:"currency"`
+	Memo       string `json:"memo"`
+	Reference  string `json:"reference"`
+	EndToEndID string `json:"end_to_end_id"`
+	// a transfer above CooldownLimit fails with ErrPayeeCooldown unless the sender saved the recipient's
+	// account as a payee at least CooldownPeriod ago; a zero CooldownLimit leaves the transfer uncapped
+	CooldownLimit  float64       `json:"cooldown_limit"`
+	CooldownPeriod time.Duration `json:"cooldown_period"`
 }
 
 type TransferTxResult struct {
@@ -40,34 +78,69 @@ type TransferTxResult struct {
 }
 
 // TransferTx performs a money transfer from one account to another
-// it creates a transfer record, and account entries, and update accounts' balance within a single database transaction
+// it creates a transfer record, and account entries, and update accounts' balance within a single database transaction.
+// It fails with ErrInsufficientFunds, changing nothing, if the sender's balance doesn't cover the amount when it is debited.
 func (s *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
-	err := s.execTx(ctx, func(q *Queries) error {
+	err := s.execTx(ctx, func(ctx context.Context, q *Queries) error {
 		var err error
 
+		if arg.ToAlias != "" {
+			recipient, err := resolveAlias(ctx, q, arg.ToAlias, arg.Currency)
+			if err != nil {
+				return err
+			}
+			if recipient.Account.ID == arg.FromAccountID {
+				return ErrSameAccount
+			}
+			arg.ToAccountID = recipient.Account.ID
+		}
+
+		// checked once the recipient is known, however the client named it
+		if arg.CooldownLimit > 0 && arg.Amount > arg.CooldownLimit {
+			established, err := q.IsEstablishedPayee(ctx, IsEstablishedPayeeParams{
+				FromAccountID:   arg.FromAccountID,
+				ToAccountID:     arg.ToAccountID,
+				CooldownSeconds: arg.CooldownPeriod.Seconds(),
+			})
+			if err != nil {
+				return err
+			}
+			if !established {
+				return ErrPayeeCooldown
+			}
+		}
+
 		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
 			FromAccountID: arg.FromAccountID,
 			ToAccountID:   arg.ToAccountID,
 			Amount:        arg.Amount,
+			Memo:          arg.Memo,
+			Reference:     arg.Reference,
+			EndToEndID:    arg.EndToEndID,
 		})
 
 		if err != nil {
 			return err
 		}
 
+		// both entries point back at the transfer so each side can see its memo and reference
+		transferID := pgtype.Int8{Int64: result.Transfer.ID, Valid: true}
+
 		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
+			AccountID:  arg.FromAccountID,
+			Amount:     -arg.Amount,
+			TransferID: transferID,
 		})
 		if err != nil {
 			return err
 		}
 
 		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
+			AccountID:  arg.ToAccountID,
+			Amount:     arg.Amount,
+			TransferID: transferID,
 		})
 		if err != nil {
 			return err
@@ -75,10 +148,17 @@ func (s *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (Transf
 
 		// get account -> update its balance
 
-		result.FromAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		result.FromAccount, err = q.DebitAccountBalance(ctx, DebitAccountBalanceParams{
 			ID:     arg.FromAccountID,
-			Amount: -arg.Amount,
+			Amount: arg.Amount,
 		})
+		if errors.Is(err, ErrRecordNotFound) {
+			// no row matched: either the balance is short or the account is gone
+			if _, err := q.GetAccount(ctx, arg.FromAccountID); err != nil {
+				return err
+			}
+			return ErrInsufficientFunds
+		}
 		if err != nil {
 			return err
 		}
@@ -91,8 +171,294 @@ func (s *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (Transf
 			return err
 		}
 
+		// keep the daily rollups behind the analytics endpoint in step with the balances
+		err = q.UpsertDailyRollup(ctx, UpsertDailyRollupParams{
+			AccountID:      arg.FromAccountID,
+			Outflow:        arg.Amount,
+			ClosingBalance: result.FromAccount.Balance,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = q.UpsertDailyRollup(ctx, UpsertDailyRollupParams{
+			AccountID:      arg.ToAccountID,
+			Inflow:         arg.Amount,
+			ClosingBalance: result.ToAccount.Balance,
+		})
+		if err != nil {
+			return err
+		}
+
+		// and the per-counterparty ones behind its top counterparties, one row for each side
+		err = q.UpsertCounterpartyRollup(ctx, UpsertCounterpartyRollupParams{
+			AccountID:             arg.FromAccountID,
+			CounterpartyAccountID: arg.ToAccountID,
+			Sent:                  arg.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		return q.UpsertCounterpartyRollup(ctx, UpsertCounterpartyRollupParams{
+			AccountID:             arg.ToAccountID,
+			CounterpartyAccountID: arg.FromAccountID,
+			Received:              arg.Amount,
+		})
+	})
+
+	return result, err
+}
+
+type DepositTxParams struct {
+	AccountID int64   `json:"account_id"`
+	Amount    float64 `json:"amount"`
+}
+
+type DepositTxResult struct {
+	Account Account `json:"account"`
+	Entry   Entry   `json:"entry"`
+}
+
+// DepositTx credits money from outside the bank to an account
+// it records the entry, updates the balance and the daily rollup within a single database transaction, so the ledger still reconciles
+func (s *SQLStore) DepositTx(ctx context.Context, arg DepositTxParams) (DepositTxResult, error) {
+	var result DepositTxResult
+
+	err := s.execTx(ctx, func(ctx context.Context, q *Queries) error {
+		var err error
+
+		result.Entry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: arg.AccountID,
+			Amount:    arg.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Account, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     arg.AccountID,
+			Amount: arg.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		return q.UpsertDailyRollup(ctx, UpsertDailyRollupParams{
+			AccountID:      arg.AccountID,
+			Inflow:         arg.Amount,
+			ClosingBalance: result.Account.Balance,
+		})
+	})
+
+	return result, err
+}
+
+// EmailVerification is the code a new email address is confirmed with. The store only saves it, the caller
+// mails the VerifyEmail it returns once the transaction has committed, so a slow mail server holds no rows
+// and a rolled back or retried transaction mails nothing.
+type EmailVerification struct {
+	SecretCode string
+	ExpiredAt  time.Time
+}
+
+func saveVerifyEmail(ctx context.Context, q *Queries, user User, verification EmailVerification) (VerifyEmail, error) {
+	return q.CreateVerifyEmail(ctx, CreateVerifyEmailParams{
+		Username:   user.Username,
+		Email:      user.Email,
+		SecretCode: verification.SecretCode,
+		ExpiredAt:  verification.ExpiredAt,
+	})
+}
+
+type CreateUserTxParams struct {
+	CreateUserParams
+	Verification EmailVerification `json:"-"`
+}
+
+type CreateUserTxResult struct {
+	User        User        `json:"user"`
+	VerifyEmail VerifyEmail `json:"verify_email"`
+}
+
+// CreateUserTx creates a user and the code verifying their email address within a single database transaction
+func (s *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error) {
+	var result CreateUserTxResult
+
+	err := s.execTx(ctx, func(ctx context.Context, q *Queries) error {
+		var err error
+
+		result.User, err = q.CreateUser(ctx, arg.CreateUserParams)
+		if err != nil {
+			return err
+		}
+
+		result.VerifyEmail, err = saveVerifyEmail(ctx, q, result.User, arg.Verification)
+		return err
+	})
+
+	return result, err
+}
+
+// UpdateUserTxParams holds the new values of the fields being changed, fields left invalid keep their value
+type UpdateUserTxParams struct {
+	Username       string      `json:"username"`
+	FullName       pgtype.Text `json:"full_name"`
+	Email          pgtype.Text `json:"email"`
+	HashedPassword pgtype.Text `json:"hashed_password"`
+	// Verification is saved for the new address when Email changes it
+	Verification EmailVerification `json:"-"`
+}
+
+type UpdateUserTxResult struct {
+	User User `json:"user"`
+	// BlockedSessions counts the sessions ended by a password change
+	BlockedSessions int64 `json:"blocked_sessions"`
+	// VerifyEmail is the code to mail to a new address, it is empty when the address didn't change
+	VerifyEmail VerifyEmail `json:"verify_email"`
+}
+
+// UpdateUserTx changes a user's profile within a single database transaction.
+// a new password bumps password_changed_at and blocks every session started with the old one,
+// a new email address has to be verified again with the code saved for it, and the alias of the old one is unverified
+func (s *SQLStore) UpdateUserTx(ctx context.Context, arg UpdateUserTxParams) (UpdateUserTxResult, error) {
+	var result UpdateUserTxResult
+
+	err := s.execTx(ctx, func(ctx context.Context, q *Queries) error {
+		user, err := q.GetUserForUpdate(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		update := UpdateUserParams{
+			Username:       arg.Username,
+			FullName:       arg.FullName,
+			Email:          arg.Email,
+			HashedPassword: arg.HashedPassword,
+		}
+		emailChanged := arg.Email.Valid && arg.Email.String != user.Email
+		if emailChanged {
+			update.IsEmailVerified = pgtype.Bool{Bool: false, Valid: true}
+		}
+		if arg.HashedPassword.Valid {
+			update.PasswordChangedAt = pgtype.Timestamptz{Time: time.Now(), Valid: true}
+		}
+
+		result.User, err = q.UpdateUser(ctx, update)
+		if err != nil {
+			return err
+		}
+
+		if arg.HashedPassword.Valid {
+			result.BlockedSessions, err = q.BlockUserSessions(ctx, arg.Username)
+			if err != nil {
+				return err
+			}
+		}
+
+		if emailChanged {
+			// the old address may go to someone else now, its alias must neither pay this user nor stay reserved
+			_, err = q.UnverifyEmailAlias(ctx, UnverifyEmailAliasParams{
+				Username: arg.Username,
+				Email:    user.Email,
+			})
+			if err != nil {
+				return err
+			}
+
+			result.VerifyEmail, err = saveVerifyEmail(ctx, q, result.User, arg.Verification)
+			if err != nil {
+				return err
+			}
+		}
 		return nil
 	})
 
 	return result, err
 }
+
+type VerifyEmailTxParams struct {
+	EmailID    int64  `json:"email_id"`
+	SecretCode string `json:"secret_code"`
+}
+
+type VerifyEmailTxResult struct {
+	User        User        `json:"user"`
+	VerifyEmail VerifyEmail `json:"verify_email"`
+}
+
+// VerifyEmailTx uses up a verification code and marks the address it was sent to as verified within a single
+// database transaction, along with the user's email alias for that address. It fails with ErrRecordNotFound when the code is unknown, used or expired, or was sent
+// to an address the user has changed since.
+func (s *SQLStore) VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (VerifyEmailTxResult, error) {
+	var result VerifyEmailTxResult
+
+	err := s.execTx(ctx, func(ctx context.Context, q *Queries) error {
+		var err error
+
+		result.VerifyEmail, err = q.UseVerifyEmail(ctx, UseVerifyEmailParams{
+			ID:         arg.EmailID,
+			SecretCode: arg.SecretCode,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.User, err = q.VerifyUserEmail(ctx, VerifyUserEmailParams{
+			Username: result.VerifyEmail.Username,
+			Email:    result.VerifyEmail.Email,
+		})
+		if err != nil {
+			return err
+		}
+
+		// an email alias registered before the address was verified couldn't be verified then
+		_, err = q.VerifyEmailAlias(ctx, VerifyEmailAliasParams{
+			Username: result.User.Username,
+			Email:    result.User.Email,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+type ResolveAliasResult struct {
+	Account Account `json:"account"`
+	Owner   User    `json:"owner"`
+}
+
+// ResolveAlias finds the account in the given currency that a username or a verified email, phone or @tag alias pays into
+func (s *SQLStore) ResolveAlias(ctx context.Context, alias string, currency string) (ResolveAliasResult, error) {
+	return resolveAlias(ctx, s.Queries, alias, currency)
+}
+
+func resolveAlias(ctx context.Context, q *Queries, alias string, currency string) (ResolveAliasResult, error) {
+	var result ResolveAliasResult
+
+	kind, handle, err := util.ParseAlias(alias)
+	if err != nil {
+		return result, err
+	}
+
+	// usernames are always resolvable, every other handle has to be registered and verified
+	username := handle
+	if kind != util.AliasUsername {
+		registered, err := q.GetVerifiedAlias(ctx, handle)
+		if err != nil {
+			return result, err
+		}
+		username = registered.Username
+	}
+
+	result.Owner, err = q.GetUser(ctx, username)
+	if err != nil {
+		return result, err
+	}
+
+	result.Account, err = q.GetAccountByOwnerAndCurrency(ctx, GetAccountByOwnerAndCurrencyParams{
+		Owner:    username,
+		Currency: currency,
+	})
+	return result, err
+}
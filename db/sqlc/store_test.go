@@ -2,9 +2,20 @@ package db
 
 import (
 	"context"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/S-Devoe/golang-simple-bank/tracing"
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestTransferTx(t *testing.T) {
@@ -111,3 +122,357 @@ func TestTransferTx(t *testing.T) {
 	require.Equal(t, account2.Balance+float64(n)*amount, updatedAccount2.Balance)
 
 }
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	user := createRandomUser(t)
+	account1, err := testStore.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  30,
+		Currency: util.USD,
+	})
+	require.NoError(t, err)
+	account2 := createRandomAccount(t)
+
+	// concurrent transfers worth more than the balance, each passing any check made before it starts
+	n := 5
+	errs := make(chan error)
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: account1.ID,
+				ToAccountID:   account2.ID,
+				Amount:        10,
+			})
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrInsufficientFunds)
+	}
+	require.Equal(t, 3, succeeded)
+
+	updatedAccount1, err := testStore.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, float64(0), updatedAccount1.Balance)
+
+	entries, err := testStore.CountAccountEntries(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), entries)
+}
+
+func TestTransferTxToAlias(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	owner2, err := testStore.GetUser(context.Background(), account2.Owner)
+	require.NoError(t, err)
+	alias := createRandomAlias(t, owner2, true)
+
+	amount := float64(10)
+	result, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		Amount:        amount,
+		ToAlias:       alias.Alias,
+		Currency:      account2.Currency,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account2.ID, result.Transfer.ToAccountID)
+	require.Equal(t, account2.ID, result.ToEntry.AccountID)
+	require.Equal(t, account2.Balance+amount, result.ToAccount.Balance)
+
+	// an alias without an account in the currency rolls the whole transfer back
+	_, err = testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		Amount:        amount,
+		ToAlias:       alias.Alias,
+		Currency:      "XXX",
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	updatedAccount1, err := testStore.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-amount, updatedAccount1.Balance)
+}
+
+func TestTransferTxPayeeCooldown(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	owner1, err := testStore.GetUser(context.Background(), account1.Owner)
+	require.NoError(t, err)
+
+	arg := TransferTxParams{
+		FromAccountID:  account1.ID,
+		ToAccountID:    account2.ID,
+		Amount:         10,
+		Currency:       account1.Currency,
+		CooldownLimit:  5,
+		CooldownPeriod: time.Hour,
+	}
+
+	// an account that was never saved as a payee is capped
+	_, err = testStore.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrPayeeCooldown)
+
+	// and so is a payee saved too recently
+	_, err = testStore.CreatePayee(context.Background(), CreatePayeeParams{
+		Owner:       owner1.Username,
+		Nickname:    util.GenerateRandomString(8),
+		AccountID:   account2.ID,
+		DisplayName: util.GenerateRandomName(),
+	})
+	require.NoError(t, err)
+	_, err = testStore.TransferTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrPayeeCooldown)
+
+	// amounts within the limit, and payees older than the period, go through
+	arg.CooldownPeriod = 0
+	_, err = testStore.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	arg.CooldownPeriod = time.Hour
+	arg.Amount = 5
+	_, err = testStore.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+}
+
+func TestDepositTx(t *testing.T) {
+	user := createRandomUser(t)
+	account, err := testStore.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  0,
+		Currency: util.USD,
+	})
+	require.NoError(t, err)
+
+	result, err := testStore.DepositTx(context.Background(), DepositTxParams{AccountID: account.ID, Amount: 250})
+	require.NoError(t, err)
+	require.Equal(t, float64(250), result.Account.Balance)
+	require.Equal(t, account.ID, result.Entry.AccountID)
+	require.False(t, result.Entry.TransferID.Valid)
+
+	// a deposit goes through the ledger, so the account reconciles
+	unbalanced, err := testStore.ListUnbalancedAccounts(context.Background())
+	require.NoError(t, err)
+	for _, row := range unbalanced {
+		require.NotEqual(t, account.ID, row.ID)
+	}
+}
+
+func TestUpdateUserTx(t *testing.T) {
+	user := createRandomUser(t)
+	session := createRandomSession(t, user)
+
+	// changing only the name leaves the password, email and sessions alone
+	newFullName := util.GenerateRandomName()
+	result, err := testStore.UpdateUserTx(context.Background(), UpdateUserTxParams{
+		Username: user.Username,
+		FullName: pgtype.Text{String: newFullName, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, newFullName, result.User.FullName)
+	require.Equal(t, user.Email, result.User.Email)
+	require.Equal(t, user.HashedPassword, result.User.HashedPassword)
+	require.Equal(t, user.IsEmailVerified, result.User.IsEmailVerified)
+	require.Zero(t, result.BlockedSessions)
+
+	oldEmailAlias, err := testStore.CreateAlias(context.Background(), CreateAliasParams{
+		Username:   user.Username,
+		Alias:      strings.ToLower(user.Email),
+		Kind:       util.AliasEmail,
+		IsVerified: true,
+	})
+	require.NoError(t, err)
+
+	newEmail := util.GenerateRandomEmail()
+	newHashedPassword := util.GenerateRandomString(32)
+	verification := testEmailVerification()
+	result, err = testStore.UpdateUserTx(context.Background(), UpdateUserTxParams{
+		Username:       user.Username,
+		Email:          pgtype.Text{String: newEmail, Valid: true},
+		HashedPassword: pgtype.Text{String: newHashedPassword, Valid: true},
+		Verification:   verification,
+	})
+	require.NoError(t, err)
+	require.Equal(t, newEmail, result.User.Email)
+	require.False(t, result.User.IsEmailVerified)
+	require.Equal(t, newEmail, result.VerifyEmail.Email)
+	require.Equal(t, verification.SecretCode, result.VerifyEmail.SecretCode)
+	require.Equal(t, newHashedPassword, result.User.HashedPassword)
+	require.WithinDuration(t, time.Now(), result.User.PasswordChangedAt, time.Second)
+	require.Equal(t, int64(1), result.BlockedSessions)
+
+	blocked, err := testStore.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	require.True(t, blocked.IsBlocked)
+
+	// the old address no longer pays this user
+	oldEmailAlias, err = testStore.GetAlias(context.Background(), oldEmailAlias.ID)
+	require.NoError(t, err)
+	require.False(t, oldEmailAlias.IsVerified)
+
+	_, err = testStore.UpdateUserTx(context.Background(), UpdateUserTxParams{Username: "missing"})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+func testEmailVerification() EmailVerification {
+	return EmailVerification{
+		SecretCode: util.GenerateRandomString(32),
+		ExpiredAt:  time.Now().Add(time.Hour),
+	}
+}
+
+func TestCreateUserTx(t *testing.T) {
+	verification := testEmailVerification()
+	arg := CreateUserTxParams{
+		CreateUserParams: CreateUserParams{
+			Username:       util.GenerateRandomString(10),
+			HashedPassword: util.GenerateRandomString(32),
+			FullName:       util.GenerateRandomName(),
+			Email:          util.GenerateRandomEmail(),
+		},
+		Verification: verification,
+	}
+
+	result, err := testStore.CreateUserTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.False(t, result.User.IsEmailVerified)
+	require.Equal(t, arg.Username, result.VerifyEmail.Username)
+	require.Equal(t, arg.Email, result.VerifyEmail.Email)
+	require.Equal(t, verification.SecretCode, result.VerifyEmail.SecretCode)
+	require.False(t, result.VerifyEmail.IsUsed)
+}
+
+func TestVerifyEmailTx(t *testing.T) {
+	verification := testEmailVerification()
+	created, err := testStore.CreateUserTx(context.Background(), CreateUserTxParams{
+		CreateUserParams: CreateUserParams{
+			Username:       util.GenerateRandomString(10),
+			HashedPassword: util.GenerateRandomString(32),
+			FullName:       util.GenerateRandomName(),
+			Email:          util.GenerateRandomEmail(),
+		},
+		Verification: verification,
+	})
+	require.NoError(t, err)
+	arg := VerifyEmailTxParams{EmailID: created.VerifyEmail.ID, SecretCode: verification.SecretCode}
+
+	// registered while the address was still unverified
+	emailAlias, err := testStore.CreateAlias(context.Background(), CreateAliasParams{
+		Username: created.User.Username,
+		Alias:    strings.ToLower(created.User.Email),
+		Kind:     util.AliasEmail,
+	})
+	require.NoError(t, err)
+
+	_, err = testStore.VerifyEmailTx(context.Background(), VerifyEmailTxParams{EmailID: arg.EmailID, SecretCode: "wrong"})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	result, err := testStore.VerifyEmailTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, result.User.IsEmailVerified)
+	require.True(t, result.VerifyEmail.IsUsed)
+
+	// verifying the address verifies its alias too
+	emailAlias, err = testStore.GetAlias(context.Background(), emailAlias.ID)
+	require.NoError(t, err)
+	require.True(t, emailAlias.IsVerified)
+
+	// a code works once
+	_, err = testStore.VerifyEmailTx(context.Background(), arg)
+	require.ErrorIs(t, err, ErrRecordNotFound)
+
+	// a code sent to an address the user has changed since doesn't verify the new one
+	stale := testEmailVerification()
+	changed, err := testStore.UpdateUserTx(context.Background(), UpdateUserTxParams{
+		Username:     created.User.Username,
+		Email:        pgtype.Text{String: util.GenerateRandomEmail(), Valid: true},
+		Verification: stale,
+	})
+	require.NoError(t, err)
+	fresh := testEmailVerification()
+	_, err = testStore.UpdateUserTx(context.Background(), UpdateUserTxParams{
+		Username:     created.User.Username,
+		Email:        pgtype.Text{String: util.GenerateRandomEmail(), Valid: true},
+		Verification: fresh,
+	})
+	require.NoError(t, err)
+	_, err = testStore.VerifyEmailTx(context.Background(), VerifyEmailTxParams{
+		EmailID:    changed.VerifyEmail.ID,
+		SecretCode: stale.SecretCode,
+	})
+	require.ErrorIs(t, err, ErrRecordNotFound)
+}
+
+// countingTxObserver records what the store tells its TxObserver
+type countingTxObserver struct {
+	retries   []string
+	rollbacks int
+}
+
+func (observer *countingTxObserver) TxRetried(reason string) {
+	observer.retries = append(observer.retries, reason)
+}
+
+func (observer *countingTxObserver) TxRolledBack() {
+	observer.rollbacks++
+}
+
+func TestExecTxRetries(t *testing.T) {
+	observer := &countingTxObserver{}
+	store := NewStore(testPool, observer).(*SQLStore)
+
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+	spans := tracetest.NewInMemoryExporter()
+	tracing.Install(tracing.NewProvider(sdktrace.WithSyncer(spans)))
+
+	// a transaction postgres aborted over a conflict runs again in a fresh one
+	attempts := 0
+	err := store.execTx(context.Background(), func(ctx context.Context, q *Queries) error {
+		attempts++
+		if attempts == 1 {
+			return &pgconn.PgError{Code: SerializationFailure}
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, attempts)
+	require.Equal(t, []string{"serialization_failure"}, observer.retries)
+	require.Equal(t, 1, observer.rollbacks)
+
+	// each attempt is a span of its own
+	var txSpans []tracetest.SpanStub
+	for _, span := range spans.GetSpans() {
+		if span.Name == "db.tx" {
+			txSpans = append(txSpans, span)
+		}
+	}
+	require.Len(t, txSpans, 2)
+	require.Contains(t, txSpans[0].Attributes, attribute.Int("db.tx.attempt", 1))
+	require.Equal(t, codes.Error, txSpans[0].Status.Code)
+	require.Contains(t, txSpans[1].Attributes, attribute.Int("db.tx.attempt", 2))
+
+	// up to maxTxAttempts times
+	attempts = 0
+	err = store.execTx(context.Background(), func(ctx context.Context, q *Queries) error {
+		attempts++
+		return &pgconn.PgError{Code: SerializationFailure}
+	})
+	require.Equal(t, SerializationFailure, ErrorCode(err))
+	require.Equal(t, maxTxAttempts, attempts)
+
+	// other errors are returned right away
+	attempts = 0
+	err = store.execTx(context.Background(), func(ctx context.Context, q *Queries) error {
+		attempts++
+		return ErrSameAccount
+	})
+	require.ErrorIs(t, err, ErrSameAccount)
+	require.Equal(t, 1, attempts)
+}
@@ -7,25 +7,70 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
+const countOwnerTransfers = `-- name: CountOwnerTransfers :one
+SELECT COUNT(*) FROM transfer t
+WHERE t.from_account_id IN (SELECT a.id FROM accounts a WHERE a.owner = $1)
+  OR t.to_account_id IN (SELECT a.id FROM accounts a WHERE a.owner = $1)
+`
+
+func (q *Queries) CountOwnerTransfers(ctx context.Context, owner string) (int64, error) {
+	row := q.db.QueryRow(ctx, countOwnerTransfers, owner)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countSearchTransfers = `-- name: CountSearchTransfers :one
+SELECT COUNT(*) FROM transfer t
+WHERE (
+  t.from_account_id IN (SELECT a.id FROM accounts a WHERE a.owner = $1)
+  OR t.to_account_id IN (SELECT a.id FROM accounts a WHERE a.owner = $1)
+)
+AND to_tsvector('simple', t.memo || ' ' || t.reference || ' ' || t.end_to_end_id) @@ plainto_tsquery('simple', $2)
+`
+
+type CountSearchTransfersParams struct {
+	Owner string `json:"owner"`
+	Query string `json:"query"`
+}
+
+func (q *Queries) CountSearchTransfers(ctx context.Context, arg CountSearchTransfersParams) (int64, error) {
+	row := q.db.QueryRow(ctx, countSearchTransfers, arg.Owner, arg.Query)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfer (
-  from_account_id, to_account_id, amount
+  from_account_id, to_account_id, amount, memo, reference, end_to_end_id
 ) VALUES (
-  $1, $2 , $3
+  $1, $2 , $3, $4, $5, $6
 )
-RETURNING id, from_account_id, to_account_id, amount, created_at
+RETURNING id, from_account_id, to_account_id, amount, created_at, memo, reference, end_to_end_id
 `
 
 type CreateTransferParams struct {
 	FromAccountID int64   `json:"from_account_id"`
 	ToAccountID   int64   `json:"to_account_id"`
 	Amount        float64 `json:"amount"`
+	Memo          string  `json:"memo"`
+	Reference     string  `json:"reference"`
+	EndToEndID    string  `json:"end_to_end_id"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRow(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRow(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Memo,
+		arg.Reference,
+		arg.EndToEndID,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -33,12 +78,15 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Memo,
+		&i.Reference,
+		&i.EndToEndID,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfer
+SELECT id, from_account_id, to_account_id, amount, created_at, memo, reference, end_to_end_id FROM transfer
 WHERE id = $1 LIMIT 1
 `
 
@@ -51,12 +99,134 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Memo,
+		&i.Reference,
+		&i.EndToEndID,
 	)
 	return i, err
 }
 
+const getTransferByEndToEndID = `-- name: GetTransferByEndToEndID :one
+SELECT id, from_account_id, to_account_id, amount, created_at, memo, reference, end_to_end_id FROM transfer
+WHERE from_account_id = $1 AND end_to_end_id = $2
+LIMIT 1
+`
+
+type GetTransferByEndToEndIDParams struct {
+	FromAccountID int64  `json:"from_account_id"`
+	EndToEndID    string `json:"end_to_end_id"`
+}
+
+func (q *Queries) GetTransferByEndToEndID(ctx context.Context, arg GetTransferByEndToEndIDParams) (Transfer, error) {
+	row := q.db.QueryRow(ctx, getTransferByEndToEndID, arg.FromAccountID, arg.EndToEndID)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.Memo,
+		&i.Reference,
+		&i.EndToEndID,
+	)
+	return i, err
+}
+
+const listOwnerTransfers = `-- name: ListOwnerTransfers :many
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.memo, t.reference, t.end_to_end_id FROM transfer t
+WHERE (
+  t.from_account_id IN (SELECT a.id FROM accounts a WHERE a.owner = $1)
+  OR t.to_account_id IN (SELECT a.id FROM accounts a WHERE a.owner = $1)
+)
+AND t.id < $2
+ORDER BY t.id DESC
+LIMIT $3
+`
+
+type ListOwnerTransfersParams struct {
+	Owner  string `json:"owner"`
+	Cursor int64  `json:"cursor"`
+	Limit  int32  `json:"limit"`
+}
+
+func (q *Queries) ListOwnerTransfers(ctx context.Context, arg ListOwnerTransfersParams) ([]Transfer, error) {
+	rows, err := q.db.Query(ctx, listOwnerTransfers, arg.Owner, arg.Cursor, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Memo,
+			&i.Reference,
+			&i.EndToEndID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listOwnerTransfersReverse = `-- name: ListOwnerTransfersReverse :many
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.memo, t.reference, t.end_to_end_id FROM transfer t
+WHERE (
+  t.from_account_id IN (SELECT a.id FROM accounts a WHERE a.owner = $1)
+  OR t.to_account_id IN (SELECT a.id FROM accounts a WHERE a.owner = $1)
+)
+AND t.id > $2
+ORDER BY t.id
+LIMIT $3
+`
+
+type ListOwnerTransfersReverseParams struct {
+	Owner  string `json:"owner"`
+	Cursor int64  `json:"cursor"`
+	Limit  int32  `json:"limit"`
+}
+
+func (q *Queries) ListOwnerTransfersReverse(ctx context.Context, arg ListOwnerTransfersReverseParams) ([]Transfer, error) {
+	rows, err := q.db.Query(ctx, listOwnerTransfersReverse, arg.Owner, arg.Cursor, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Memo,
+			&i.Reference,
+			&i.EndToEndID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfer
+SELECT id, from_account_id, to_account_id, amount, created_at, memo, reference, end_to_end_id FROM transfer
 ORDER BY created_at DESC
 `
 
@@ -75,6 +245,79 @@ func (q *Queries) ListTransfers(ctx context.Context) ([]Transfer, error) {
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.Memo,
+			&i.Reference,
+			&i.EndToEndID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchTransfers = `-- name: SearchTransfers :many
+SELECT t.id, t.from_account_id, t.to_account_id, t.amount, t.created_at, t.memo, t.reference, t.end_to_end_id,
+  ts_rank(
+    to_tsvector('simple', t.memo || ' ' || t.reference || ' ' || t.end_to_end_id),
+    plainto_tsquery('simple', $1)
+  )::float8 AS rank
+FROM transfer t
+WHERE (
+  t.from_account_id IN (SELECT a.id FROM accounts a WHERE a.owner = $2)
+  OR t.to_account_id IN (SELECT a.id FROM accounts a WHERE a.owner = $2)
+)
+AND to_tsvector('simple', t.memo || ' ' || t.reference || ' ' || t.end_to_end_id) @@ plainto_tsquery('simple', $1)
+ORDER BY rank DESC, t.id DESC
+LIMIT $4 OFFSET $3
+`
+
+type SearchTransfersParams struct {
+	Query  string `json:"query"`
+	Owner  string `json:"owner"`
+	Offset int32  `json:"offset"`
+	Limit  int32  `json:"limit"`
+}
+
+type SearchTransfersRow struct {
+	ID            int64     `json:"id"`
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        float64   `json:"amount"`
+	CreatedAt     time.Time `json:"created_at"`
+	Memo          string    `json:"memo"`
+	Reference     string    `json:"reference"`
+	EndToEndID    string    `json:"end_to_end_id"`
+	Rank          float64   `json:"rank"`
+}
+
+func (q *Queries) SearchTransfers(ctx context.Context, arg SearchTransfersParams) ([]SearchTransfersRow, error) {
+	rows, err := q.db.Query(ctx, searchTransfers,
+		arg.Query,
+		arg.Owner,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []SearchTransfersRow{}
+	for rows.Next() {
+		var i SearchTransfersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Memo,
+			&i.Reference,
+			&i.EndToEndID,
+			&i.Rank,
 		); err != nil {
 			return nil, err
 		}
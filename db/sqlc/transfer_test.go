@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/S-Devoe/golang-simple-bank/util"
 	"github.com/stretchr/testify/require"
 )
 
@@ -65,3 +66,77 @@ func TestGetTransfer(t *testing.T) {
 	require.Equal(t, transferGot.FromAccountID, transfer.FromAccountID)
 	require.Equal(t, transferGot.ToAccountID, transfer.ToAccountID)
 }
+
+func TestSearchTransfers(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	keyword := util.GenerateRandomString(12)
+
+	_, err := testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Memo:          "rent " + keyword,
+		Reference:     "INV-1",
+	})
+	require.NoError(t, err)
+
+	_, err = testStore.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		Memo:          "groceries",
+	})
+	require.NoError(t, err)
+
+	// both sides of the transfer can find it
+	for _, owner := range []string{account1.Owner, account2.Owner} {
+		transfers, err := testStore.SearchTransfers(context.Background(), SearchTransfersParams{
+			Query:  keyword,
+			Owner:  owner,
+			Limit:  5,
+			Offset: 0,
+		})
+		require.NoError(t, err)
+		require.Len(t, transfers, 1)
+		require.Equal(t, "rent "+keyword, transfers[0].Memo)
+		require.Equal(t, "INV-1", transfers[0].Reference)
+		require.Greater(t, transfers[0].Rank, float64(0))
+
+		count, err := testStore.CountSearchTransfers(context.Background(), CountSearchTransfersParams{
+			Query: keyword,
+			Owner: owner,
+		})
+		require.NoError(t, err)
+		require.Equal(t, int64(1), count)
+	}
+
+	// other users can't see it
+	transfers, err := testStore.SearchTransfers(context.Background(), SearchTransfersParams{
+		Query:  keyword,
+		Owner:  createRandomUser(t).Username,
+		Limit:  5,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Empty(t, transfers)
+}
+
+func TestDuplicateEndToEndID(t *testing.T) {
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	arg := TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+		EndToEndID:    util.GenerateRandomString(16),
+	}
+
+	_, err := testStore.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	_, err = testStore.TransferTx(context.Background(), arg)
+	require.Error(t, err)
+	require.Equal(t, UniqueViolation, ErrorCode(err))
+}
@@ -61,33 +61,91 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/util.Response"
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/util.Response"
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "404": {
                         "description": "Not Found",
                         "schema": {
-                            "$ref": "#/definitions/util.Response"
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/logout": {
+            "post": {
+                "description": "Block the session behind a refresh token so it can no longer renew access tokens",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Logout",
+                "parameters": [
+                    {
+                        "description": "Request body with the refresh token of the session to end",
+                        "name": "logoutRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.logoutUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
                         "schema": {
                             "$ref": "#/definitions/util.Response"
                         }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Session Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
                     }
                 }
             }
         },
         "/signup": {
             "post": {
-                "description": "Create a new user",
+                "description": "Create a new user, and mail them the link verifying their email address",
                 "consumes": [
                     "application/json"
                 ],
@@ -131,19 +189,19 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/util.Response"
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "403": {
                         "description": "Forbidden",
                         "schema": {
-                            "$ref": "#/definitions/util.Response"
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/util.Response"
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     }
                 }
@@ -195,25 +253,168 @@ const docTemplate = `{
                     "400": {
                         "description": "Bad Request",
                         "schema": {
-                            "$ref": "#/definitions/util.Response"
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/util.Response"
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "404": {
                         "description": "Session Not Found",
                         "schema": {
-                            "$ref": "#/definitions/util.Response"
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
-                            "$ref": "#/definitions/util.Response"
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{username}": {
+            "patch": {
+                "description": "Change the authenticated user's full name, email or password. A new password ends every existing session and a new email has to be verified again with the link mailed to it",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update User",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to change",
+                        "name": "updateRequest",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/api.updateUserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/api.userResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    }
+                }
+            }
+        },
+        "/verify_email": {
+            "get": {
+                "description": "Confirm a user's email address with the code in the link mailed to it at signup or after changing it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auth"
+                ],
+                "summary": "Verify Email",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Email ID",
+                        "name": "email_id",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Secret code",
+                        "name": "secret_code",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Success",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/util.Response"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/api.verifyEmailResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/apperr.Problem"
                         }
                     }
                 }
@@ -275,12 +476,21 @@ const docTemplate = `{
                     "type": "string"
                 },
                 "password": {
-                    "type": "string",
-                    "minLength": 6
+                    "type": "string"
                 },
                 "username": {
-                    "type": "string",
-                    "minLength": 3
+                    "type": "string"
+                }
+            }
+        },
+        "api.logoutUserRequest": {
+            "type": "object",
+            "required": [
+                "refresh_token"
+            ],
+            "properties": {
+                "refresh_token": {
+                    "type": "string"
                 }
             }
         },
@@ -306,6 +516,20 @@ const docTemplate = `{
                 }
             }
         },
+        "api.updateUserRequest": {
+            "type": "object",
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "full_name": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
         "api.userResponse": {
             "type": "object",
             "properties": {
@@ -318,6 +542,9 @@ const docTemplate = `{
                 "full_name": {
                     "type": "string"
                 },
+                "is_email_verified": {
+                    "type": "boolean"
+                },
                 "password_changed_at": {
                     "description": "Omit if empty",
                     "type": "string"
@@ -327,6 +554,148 @@ const docTemplate = `{
                 }
             }
         },
+        "api.verifyEmailResponse": {
+            "type": "object",
+            "properties": {
+                "is_verified": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "apperr.Code": {
+            "type": "string",
+            "enum": [
+                "INVALID_ARGUMENT",
+                "UNAUTHENTICATED",
+                "INVALID_CREDENTIALS",
+                "PERMISSION_DENIED",
+                "USER_NOT_FOUND",
+                "USER_ALREADY_EXISTS",
+                "EMAIL_TAKEN",
+                "INVALID_EMAIL_CODE",
+                "ACCOUNT_NOT_FOUND",
+                "ACCOUNT_NOT_OWNED",
+                "ACCOUNT_ALREADY_EXISTS",
+                "CURRENCY_MISMATCH",
+                "INSUFFICIENT_FUNDS",
+                "SAME_ACCOUNT",
+                "IDEMPOTENCY_KEY_REUSED",
+                "PAYEE_NOT_FOUND",
+                "PAYEE_NOT_OWNED",
+                "PAYEE_ALREADY_EXISTS",
+                "PAYEE_COOLDOWN",
+                "ALIAS_NOT_FOUND",
+                "ALIAS_NOT_OWNED",
+                "ALIAS_TAKEN",
+                "CURRENCY_NOT_FOUND",
+                "CURRENCY_ALREADY_EXISTS",
+                "INVALID_REFRESH_TOKEN",
+                "SESSION_NOT_FOUND",
+                "SESSION_BLOCKED",
+                "SESSION_USER_MISMATCH",
+                "SESSION_TOKEN_MISMATCH",
+                "SESSION_EXPIRED",
+                "REQUEST_TOO_LARGE",
+                "TIMEOUT",
+                "UNAVAILABLE",
+                "INTERNAL"
+            ],
+            "x-enum-varnames": [
+                "InvalidArgument",
+                "Unauthenticated",
+                "InvalidCredentials",
+                "PermissionDenied",
+                "UserNotFound",
+                "UserAlreadyExists",
+                "EmailTaken",
+                "InvalidEmailCode",
+                "AccountNotFound",
+                "AccountNotOwned",
+                "AccountAlreadyExists",
+                "CurrencyMismatch",
+                "InsufficientFunds",
+                "SameAccount",
+                "IdempotencyKeyReused",
+                "PayeeNotFound",
+                "PayeeNotOwned",
+                "PayeeAlreadyExists",
+                "PayeeCooldown",
+                "AliasNotFound",
+                "AliasNotOwned",
+                "AliasTaken",
+                "CurrencyNotFound",
+                "CurrencyExists",
+                "InvalidRefreshToken",
+                "SessionNotFound",
+                "SessionBlocked",
+                "SessionUserMismatch",
+                "SessionTokenMismatch",
+                "SessionExpired",
+                "RequestTooLarge",
+                "Timeout",
+                "Unavailable",
+                "Internal"
+            ]
+        },
+        "apperr.FieldViolation": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "field": {
+                    "type": "string"
+                }
+            }
+        },
+        "apperr.Problem": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/apperr.Code"
+                        }
+                    ],
+                    "example": "INSUFFICIENT_FUNDS"
+                },
+                "detail": {
+                    "type": "string",
+                    "example": "insufficient balance"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/apperr.FieldViolation"
+                    }
+                },
+                "instance": {
+                    "type": "string",
+                    "example": "/api/v1/transfers"
+                },
+                "metadata": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "integer",
+                    "example": 400
+                },
+                "title": {
+                    "type": "string",
+                    "example": "Insufficient funds"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "urn:simplebank:error:INSUFFICIENT_FUNDS"
+                }
+            }
+        },
         "util.Response": {
             "type": "object",
             "properties": {
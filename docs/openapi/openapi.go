@@ -0,0 +1,7 @@
+// Package openapi embeds the OpenAPI document generated from the protos by protoc-gen-openapiv2.
+package openapi
+
+import _ "embed"
+
+//go:embed simple_bank.swagger.json
+var Spec []byte
@@ -0,0 +1,185 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/S-Devoe/golang-simple-bank/apperr"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/pb"
+	"github.com/S-Devoe/golang-simple-bank/token"
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+)
+
+func (s *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	currency, err := s.store.GetCurrency(ctx, req.GetCurrency())
+	if err != nil && err != db.ErrRecordNotFound {
+		return nil, internalError(ctx, fmt.Errorf("cannot fetch currency: %w", err))
+	}
+	if err == db.ErrRecordNotFound || !currency.Enabled {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("currency", "unsupported currency")})
+	}
+
+	account, err := s.store.CreateAccount(ctx, db.CreateAccountParams{
+		Owner:    authPayload.Username,
+		Currency: currency.Code,
+		Balance:  0,
+	})
+	if err != nil {
+		if db.ErrorCode(err) == db.UniqueViolation {
+			return nil, errorWithInfo(apperr.AccountAlreadyExists, fmt.Sprintf("account in %s already exists", currency.Code), nil)
+		}
+		return nil, internalError(ctx, fmt.Errorf("cannot create account: %w", err))
+	}
+
+	return &pb.CreateAccountResponse{Account: convertAccount(account)}, nil
+}
+
+func (s *Server) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := s.ownedAccount(ctx, authPayload, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return &pb.GetAccountResponse{Account: convertAccount(account)}, nil
+}
+
+func (s *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// accounts are listed oldest first, so the first page starts after id 0
+	pageSize, cursor, err := parsePageRequest(req.GetPageSize(), req.GetPageToken(), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := s.store.ListAccounts(ctx, db.ListAccountsParams{
+		Owner:  authPayload.Username,
+		Cursor: cursor,
+		Limit:  pageSize + 1,
+	})
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("cannot list accounts: %w", err))
+	}
+
+	resp := &pb.ListAccountsResponse{}
+	for i, account := range accounts {
+		if i == int(pageSize) {
+			resp.NextPageToken = nextPageToken(accounts[i-1].ID)
+			break
+		}
+		resp.Accounts = append(resp.Accounts, convertAccount(account))
+	}
+	return resp, nil
+}
+
+func (s *Server) GetAccountEntries(ctx context.Context, req *pb.GetAccountEntriesRequest) (*pb.GetAccountEntriesResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// entries are listed newest first, so the first page starts below every id
+	pageSize, cursor, err := parsePageRequest(req.GetPageSize(), req.GetPageToken(), math.MaxInt64)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := s.ownedAccount(ctx, authPayload, req.GetAccountId())
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.store.ListAccountEntries(ctx, db.ListAccountEntriesParams{
+		AccountID: account.ID,
+		Cursor:    cursor,
+		Limit:     pageSize + 1,
+	})
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("cannot list entries: %w", err))
+	}
+
+	resp := &pb.GetAccountEntriesResponse{}
+	for i, entry := range entries {
+		if i == int(pageSize) {
+			resp.NextPageToken = nextPageToken(entries[i-1].ID)
+			break
+		}
+		resp.Entries = append(resp.Entries, convertEntry(entry))
+	}
+	return resp, nil
+}
+
+// ownedAccount fetches an account and checks it belongs to the caller, like api.ownedAccount
+func (s *Server) ownedAccount(ctx context.Context, authPayload *token.Payload, id int64) (db.Account, error) {
+	if id <= 0 {
+		return db.Account{}, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("account_id", "must be positive")})
+	}
+
+	account, err := s.store.GetAccount(ctx, id)
+	if err != nil {
+		if err == db.ErrRecordNotFound {
+			return account, errorWithInfo(apperr.AccountNotFound, "account not found", nil)
+		}
+		return account, internalError(ctx, fmt.Errorf("cannot fetch account: %w", err))
+	}
+
+	if account.Owner != authPayload.Username {
+		return account, errorWithInfo(apperr.AccountNotOwned, "account does not belong to the authenticated user", nil)
+	}
+	return account, nil
+}
+
+// parsePageRequest applies the default page size and decodes the page token into an id cursor, or first on the first page
+func parsePageRequest(pageSize int32, pageToken string, first int64) (int32, int64, error) {
+	pageSize, key, err := parsePageToken(pageSize, pageToken, strconv.FormatInt(first, 10))
+	if err != nil {
+		return 0, 0, err
+	}
+	cursor, err := strconv.ParseInt(key, 10, 64)
+	if err != nil {
+		return 0, 0, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("page_token", "is invalid")})
+	}
+	return pageSize, cursor, nil
+}
+
+// parsePageToken applies the default page size and decodes pageToken into the key to continue after, first when it is empty
+func parsePageToken(pageSize int32, pageToken string, first string) (int32, string, error) {
+	if pageSize == 0 {
+		pageSize = util.DefaultLimit
+	}
+	if pageSize < 0 || pageSize > util.MaxLimit {
+		return 0, "", invalidArgumentError([]*errdetails.BadRequest_FieldViolation{
+			fieldViolation("page_size", fmt.Sprintf("must be between 1 and %d", util.MaxLimit)),
+		})
+	}
+	if pageToken == "" {
+		return pageSize, first, nil
+	}
+
+	key, _, err := util.DecodeCursor(pageToken)
+	if err != nil {
+		return 0, "", invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("page_token", "is invalid")})
+	}
+	return pageSize, key, nil
+}
+
+// nextPageToken points the next page after the last id returned
+func nextPageToken(lastID int64) string {
+	return util.EncodeCursor(strconv.FormatInt(lastID, 10), false)
+}
@@ -0,0 +1,229 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/S-Devoe/golang-simple-bank/db/mock"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/pb"
+	"github.com/S-Devoe/golang-simple-bank/token"
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestCreateAccountRPC(t *testing.T) {
+	user := randomUser()
+	account := randomAccount(user.Username)
+	account.Balance = 0
+
+	testCases := []struct {
+		name          string
+		req           *pb.CreateAccountRequest
+		buildStubs    func(store *mockdb.MockStore)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, res *pb.CreateAccountResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.CreateAccountRequest{Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCurrency(gomock.Any(), gomock.Eq(util.USD)).Times(1).
+					Return(db.Currency{Code: util.USD, Enabled: true}, nil)
+				arg := db.CreateAccountParams{Owner: user.Username, Currency: util.USD, Balance: 0}
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Eq(arg)).Times(1).Return(account, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account.ID, res.GetAccount().GetId())
+				require.Equal(t, user.Username, res.GetAccount().GetOwner())
+			},
+		},
+		{
+			name: "DisabledCurrency",
+			req:  &pb.CreateAccountRequest{Currency: "GBP"},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCurrency(gomock.Any(), gomock.Eq("GBP")).Times(1).
+					Return(db.Currency{Code: "GBP", Enabled: false}, nil)
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "AlreadyExists",
+			req:  &pb.CreateAccountRequest{Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCurrency(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.Currency{Code: util.USD, Enabled: true}, nil)
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(1).Return(db.Account{}, db.ErrUniqueViolation)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newContextWithBearerToken(t, tokenMaker, user.Username, time.Minute)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				require.Equal(t, codes.AlreadyExists, status.Code(err))
+			},
+		},
+		{
+			name: "NoAuthorization",
+			req:  &pb.CreateAccountRequest{Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateAccountResponse, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := tc.buildContext(t, server.tokenMaker)
+			res, err := server.CreateAccount(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestGetAccountRPC(t *testing.T) {
+	user := randomUser()
+	other := randomUser()
+	account := randomAccount(user.Username)
+
+	testCases := []struct {
+		name          string
+		username      string
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.GetAccountResponse, err error)
+	}{
+		{
+			name:     "OK",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account.Balance, res.GetAccount().GetBalance())
+			},
+		},
+		{
+			name:     "NotOwner",
+			username: other.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name:     "NotFound",
+			username: user.Username,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(db.Account{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				require.Equal(t, codes.NotFound, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, tc.username, time.Minute)
+			res, err := server.GetAccount(ctx, &pb.GetAccountRequest{Id: account.ID})
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestListAccountsRPC(t *testing.T) {
+	user := randomUser()
+	accounts := []db.Account{
+		{ID: 1, Owner: user.Username, Currency: util.USD},
+		{ID: 2, Owner: user.Username, Currency: util.EUR},
+		{ID: 3, Owner: user.Username, Currency: util.CAD},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username, time.Minute)
+
+	// the first page fetches one extra account to know there is another page
+	first := db.ListAccountsParams{Owner: user.Username, Cursor: 0, Limit: 3}
+	store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(first)).Times(1).Return(accounts, nil)
+	res, err := server.ListAccounts(ctx, &pb.ListAccountsRequest{PageSize: 2})
+	require.NoError(t, err)
+	require.Len(t, res.GetAccounts(), 2)
+	require.NotEmpty(t, res.GetNextPageToken())
+
+	second := db.ListAccountsParams{Owner: user.Username, Cursor: 2, Limit: 3}
+	store.EXPECT().ListAccounts(gomock.Any(), gomock.Eq(second)).Times(1).Return(accounts[2:], nil)
+	res, err = server.ListAccounts(ctx, &pb.ListAccountsRequest{PageSize: 2, PageToken: res.GetNextPageToken()})
+	require.NoError(t, err)
+	require.Len(t, res.GetAccounts(), 1)
+	require.Empty(t, res.GetNextPageToken())
+
+	_, err = server.ListAccounts(ctx, &pb.ListAccountsRequest{PageToken: "garbage"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGetAccountEntriesRPC(t *testing.T) {
+	user := randomUser()
+	account := randomAccount(user.Username)
+	entries := []db.ListAccountEntriesRow{
+		{ID: 9, AccountID: account.ID, Amount: -10, Memo: "rent"},
+		{ID: 7, AccountID: account.ID, Amount: 25},
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	arg := db.ListAccountEntriesParams{AccountID: account.ID, Cursor: 1<<63 - 1, Limit: util.DefaultLimit + 1}
+	store.EXPECT().ListAccountEntries(gomock.Any(), gomock.Eq(arg)).Times(1).Return(entries, nil)
+
+	server := newTestServer(t, store)
+	ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username, time.Minute)
+	res, err := server.GetAccountEntries(ctx, &pb.GetAccountEntriesRequest{AccountId: account.ID})
+	require.NoError(t, err)
+	require.Len(t, res.GetEntries(), 2)
+	require.Equal(t, "rent", res.GetEntries()[0].GetMemo())
+	require.Empty(t, res.GetNextPageToken())
+}
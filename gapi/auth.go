@@ -2,56 +2,80 @@ package gapi
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/S-Devoe/golang-simple-bank/apperr"
 	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/logging"
+	"github.com/S-Devoe/golang-simple-bank/metrics"
 	"github.com/S-Devoe/golang-simple-bank/pb"
 	"github.com/S-Devoe/golang-simple-bank/util/password"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func (s *Server) LoginUser(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
+	// only presence is checked, so users whose names predate the username rules can still log in
+	var violations []*errdetails.BadRequest_FieldViolation
+	if req.GetUsername() == "" {
+		violations = append(violations, fieldViolation("username", "is required"))
+	}
+	if req.GetPassword() == "" {
+		violations = append(violations, fieldViolation("password", "is required"))
+	}
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+	logging.With(ctx, "username", req.GetUsername())
+
 	user, err := s.store.GetUser(ctx, req.GetUsername())
 	if err != nil {
 		if err == db.ErrRecordNotFound {
-			return nil, status.Errorf(codes.NotFound, "user not found")
+			metrics.Logins.WithLabelValues(metrics.TransportGRPC, metrics.LoginFailed).Inc()
+			return nil, errorWithInfo(apperr.UserNotFound, "user not found", nil)
 
 		}
-		return nil, status.Errorf(codes.Internal, "cannot fetch user: %v", err)
+		return nil, internalError(ctx, fmt.Errorf("cannot fetch user: %w", err))
 	}
 
 	passwordMatch, err := password.ComparePasswordAndHash(req.GetPassword(), user.HashedPassword)
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "internal error: %v", err)
+		return nil, internalError(ctx, fmt.Errorf("cannot compare password: %w", err))
 	}
 
 	if !passwordMatch {
-		return nil, status.Errorf(codes.Unauthenticated, "invalid username or password")
-	}
-
-	accessToken, accessPayload, err := s.tokenMaker.CreateToken(user.Username, user.Email, s.config.AccessTokenDuration)
-	if err != nil {
-		return nil, status.Errorf(codes.Internal, "internal server error: %v", err)
+		metrics.Logins.WithLabelValues(metrics.TransportGRPC, metrics.LoginFailed).Inc()
+		return nil, errorWithInfo(apperr.InvalidCredentials, "invalid username or password", nil)
 	}
 
 	refreshToken, refreshPayload, err := s.tokenMaker.CreateToken(
 		user.Username,
 		user.Email,
+		"",
 		s.config.RefreshTokenDuration,
 	)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("cannot create refresh token: %w", err))
+	}
+
+	// the access token is only honoured while the session it belongs to is live
+	accessToken, accessPayload, err := s.tokenMaker.CreateToken(user.Username, user.Email, refreshPayload.ID.String(), s.config.AccessTokenDuration)
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("cannot create access token: %w", err))
+	}
 
+	mtdt := s.extractMetadata(ctx)
 	session, err := s.store.CreateSession(ctx, db.CreateSessionParams{
 		ID:           refreshPayload.ID.String(),
 		Username:     user.Username,
 		RefreshToken: refreshToken,
-		UserAgent:    "",
-		ClientIp:     "",
+		UserAgent:    mtdt.UserAgent,
+		ClientIp:     mtdt.ClientIP,
 		IsBlocked:    false,
 		ExpiresAt:    refreshPayload.ExpiredAt,
 	})
 	if err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to create session: %v", err)
+		return nil, internalError(ctx, fmt.Errorf("failed to create session: %w", err))
 	}
 
 	resp := &pb.LoginResponse{
@@ -62,6 +86,7 @@ func (s *Server) LoginUser(ctx context.Context, req *pb.LoginRequest) (*pb.Login
 		SessionId:             session.ID,
 		User:                  converteUser(user),
 	}
+	metrics.Logins.WithLabelValues(metrics.TransportGRPC, metrics.LoginSucceeded).Inc()
 	return resp, nil
 
 }
@@ -0,0 +1,137 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/S-Devoe/golang-simple-bank/apperr"
+	"github.com/S-Devoe/golang-simple-bank/logging"
+	"github.com/S-Devoe/golang-simple-bank/pb"
+	"github.com/S-Devoe/golang-simple-bank/token"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionv1 "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	reflectionv1alpha "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+)
+
+const (
+	authorizationHeader = "authorization"
+	authorizationBearer = "bearer"
+)
+
+// publicMethods can be called without an access token; every other RPC requires one
+var publicMethods = map[string]bool{
+	pb.SimpleBank_CreateUser_FullMethodName: true,
+	pb.SimpleBank_LoginUser_FullMethodName:  true,
+	// the code mailed to the address is the credential for this
+	pb.SimpleBank_VerifyEmail_FullMethodName: true,
+	// the refresh token in the request is the credential for these
+	pb.SimpleBank_RenewAccessToken_FullMethodName: true,
+	pb.SimpleBank_Logout_FullMethodName:           true,
+}
+
+// publicServices registered next to SimpleBank on the same server: probes and tooling call them without a token
+var publicServices = []string{
+	healthpb.Health_ServiceDesc.ServiceName,
+	reflectionv1.ServerReflection_ServiceDesc.ServiceName,
+	reflectionv1alpha.ServerReflection_ServiceDesc.ServiceName,
+}
+
+type authPayloadKey struct{}
+
+// AuthUnaryInterceptor verifies the bearer token of non-public unary RPCs and puts its payload into the context
+func (s *Server) AuthUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// AuthStreamInterceptor is the streaming counterpart of AuthUnaryInterceptor
+func (s *Server) AuthStreamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(stream.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &wrappedStream{ServerStream: stream, ctx: ctx})
+}
+
+// wrappedStream replaces the stream context with one an interceptor added values to
+type wrappedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *wrappedStream) Context() context.Context {
+	return stream.ctx
+}
+
+func (s *Server) authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	if isPublic(fullMethod) {
+		return ctx, nil
+	}
+
+	payload, err := s.verifyAccessToken(ctx)
+	if err != nil {
+		return nil, errorWithInfo(apperr.Unauthenticated, fmt.Sprintf("unauthorized: %v", err), nil)
+	}
+	if err := s.sessions.Authorize(ctx, payload); err != nil {
+		return nil, appError(ctx, err)
+	}
+	logging.With(ctx, "username", payload.Username)
+	return context.WithValue(ctx, authPayloadKey{}, payload), nil
+}
+
+// isPublic reports whether fullMethod, "/<service>/<method>", is a public method or belongs to a public service
+func isPublic(fullMethod string) bool {
+	if publicMethods[fullMethod] {
+		return true
+	}
+	for _, service := range publicServices {
+		if strings.HasPrefix(fullMethod, "/"+service+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// verifyAccessToken checks the `authorization: bearer <token>` metadata, the gRPC counterpart of api.authMiddleware
+func (s *Server) verifyAccessToken(ctx context.Context) (*token.Payload, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, fmt.Errorf("missing metadata")
+	}
+
+	values := md.Get(authorizationHeader)
+	if len(values) == 0 {
+		return nil, fmt.Errorf("missing authorization header")
+	}
+
+	fields := strings.Fields(values[0])
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("invalid authorization header format")
+	}
+
+	authType := strings.ToLower(fields[0])
+	if authType != authorizationBearer {
+		return nil, fmt.Errorf("unsupported authorization type: %s", authType)
+	}
+
+	payload, err := s.tokenMaker.VerifyToken(fields[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid access token: %w", err)
+	}
+	return payload, nil
+}
+
+// authPayloadFromContext returns the payload AuthUnaryInterceptor stored for the caller
+func authPayloadFromContext(ctx context.Context) (*token.Payload, error) {
+	payload, ok := ctx.Value(authPayloadKey{}).(*token.Payload)
+	if !ok {
+		return nil, errorWithInfo(apperr.Unauthenticated, "unauthorized: no access token payload in context", nil)
+	}
+	return payload, nil
+}
@@ -0,0 +1,239 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/S-Devoe/golang-simple-bank/apperr"
+	mockdb "github.com/S-Devoe/golang-simple-bank/db/mock"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/pb"
+	"github.com/S-Devoe/golang-simple-bank/token"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestAuthUnaryInterceptor(t *testing.T) {
+	user := randomUser()
+
+	testCases := []struct {
+		name          string
+		method        string
+		buildStubs    func(store *mockdb.MockStore)
+		buildContext  func(t *testing.T, tokenMaker token.Maker) context.Context
+		checkResponse func(t *testing.T, payload *token.Payload, err error)
+	}{
+		{
+			name:   "OK",
+			method: pb.SimpleBank_GetAccount_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newIncomingBearerContext(t, tokenMaker, user.Username, time.Minute)
+			},
+			checkResponse: func(t *testing.T, payload *token.Payload, err error) {
+				require.NoError(t, err)
+				require.NotNil(t, payload)
+				require.Equal(t, user.Username, payload.Username)
+			},
+		},
+		{
+			name:   "PublicMethod",
+			method: pb.SimpleBank_LoginUser_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponse: func(t *testing.T, payload *token.Payload, err error) {
+				require.NoError(t, err)
+				require.Nil(t, payload)
+			},
+		},
+		{
+			name:   "NoAuthorization",
+			method: pb.SimpleBank_GetAccount_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return context.Background()
+			},
+			checkResponse: func(t *testing.T, payload *token.Payload, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:   "UnsupportedAuthorization",
+			method: pb.SimpleBank_GetAccount_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				md := metadata.Pairs(authorizationHeader, "basic abc")
+				return metadata.NewIncomingContext(context.Background(), md)
+			},
+			checkResponse: func(t *testing.T, payload *token.Payload, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			name:   "InvalidAuthorizationFormat",
+			method: pb.SimpleBank_GetAccount_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				md := metadata.Pairs(authorizationHeader, authorizationBearer)
+				return metadata.NewIncomingContext(context.Background(), md)
+			},
+			checkResponse: func(t *testing.T, payload *token.Payload, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+		{
+			// a refresh token names no session of its own and is not an access token
+			name:   "RefreshToken",
+			method: pb.SimpleBank_GetAccount_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				refreshToken, _, err := tokenMaker.CreateToken(user.Username, "", "", time.Minute)
+				require.NoError(t, err)
+				md := metadata.Pairs(authorizationHeader, fmt.Sprintf("%s %s", authorizationBearer, refreshToken))
+				return metadata.NewIncomingContext(context.Background(), md)
+			},
+			checkResponse: func(t *testing.T, payload *token.Payload, err error) {
+				requireErrorReason(t, err, codes.Unauthenticated, apperr.Unauthenticated)
+			},
+		},
+		{
+			name:   "LoggedOutSession",
+			method: pb.SimpleBank_GetAccount_FullMethodName,
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetAuthSession(gomock.Any(), gomock.Eq("ended")).Times(1).
+					Return(db.GetAuthSessionRow{Session: db.Session{ID: "ended", Username: user.Username, IsBlocked: true, ExpiresAt: time.Now().Add(time.Hour)}}, nil)
+			},
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				accessToken, _, err := tokenMaker.CreateToken(user.Username, "", "ended", time.Minute)
+				require.NoError(t, err)
+				md := metadata.Pairs(authorizationHeader, fmt.Sprintf("%s %s", authorizationBearer, accessToken))
+				return metadata.NewIncomingContext(context.Background(), md)
+			},
+			checkResponse: func(t *testing.T, payload *token.Payload, err error) {
+				requireErrorReason(t, err, codes.Unauthenticated, apperr.Unauthenticated)
+			},
+		},
+		{
+			name:   "ExpiredToken",
+			method: pb.SimpleBank_GetAccount_FullMethodName,
+			buildContext: func(t *testing.T, tokenMaker token.Maker) context.Context {
+				return newIncomingBearerContext(t, tokenMaker, user.Username, -time.Minute)
+			},
+			checkResponse: func(t *testing.T, payload *token.Payload, err error) {
+				require.Equal(t, codes.Unauthenticated, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			store := mockdb.NewMockStore(gomock.NewController(t))
+			if tc.buildStubs != nil {
+				tc.buildStubs(store)
+			}
+			server := newTestServer(t, store)
+			ctx := tc.buildContext(t, server.tokenMaker)
+
+			var payload *token.Payload
+			handler := func(ctx context.Context, req any) (any, error) {
+				payload, _ = ctx.Value(authPayloadKey{}).(*token.Payload)
+				return nil, nil
+			}
+			_, err := server.AuthUnaryInterceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: tc.method}, handler)
+			tc.checkResponse(t, payload, err)
+		})
+	}
+}
+
+type mockServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (stream *mockServerStream) Context() context.Context {
+	return stream.ctx
+}
+
+func TestAuthStreamInterceptor(t *testing.T) {
+	server := newTestServer(t, mockdb.NewMockStore(gomock.NewController(t)))
+	user := randomUser()
+	info := &grpc.StreamServerInfo{FullMethod: "/pb.SimpleBank/WatchSomething"}
+
+	var payload *token.Payload
+	handler := func(srv any, stream grpc.ServerStream) error {
+		var err error
+		payload, err = authPayloadFromContext(stream.Context())
+		return err
+	}
+
+	stream := &mockServerStream{ctx: newIncomingBearerContext(t, server.tokenMaker, user.Username, time.Minute)}
+	err := server.AuthStreamInterceptor(nil, stream, info, handler)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, payload.Username)
+
+	stream = &mockServerStream{ctx: context.Background()}
+	err = server.AuthStreamInterceptor(nil, stream, info, handler)
+	require.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+// TestAuthInterceptorChain serves the services main registers through the full interceptor chain:
+// probes and reflection get through without a token, SimpleBank RPCs don't
+func TestAuthInterceptorChain(t *testing.T) {
+	server := newTestServer(t, mockdb.NewMockStore(gomock.NewController(t)))
+
+	grpcServer := grpc.NewServer(server.ServerOptions()...)
+	pb.RegisterSimpleBankServer(grpcServer, server)
+	healthpb.RegisterHealthServer(grpcServer, health.NewServer())
+	reflection.Register(grpcServer)
+
+	listener := bufconn.Listen(1 << 20)
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///chain",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	ctx := context.Background()
+
+	res, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, res.Status)
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	require.NoError(t, err)
+	err = stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	})
+	require.NoError(t, err)
+	reflectionRes, err := stream.Recv()
+	require.NoError(t, err)
+	require.NotNil(t, reflectionRes.GetListServicesResponse())
+	require.NoError(t, stream.CloseSend())
+
+	_, err = pb.NewSimpleBankClient(conn).GetAccount(ctx, &pb.GetAccountRequest{Id: 1})
+	requireErrorReason(t, err, codes.Unauthenticated, apperr.Unauthenticated)
+}
+
+// newIncomingBearerContext returns an incoming context carrying only the authorization metadata, as a client would send it
+func newIncomingBearerContext(t *testing.T, tokenMaker token.Maker, username string, duration time.Duration) context.Context {
+	accessToken, _, err := tokenMaker.CreateToken(username, "", testSessionID(username), duration)
+	require.NoError(t, err)
+
+	md := metadata.Pairs(authorizationHeader, fmt.Sprintf("%s %s", authorizationBearer, accessToken))
+	return metadata.NewIncomingContext(context.Background(), md)
+}
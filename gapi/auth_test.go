@@ -0,0 +1,54 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+
+	mockdb "github.com/S-Devoe/golang-simple-bank/db/mock"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/pb"
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/S-Devoe/golang-simple-bank/util/password"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestLoginUserRPC(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := randomUser()
+	userPassword := util.GenerateRandomString(8)
+	hashedPassword, err := password.GeneratePasswordHash(userPassword)
+	require.NoError(t, err)
+	user.HashedPassword = hashedPassword
+
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetUser(gomock.Any(), gomock.Eq(user.Username)).Times(1).Return(user, nil)
+	store.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Times(1).
+		DoAndReturn(func(ctx context.Context, arg db.CreateSessionParams) (db.Session, error) {
+			// the session records the client the same way the REST login does
+			require.Equal(t, "Mozilla/5.0", arg.UserAgent)
+			require.Equal(t, "203.0.113.9", arg.ClientIp)
+			return db.Session{ID: arg.ID, Username: arg.Username}, nil
+		})
+
+	server := newTestServer(t, store)
+	ctx := peer.NewContext(metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		grpcGatewayUserAgentHeader, "Mozilla/5.0",
+		xForwardedForHeader, "203.0.113.9",
+	)), gatewayPeer)
+
+	res, err := server.LoginUser(ctx, &pb.LoginRequest{Username: user.Username, Password: userPassword})
+	require.NoError(t, err)
+	require.NotEmpty(t, res.GetAccessToken())
+	require.NotEmpty(t, res.GetRefreshToken())
+	require.NotEmpty(t, res.GetSessionId())
+
+	// the access token names the session it belongs to, so logging the session out ends it too
+	payload, err := server.tokenMaker.VerifyToken(res.GetAccessToken())
+	require.NoError(t, err)
+	require.Equal(t, res.GetSessionId(), payload.SessionID)
+}
@@ -13,5 +13,53 @@ func converteUser(user db.User) *pb.User {
 		Email:             user.Email,
 		CreatedAt:         timestamppb.New(user.CreatedAt),
 		PasswordChangedAt: timestamppb.New(user.PasswordChangedAt),
+		IsEmailVerified:   user.IsEmailVerified,
+	}
+}
+
+func convertAccount(account db.Account) *pb.Account {
+	return &pb.Account{
+		Id:        account.ID,
+		Owner:     account.Owner,
+		Balance:   account.Balance,
+		Currency:  account.Currency,
+		CreatedAt: timestamppb.New(account.CreatedAt),
+	}
+}
+
+func convertEntry(entry db.ListAccountEntriesRow) *pb.Entry {
+	return &pb.Entry{
+		Id:         entry.ID,
+		AccountId:  entry.AccountID,
+		Amount:     entry.Amount,
+		TransferId: entry.TransferID.Int64,
+		Memo:       entry.Memo,
+		Reference:  entry.Reference,
+		EndToEndId: entry.EndToEndID,
+		CreatedAt:  timestamppb.New(entry.CreatedAt),
+	}
+}
+
+func convertTransfer(transfer db.Transfer) *pb.Transfer {
+	return &pb.Transfer{
+		Id:            transfer.ID,
+		FromAccountId: transfer.FromAccountID,
+		ToAccountId:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		Memo:          transfer.Memo,
+		Reference:     transfer.Reference,
+		EndToEndId:    transfer.EndToEndID,
+		CreatedAt:     timestamppb.New(transfer.CreatedAt),
+	}
+}
+
+func convertSession(session db.Session) *pb.Session {
+	return &pb.Session{
+		Id:        session.ID,
+		UserAgent: session.UserAgent,
+		ClientIp:  session.ClientIp,
+		IsBlocked: session.IsBlocked,
+		ExpiresAt: timestamppb.New(session.ExpiresAt),
+		CreatedAt: timestamppb.New(session.CreatedAt),
 	}
 }
@@ -0,0 +1,71 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+
+	"github.com/S-Devoe/golang-simple-bank/apperr"
+	"github.com/S-Devoe/golang-simple-bank/logging"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain scopes the apperr codes sent as ErrorInfo reasons
+const errorDomain = "simplebank"
+
+// errorWithInfo builds a status error carrying code as the reason of an ErrorInfo detail
+func errorWithInfo(code apperr.Code, message string, metadata map[string]string) error {
+	st := status.New(code.GRPCCode(), message)
+	detailed, err := st.WithDetails(&errdetails.ErrorInfo{
+		Reason:   string(code),
+		Domain:   errorDomain,
+		Metadata: metadata,
+	})
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// appError converts an apperr error into its status error, anything else is internal
+func appError(ctx context.Context, err error) error {
+	var appErr *apperr.Error
+	if !errors.As(err, &appErr) {
+		return internalError(ctx, err)
+	}
+	if appErr.Code == apperr.InvalidArgument && len(appErr.Fields) > 0 {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(appErr.Fields))
+		for _, field := range appErr.Fields {
+			violations = append(violations, fieldViolation(field.Field, field.Description))
+		}
+		return invalidArgumentError(violations)
+	}
+	return errorWithInfo(appErr.Code, appErr.Message, appErr.Metadata)
+}
+
+// internalError hides err from the caller behind a bare Internal status, and hands it to the request log instead
+func internalError(ctx context.Context, err error) error {
+	logging.With(ctx, "error", err.Error())
+	return errorWithInfo(apperr.Internal, "internal server error", nil)
+}
+
+func fieldViolation(field string, description string) *errdetails.BadRequest_FieldViolation {
+	return &errdetails.BadRequest_FieldViolation{
+		Field:       field,
+		Description: description,
+	}
+}
+
+// invalidArgumentError builds an InvalidArgument status error carrying a BadRequest detail
+func invalidArgumentError(violations []*errdetails.BadRequest_FieldViolation) error {
+	st := status.New(codes.InvalidArgument, "invalid parameters")
+	detailed, err := st.WithDetails(
+		&errdetails.ErrorInfo{Reason: string(apperr.InvalidArgument), Domain: errorDomain},
+		&errdetails.BadRequest{FieldViolations: violations},
+	)
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
@@ -0,0 +1,29 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/S-Devoe/golang-simple-bank/apperr"
+	"github.com/S-Devoe/golang-simple-bank/session"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestAppError(t *testing.T) {
+	ctx := context.Background()
+
+	err := appError(ctx, session.ErrSessionExpired)
+	requireErrorReason(t, err, codes.Unauthenticated, apperr.SessionExpired)
+
+	err = appError(ctx, apperr.Invalid(apperr.Field("amount", "must be positive")))
+	requireFieldViolations(t, err, "amount")
+	requireErrorReason(t, err, codes.InvalidArgument, apperr.InvalidArgument)
+
+	// errors that don't come from apperr are internal, and their message stays out of the status
+	err = appError(ctx, errors.New(`relation "sessions" does not exist`))
+	requireErrorReason(t, err, codes.Internal, apperr.Internal)
+	require.Equal(t, "internal server error", status.Convert(err).Message())
+}
@@ -0,0 +1,85 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/S-Devoe/golang-simple-bank/logging"
+	"github.com/S-Devoe/golang-simple-bank/pb"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const gatewayBufferSize = 1 << 20
+
+// W3C trace context headers, the gateway passes them on so the gRPC span continues the HTTP caller's trace
+const (
+	traceparentHeader = "traceparent"
+	tracestateHeader  = "tracestate"
+)
+
+// NewGatewayHandler serves the SimpleBank RPCs as JSON over HTTP, so the REST routes under /v1 share one implementation with the gRPC transport.
+// The gateway reaches grpcServer through an in-memory listener rather than calling the service directly,
+// so its requests pass through the same interceptors as native gRPC calls.
+// Stopping grpcServer also stops the in-memory listener.
+func NewGatewayHandler(ctx context.Context, grpcServer *grpc.Server) (http.Handler, error) {
+	listener := bufconn.Listen(gatewayBufferSize)
+	go func() {
+		if err := grpcServer.Serve(listener); err != nil && err != grpc.ErrServerStopped {
+			slog.Error("gateway gRPC listener stopped", "error", err)
+		}
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///gateway",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect gateway to gRPC server: %w", err)
+	}
+
+	jsonOption := runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
+		MarshalOptions: protojson.MarshalOptions{
+			UseProtoNames: true,
+		},
+		UnmarshalOptions: protojson.UnmarshalOptions{
+			DiscardUnknown: true,
+		},
+	})
+
+	grpcMux := runtime.NewServeMux(jsonOption,
+		runtime.WithIncomingHeaderMatcher(gatewayHeaderMatcher),
+		runtime.WithOutgoingHeaderMatcher(gatewayOutgoingHeaderMatcher),
+	)
+	if err := pb.RegisterSimpleBankHandler(ctx, grpcMux, conn); err != nil {
+		return nil, fmt.Errorf("cannot register gateway handler: %w", err)
+	}
+	return grpcMux, nil
+}
+
+// gatewayHeaderMatcher forwards the Idempotency-Key, X-Request-ID and W3C trace context headers as metadata on top of the default headers
+func gatewayHeaderMatcher(key string) (string, bool) {
+	for _, header := range []string{idempotencyKeyHeader, requestIDHeader, traceparentHeader, tracestateHeader} {
+		if strings.EqualFold(key, header) {
+			return header, true
+		}
+	}
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// gatewayOutgoingHeaderMatcher returns the request id as a plain X-Request-ID header, other metadata keeps the Grpc-Metadata- prefix
+func gatewayOutgoingHeaderMatcher(key string) (string, bool) {
+	if key == requestIDHeader {
+		return logging.RequestIDHeader, true
+	}
+	return runtime.MetadataHeaderPrefix + key, true
+}
@@ -0,0 +1,86 @@
+package gapi
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/S-Devoe/golang-simple-bank/logging"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// requestIDHeader is logging.RequestIDHeader as gRPC metadata keys are lower case
+var requestIDHeader = strings.ToLower(logging.RequestIDHeader)
+
+// serverErrorCodes are the failures logged as errors; the rest are the client's doing
+var serverErrorCodes = map[codes.Code]bool{
+	codes.Unknown:       true,
+	codes.Internal:      true,
+	codes.DataLoss:      true,
+	codes.Unimplemented: true,
+}
+
+// LoggingUnaryInterceptor tags every RPC with an x-request-id, keeping the caller's when it sent a usable one,
+// carries a logger holding that id through the context and logs the outcome once the handler returns.
+// Requests and responses are never logged, as they hold passwords and tokens.
+func (s *Server) LoggingUnaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	ctx, header := s.startRequestLog(ctx, info.FullMethod)
+	grpc.SetHeader(ctx, header)
+
+	res, err := handler(ctx, req)
+	logRPC(ctx, start, err)
+	return res, err
+}
+
+// LoggingStreamInterceptor is the streaming counterpart of LoggingUnaryInterceptor
+func (s *Server) LoggingStreamInterceptor(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	ctx, header := s.startRequestLog(stream.Context(), info.FullMethod)
+	stream.SetHeader(header)
+
+	err := handler(srv, &wrappedStream{ServerStream: stream, ctx: ctx})
+	logRPC(ctx, start, err)
+	return err
+}
+
+// startRequestLog returns ctx carrying the request logger, and the header echoing the request id back
+func (s *Server) startRequestLog(ctx context.Context, fullMethod string) (context.Context, metadata.MD) {
+	var requestID string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(requestIDHeader); len(ids) > 0 {
+			requestID = ids[0]
+		}
+	}
+	requestID = logging.RequestID(requestID)
+
+	logger := slog.Default().With(
+		"request_id", requestID,
+		"method", fullMethod,
+		"client_ip", s.extractMetadata(ctx).ClientIP,
+	)
+	if span := trace.SpanContextFromContext(ctx); span.HasTraceID() {
+		logger = logger.With("trace_id", span.TraceID().String())
+	}
+	return logging.NewContext(ctx, logger), metadata.Pairs(requestIDHeader, requestID)
+}
+
+func logRPC(ctx context.Context, start time.Time, err error) {
+	st := status.Convert(err)
+	attrs := []any{
+		"code", st.Code().String(),
+		"duration", time.Since(start),
+	}
+	logger := logging.FromContext(ctx)
+	if serverErrorCodes[st.Code()] {
+		// internalError has put the cause on the logger already, the message the caller got is kept next to it
+		logger.Error("rpc failed", append(attrs, "status_message", st.Message())...)
+		return
+	}
+	logger.Info("rpc completed", attrs...)
+}
@@ -0,0 +1,70 @@
+package gapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	mockdb "github.com/S-Devoe/golang-simple-bank/db/mock"
+	"github.com/S-Devoe/golang-simple-bank/logging"
+	"github.com/S-Devoe/golang-simple-bank/pb"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestLoggingUnaryInterceptor(t *testing.T) {
+	var logs bytes.Buffer
+	previous := slog.Default()
+	slog.SetDefault(logging.New(&logs, "json", slog.LevelInfo))
+	defer slog.SetDefault(previous)
+
+	user := randomUser()
+	server := newTestServer(t, mockdb.NewMockStore(gomock.NewController(t)))
+	ctx := newIncomingBearerContext(t, server.tokenMaker, user.Username, time.Minute)
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = metadata.NewIncomingContext(ctx, metadata.Join(md, metadata.Pairs(requestIDHeader, "client-request-1")))
+
+	// the same chain ServerOptions sets up: logging wraps auth
+	info := &grpc.UnaryServerInfo{FullMethod: pb.SimpleBank_GetAccount_FullMethodName}
+	call := func(code codes.Code, handle func(ctx context.Context) error) map[string]any {
+		logs.Reset()
+		_, err := server.LoggingUnaryInterceptor(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+			return server.AuthUnaryInterceptor(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+				return nil, handle(ctx)
+			})
+		})
+		require.Equal(t, code, status.Code(err))
+
+		var line map[string]any
+		require.NoError(t, json.Unmarshal(logs.Bytes(), &line))
+		return line
+	}
+
+	line := call(codes.Internal, func(ctx context.Context) error {
+		return internalError(ctx, errors.New("cannot fetch account: connection reset"))
+	})
+	require.Equal(t, "ERROR", line["level"])
+	require.Equal(t, "client-request-1", line["request_id"])
+	require.Equal(t, pb.SimpleBank_GetAccount_FullMethodName, line["method"])
+	require.Equal(t, user.Username, line["username"])
+	require.Equal(t, codes.Internal.String(), line["code"])
+	require.Equal(t, "cannot fetch account: connection reset", line["error"])
+	require.Equal(t, "internal server error", line["status_message"])
+
+	// client errors are logged without their message
+	line = call(codes.NotFound, func(ctx context.Context) error {
+		return status.Errorf(codes.NotFound, "account not found")
+	})
+	require.Equal(t, "INFO", line["level"])
+	require.Equal(t, codes.NotFound.String(), line["code"])
+	require.Equal(t, user.Username, line["username"])
+	require.NotContains(t, line, "error")
+}
@@ -0,0 +1,85 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/S-Devoe/golang-simple-bank/config"
+	mockdb "github.com/S-Devoe/golang-simple-bank/db/mock"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/token"
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/metadata"
+)
+
+func newTestServer(t *testing.T, store db.Store) *Server {
+	config := config.Config{
+		TokenSymmetricKey:    util.GenerateRandomString(32),
+		AccessTokenDuration:  time.Minute,
+		RefreshTokenDuration: time.Hour,
+	}
+
+	server, err := NewServer(config, store, nil)
+	require.NoError(t, err)
+	if store, ok := store.(*mockdb.MockStore); ok {
+		expectLiveSessions(store)
+	}
+
+	return server
+}
+
+// testSessionID names the session the access tokens of the tests are issued for
+func testSessionID(username string) string {
+	return "session-of-" + username
+}
+
+// expectLiveSessions answers the session lookups the auth interceptor makes for the tokens of the tests,
+// leaving the lookups of other session ids to the stubs of each test
+func expectLiveSessions(store *mockdb.MockStore) {
+	testSession := gomock.Cond(func(id string) bool {
+		return strings.HasPrefix(id, "session-of-")
+	})
+	store.EXPECT().GetAuthSession(gomock.Any(), testSession).AnyTimes().
+		DoAndReturn(func(_ context.Context, id string) (db.GetAuthSessionRow, error) {
+			return db.GetAuthSessionRow{Session: db.Session{
+				ID:        id,
+				Username:  strings.TrimPrefix(id, "session-of-"),
+				ExpiresAt: time.Now().Add(time.Hour),
+			}}, nil
+		})
+}
+
+// newContextWithBearerToken returns an incoming context carrying an access token for username,
+// along with the payload the auth interceptor would have stored for it
+func newContextWithBearerToken(t *testing.T, tokenMaker token.Maker, username string, duration time.Duration) context.Context {
+	accessToken, payload, err := tokenMaker.CreateToken(username, "", testSessionID(username), duration)
+	require.NoError(t, err)
+
+	md := metadata.MD{
+		authorizationHeader: []string{fmt.Sprintf("%s %s", authorizationBearer, accessToken)},
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+	return context.WithValue(ctx, authPayloadKey{}, payload)
+}
+
+func randomUser() db.User {
+	return db.User{
+		Username: util.GenerateRandomString(10),
+		FullName: util.GenerateRandomName(),
+		Email:    util.GenerateRandomEmail(),
+	}
+}
+
+func randomAccount(owner string) db.Account {
+	return db.Account{
+		ID:       util.GenerateRandomInt(1, 1000),
+		Owner:    owner,
+		Balance:  util.RandomMoney(),
+		Currency: util.USD,
+	}
+}
@@ -0,0 +1,87 @@
+package gapi
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+const (
+	// grpc-gateway forwards the caller's User-Agent under this key, its own client sets user-agent
+	grpcGatewayUserAgentHeader = "grpcgateway-user-agent"
+	userAgentHeader            = "user-agent"
+	// every proxy appends the address it got the request from to this list, grpc-gateway included
+	xForwardedForHeader = "x-forwarded-for"
+	// the network of the in-memory listener the gateway reaches the gRPC server through
+	gatewayNetwork = "bufconn"
+)
+
+// Metadata is what we record about the client behind a request, the gRPC counterpart of gin's UserAgent and ClientIP
+type Metadata struct {
+	UserAgent string
+	ClientIP  string
+}
+
+func (s *Server) extractMetadata(ctx context.Context) *Metadata {
+	mtdt := &Metadata{}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	if userAgents := md.Get(grpcGatewayUserAgentHeader); len(userAgents) > 0 {
+		mtdt.UserAgent = userAgents[0]
+	} else if userAgents := md.Get(userAgentHeader); len(userAgents) > 0 {
+		mtdt.UserAgent = userAgents[0]
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		mtdt.ClientIP = s.clientIP(p.Addr, md.Get(xForwardedForHeader))
+	}
+	return mtdt
+}
+
+// clientIP is the peer address, unless the peer is the gateway or a trusted proxy. Then it is the right-most
+// x-forwarded-for hop that isn't a trusted proxy: the hops left of it were written by the client and can be anything.
+func (s *Server) clientIP(peerAddr net.Addr, forwardedFor []string) string {
+	var client netip.Addr
+	if peerAddr.Network() != gatewayNetwork {
+		addrPort, err := netip.ParseAddrPort(peerAddr.String())
+		if err != nil {
+			return peerAddr.String()
+		}
+		client = addrPort.Addr().Unmap()
+		if !s.trustedProxy(client) {
+			return client.String()
+		}
+	}
+
+	var hops []string
+	for _, value := range forwardedFor {
+		hops = append(hops, strings.Split(value, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = hop.Unmap()
+		if !s.trustedProxy(client) {
+			break
+		}
+	}
+	if !client.IsValid() {
+		return ""
+	}
+	return client.String()
+}
+
+func (s *Server) trustedProxy(addr netip.Addr) bool {
+	for _, prefix := range s.trustedProxies {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package gapi
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// gatewayAddr is the address the gateway's calls come from, the in-memory listener it dials
+type gatewayAddr struct{}
+
+func (gatewayAddr) Network() string { return gatewayNetwork }
+func (gatewayAddr) String() string  { return gatewayNetwork }
+
+var gatewayPeer = &peer.Peer{Addr: gatewayAddr{}}
+
+func TestExtractMetadata(t *testing.T) {
+	proxyPeer := &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.7"), Port: 51234}}
+	clientPeer := &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("198.51.100.4"), Port: 51234}}
+
+	testCases := []struct {
+		name     string
+		ctx      context.Context
+		expected Metadata
+	}{
+		{
+			name: "Gateway",
+			ctx: peer.NewContext(metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+				grpcGatewayUserAgentHeader, "Mozilla/5.0",
+				userAgentHeader, "grpc-go/1.70.0",
+				xForwardedForHeader, "203.0.113.9",
+			)), gatewayPeer),
+			expected: Metadata{UserAgent: "Mozilla/5.0", ClientIP: "203.0.113.9"},
+		},
+		{
+			name: "GatewayBehindTrustedProxy",
+			ctx: peer.NewContext(metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+				grpcGatewayUserAgentHeader, "Mozilla/5.0",
+				xForwardedForHeader, "192.0.2.66, 203.0.113.9",
+				xForwardedForHeader, "10.0.0.1",
+			)), gatewayPeer),
+			// the left-most entry came from the client and is ignored
+			expected: Metadata{UserAgent: "Mozilla/5.0", ClientIP: "203.0.113.9"},
+		},
+		{
+			name: "NativeClientBehindTrustedProxy",
+			ctx: peer.NewContext(metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+				userAgentHeader, "simplebank-ios/2.1 grpc-go/1.70.0",
+				xForwardedForHeader, "198.51.100.4",
+			)), proxyPeer),
+			expected: Metadata{UserAgent: "simplebank-ios/2.1 grpc-go/1.70.0", ClientIP: "198.51.100.4"},
+		},
+		{
+			name: "ForwardedForFromUntrustedPeer",
+			ctx: peer.NewContext(metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+				userAgentHeader, "grpcurl/1.9",
+				xForwardedForHeader, "192.0.2.66",
+			)), clientPeer),
+			expected: Metadata{UserAgent: "grpcurl/1.9", ClientIP: "198.51.100.4"},
+		},
+		{
+			name: "TrustedPeerFallback",
+			ctx: peer.NewContext(metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+				userAgentHeader, "grpcurl/1.9",
+			)), proxyPeer),
+			expected: Metadata{UserAgent: "grpcurl/1.9", ClientIP: "10.0.0.7"},
+		},
+		{
+			name:     "Nothing",
+			ctx:      context.Background(),
+			expected: Metadata{},
+		},
+	}
+
+	server := newTestServer(t, nil)
+	server.trustedProxies = []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, *server.extractMetadata(tc.ctx))
+		})
+	}
+}
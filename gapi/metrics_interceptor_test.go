@@ -0,0 +1,36 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/S-Devoe/golang-simple-bank/metrics"
+	"github.com/S-Devoe/golang-simple-bank/pb"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestMetricsUnaryInterceptor(t *testing.T) {
+	server := newTestServer(t, nil)
+	info := &grpc.UnaryServerInfo{FullMethod: pb.SimpleBank_GetAccount_FullMethodName}
+
+	notFound := metrics.GRPCRequests.WithLabelValues(info.FullMethod, codes.NotFound.String())
+	ok := metrics.GRPCRequests.WithLabelValues(info.FullMethod, codes.OK.String())
+	beforeNotFound, beforeOK := testutil.ToFloat64(notFound), testutil.ToFloat64(ok)
+
+	_, err := server.MetricsUnaryInterceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		return nil, status.Errorf(codes.NotFound, "account not found")
+	})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	_, err = server.MetricsUnaryInterceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		return &pb.GetAccountResponse{}, nil
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, beforeNotFound+1, testutil.ToFloat64(notFound))
+	require.Equal(t, beforeOK+1, testutil.ToFloat64(ok))
+	require.GreaterOrEqual(t, testutil.CollectAndCount(metrics.GRPCRequestDuration), 1)
+}
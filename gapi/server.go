@@ -2,11 +2,17 @@ package gapi
 
 import (
 	"fmt"
+	"net/netip"
 
 	"github.com/S-Devoe/golang-simple-bank/config"
 	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/mail"
+	"github.com/S-Devoe/golang-simple-bank/metrics"
 	"github.com/S-Devoe/golang-simple-bank/pb"
+	"github.com/S-Devoe/golang-simple-bank/session"
 	"github.com/S-Devoe/golang-simple-bank/token"
+	"github.com/S-Devoe/golang-simple-bank/verification"
+	"google.golang.org/grpc"
 )
 
 // server struct will serve all gRPC requests for the banking service
@@ -14,21 +20,50 @@ type Server struct {
 	store      db.Store
 	tokenMaker token.Maker
 	config     config.Config
+	sessions   *session.Service
+	emails     *verification.Service
+	watcher    AccountWatcher
+	// the proxies whose x-forwarded-for entries are believed
+	trustedProxies []netip.Prefix
 	pb.UnimplementedSimpleBankServer
 }
 
-// Newserver creates a new gRPC server.
-func NewServer(config config.Config, store db.Store) (*Server, error) {
+// Newserver creates a new gRPC server. watcher feeds WatchAccount, which is unavailable when it is nil.
+func NewServer(config config.Config, store db.Store, watcher AccountWatcher) (*Server, error) {
 	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
+	tokenMaker = metrics.CountTokens(tokenMaker, "paseto")
+	trustedProxies, err := config.TrustedProxyPrefixes()
+	if err != nil {
+		return nil, err
+	}
 	server := &Server{
-		store:      store,
-		tokenMaker: tokenMaker,
-		config:     config,
+		store:          store,
+		tokenMaker:     tokenMaker,
+		config:         config,
+		sessions:       session.NewService(store, tokenMaker, config.AccessTokenDuration),
+		emails:         newVerificationService(config, store),
+		watcher:        watcher,
+		trustedProxies: trustedProxies,
 	}
 
 	return server, nil
 
 }
+
+func newVerificationService(config config.Config, store db.Store) *verification.Service {
+	sender := mail.NewSender(config.SMTPAddress, config.SMTPUsername, config.SMTPPassword, config.MailFrom)
+	return verification.NewService(store, sender, config.EmailVerificationURL, config.EmailVerificationDuration)
+}
+
+// ServerOptions returns the interceptors every gRPC server built around s must use
+func (s *Server) ServerOptions() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		// tracing, metrics and logging run first, so rejected calls are traced, counted and logged too,
+		// the log line carries the trace id and the username auth adds
+		grpc.ChainUnaryInterceptor(s.TracingUnaryInterceptor, s.MetricsUnaryInterceptor, s.LoggingUnaryInterceptor, s.AuthUnaryInterceptor),
+		grpc.ChainStreamInterceptor(s.TracingStreamInterceptor, s.MetricsStreamInterceptor, s.LoggingStreamInterceptor, s.AuthStreamInterceptor),
+	}
+}
@@ -0,0 +1,86 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/S-Devoe/golang-simple-bank/pb"
+	"github.com/S-Devoe/golang-simple-bank/session"
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Server) RenewAccessToken(ctx context.Context, req *pb.RenewAccessTokenRequest) (*pb.RenewAccessTokenResponse, error) {
+	if req.GetRefreshToken() == "" {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("refresh_token", "must not be empty")})
+	}
+
+	accessToken, accessPayload, err := s.sessions.Renew(ctx, req.GetRefreshToken())
+	if err != nil {
+		return nil, appError(ctx, err)
+	}
+
+	return &pb.RenewAccessTokenResponse{
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: timestamppb.New(accessPayload.ExpiredAt),
+	}, nil
+}
+
+func (s *Server) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.LogoutResponse, error) {
+	if req.GetRefreshToken() == "" {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("refresh_token", "must not be empty")})
+	}
+
+	if _, err := s.sessions.Logout(ctx, req.GetRefreshToken()); err != nil {
+		return nil, appError(ctx, err)
+	}
+	return &pb.LogoutResponse{}, nil
+}
+
+func (s *Server) ListSessions(ctx context.Context, req *pb.ListSessionsRequest) (*pb.ListSessionsResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	pageSize, cursor, err := parsePageToken(req.GetPageSize(), req.GetPageToken(), session.FirstCursor)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.sessions.List(ctx, session.ListParams{
+		Username: authPayload.Username,
+		Cursor:   cursor,
+		Limit:    pageSize + 1,
+	})
+	if err != nil {
+		return nil, internalError(ctx, fmt.Errorf("cannot list sessions: %w", err))
+	}
+
+	rsp := &pb.ListSessionsResponse{}
+	if len(sessions) > int(pageSize) {
+		sessions = sessions[:pageSize]
+		rsp.NextPageToken = util.EncodeCursor(sessions[len(sessions)-1].ID, false)
+	}
+	for _, row := range sessions {
+		rsp.Sessions = append(rsp.Sessions, convertSession(row))
+	}
+	return rsp, nil
+}
+
+func (s *Server) RevokeSession(ctx context.Context, req *pb.RevokeSessionRequest) (*pb.RevokeSessionResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetId() == "" {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("id", "must not be empty")})
+	}
+
+	revoked, err := s.sessions.Revoke(ctx, authPayload.Username, req.GetId())
+	if err != nil {
+		return nil, appError(ctx, err)
+	}
+	return &pb.RevokeSessionResponse{Session: convertSession(revoked)}, nil
+}
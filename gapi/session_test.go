@@ -0,0 +1,161 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/S-Devoe/golang-simple-bank/apperr"
+	mockdb "github.com/S-Devoe/golang-simple-bank/db/mock"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/pb"
+	"github.com/S-Devoe/golang-simple-bank/session"
+	"github.com/S-Devoe/golang-simple-bank/token"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// randomSession returns a refresh token for username and the live session stored for it
+func randomSession(t *testing.T, tokenMaker token.Maker, username string) (string, db.Session) {
+	refreshToken, payload, err := tokenMaker.CreateToken(username, "", "", time.Hour)
+	require.NoError(t, err)
+
+	return refreshToken, db.Session{
+		ID:           payload.ID.String(),
+		Username:     username,
+		RefreshToken: refreshToken,
+		ExpiresAt:    payload.ExpiredAt,
+		CreatedAt:    payload.IssuedAt,
+	}
+}
+
+func TestRenewAccessTokenRPC(t *testing.T) {
+	user := randomUser()
+
+	testCases := []struct {
+		name          string
+		buildStubs    func(store *mockdb.MockStore, session db.Session)
+		checkResponse func(t *testing.T, res *pb.RenewAccessTokenResponse, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.RenewAccessTokenResponse, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, res.GetAccessToken())
+				require.True(t, res.GetAccessTokenExpiresAt().AsTime().After(time.Now()))
+			},
+		},
+		{
+			name: "Blocked",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.IsBlocked = true
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.RenewAccessTokenResponse, err error) {
+				requireErrorReason(t, err, codes.Unauthenticated, apperr.SessionBlocked)
+			},
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, res *pb.RenewAccessTokenResponse, err error) {
+				requireErrorReason(t, err, codes.NotFound, apperr.SessionNotFound)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			server := newTestServer(t, store)
+			refreshToken, session := randomSession(t, server.tokenMaker, user.Username)
+			tc.buildStubs(store, session)
+
+			res, err := server.RenewAccessToken(context.Background(), &pb.RenewAccessTokenRequest{RefreshToken: refreshToken})
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestLogoutRPC(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	refreshToken, userSession := randomSession(t, server.tokenMaker, randomUser().Username)
+
+	blocked := userSession
+	blocked.IsBlocked = true
+	store.EXPECT().GetSession(gomock.Any(), gomock.Eq(userSession.ID)).Times(1).Return(userSession, nil)
+	store.EXPECT().BlockSession(gomock.Any(), gomock.Eq(userSession.ID)).Times(1).Return(blocked, nil)
+
+	_, err := server.Logout(context.Background(), &pb.LogoutRequest{RefreshToken: refreshToken})
+	require.NoError(t, err)
+
+	_, err = server.Logout(context.Background(), &pb.LogoutRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestListSessionsRPC(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	user := randomUser()
+
+	sessions := make([]db.Session, 3)
+	for i := range sessions {
+		_, sessions[i] = randomSession(t, server.tokenMaker, user.Username)
+	}
+
+	store.EXPECT().ListSessions(gomock.Any(), gomock.Eq(db.ListSessionsParams{
+		Username: user.Username,
+		Cursor:   session.FirstCursor,
+		Limit:    3,
+	})).Times(1).Return(sessions, nil)
+	store.EXPECT().ListSessions(gomock.Any(), gomock.Eq(db.ListSessionsParams{
+		Username: user.Username,
+		Cursor:   sessions[1].ID,
+		Limit:    3,
+	})).Times(1).Return(sessions[2:], nil)
+
+	ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username, time.Minute)
+	res, err := server.ListSessions(ctx, &pb.ListSessionsRequest{PageSize: 2})
+	require.NoError(t, err)
+	require.Len(t, res.GetSessions(), 2)
+	require.NotEmpty(t, res.GetNextPageToken())
+
+	res, err = server.ListSessions(ctx, &pb.ListSessionsRequest{PageSize: 2, PageToken: res.GetNextPageToken()})
+	require.NoError(t, err)
+	require.Len(t, res.GetSessions(), 1)
+	require.Equal(t, sessions[2].ID, res.GetSessions()[0].GetId())
+	require.Empty(t, res.GetNextPageToken())
+}
+
+func TestRevokeSessionRPC(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	server := newTestServer(t, store)
+	_, otherSession := randomSession(t, server.tokenMaker, "someone-else")
+	store.EXPECT().GetSession(gomock.Any(), gomock.Eq(otherSession.ID)).Times(1).Return(otherSession, nil)
+	store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+
+	ctx := newContextWithBearerToken(t, server.tokenMaker, randomUser().Username, time.Minute)
+	_, err := server.RevokeSession(ctx, &pb.RevokeSessionRequest{Id: otherSession.ID})
+	requireErrorReason(t, err, codes.NotFound, apperr.SessionNotFound)
+}
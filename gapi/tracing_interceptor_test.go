@@ -0,0 +1,60 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+
+	"github.com/S-Devoe/golang-simple-bank/pb"
+	"github.com/S-Devoe/golang-simple-bank/tracing"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelcodes "go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestTracingUnaryInterceptor(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+	exporter := tracetest.NewInMemoryExporter()
+	tracing.Install(tracing.NewProvider(sdktrace.WithSyncer(exporter)))
+
+	server := newTestServer(t, nil)
+	info := &grpc.UnaryServerInfo{FullMethod: pb.SimpleBank_GetAccount_FullMethodName}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		traceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	))
+
+	_, err := server.TracingUnaryInterceptor(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+		// the handler and the store calls it makes run under the RPC span
+		require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", trace.SpanContextFromContext(ctx).TraceID().String())
+		return nil, status.Errorf(codes.Internal, "cannot fetch account: connection reset")
+	})
+	require.Equal(t, codes.Internal, status.Code(err))
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 1)
+	span := spans[0]
+	require.Equal(t, "pb.SimpleBank/GetAccount", span.Name)
+	require.Equal(t, trace.SpanKindServer, span.SpanKind)
+	require.Equal(t, "00f067aa0ba902b7", span.Parent.SpanID().String())
+	require.Contains(t, span.Attributes, attribute.String("rpc.method", "GetAccount"))
+	require.Contains(t, span.Attributes, attribute.Int("rpc.grpc.status_code", int(codes.Internal)))
+	require.Equal(t, otelcodes.Error, span.Status.Code)
+
+	// client errors don't mark the span as failed
+	exporter.Reset()
+	_, err = server.TracingUnaryInterceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		return nil, status.Errorf(codes.NotFound, "account not found")
+	})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	require.Len(t, exporter.GetSpans(), 1)
+	require.Equal(t, otelcodes.Unset, exporter.GetSpans()[0].Status.Code)
+	require.False(t, exporter.GetSpans()[0].Parent.IsValid())
+}
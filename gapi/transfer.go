@@ -0,0 +1,196 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/S-Devoe/golang-simple-bank/apperr"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/metrics"
+	"github.com/S-Devoe/golang-simple-bank/pb"
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/S-Devoe/golang-simple-bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/metadata"
+)
+
+// idempotencyKeyHeader carries the client's retry key; it is stored as the transfer's end_to_end_id
+const idempotencyKeyHeader = "idempotency-key"
+
+func (s *Server) TransferMoney(ctx context.Context, req *pb.TransferMoneyRequest) (*pb.TransferMoneyResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	idempotencyKey := idempotencyKeyFromContext(ctx)
+	if violations := validateTransferMoneyRequest(req, idempotencyKey); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	currency, err := s.store.GetCurrency(ctx, req.GetCurrency())
+	if err != nil && err != db.ErrRecordNotFound {
+		return nil, internalError(ctx, fmt.Errorf("cannot fetch currency: %w", err))
+	}
+	if err == db.ErrRecordNotFound || !currency.Enabled {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{
+			fieldViolation("currency", "unsupported currency"),
+		})
+	}
+	if violation := checkTransferAmount(currency, req.GetAmount()); violation != nil {
+		return nil, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{violation})
+	}
+
+	fromAccount, err := s.transferAccount(ctx, req.GetFromAccountId(), currency.Code)
+	if err != nil {
+		return nil, err
+	}
+	if fromAccount.Owner != authPayload.Username {
+		return nil, errorWithInfo(apperr.AccountNotOwned, "account does not belong to the authenticated user",
+			map[string]string{"account_id": strconv.FormatInt(fromAccount.ID, 10)})
+	}
+	if _, err := s.transferAccount(ctx, req.GetToAccountId(), currency.Code); err != nil {
+		return nil, err
+	}
+
+	if idempotencyKey != "" {
+		// a retry of a transfer that already went through returns it instead of moving the money twice
+		transfer, err := s.store.GetTransferByEndToEndID(ctx, db.GetTransferByEndToEndIDParams{
+			FromAccountID: fromAccount.ID,
+			EndToEndID:    idempotencyKey,
+		})
+		if err == nil {
+			return replayTransfer(transfer, req)
+		}
+		if err != db.ErrRecordNotFound {
+			return nil, internalError(ctx, fmt.Errorf("cannot check idempotency key: %w", err))
+		}
+	}
+
+	result, err := s.store.TransferTx(ctx, db.TransferTxParams{
+		FromAccountID:  fromAccount.ID,
+		ToAccountID:    req.GetToAccountId(),
+		Amount:         req.GetAmount(),
+		Currency:       currency.Code,
+		Memo:           req.GetMemo(),
+		Reference:      req.GetReference(),
+		EndToEndID:     idempotencyKey,
+		CooldownLimit:  currency.PayeeCooldownLimit,
+		CooldownPeriod: s.config.PayeeCooldownDuration,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrInsufficientFunds) {
+			metrics.Transfers.WithLabelValues(metrics.TransportGRPC, currency.Code, metrics.TransferInsufficientFunds).Inc()
+			return nil, errorWithInfo(apperr.InsufficientFunds, err.Error(),
+				map[string]string{"account_id": strconv.FormatInt(fromAccount.ID, 10)})
+		}
+		if errors.Is(err, db.ErrPayeeCooldown) {
+			metrics.Transfers.WithLabelValues(metrics.TransportGRPC, currency.Code, metrics.TransferRejected).Inc()
+			return nil, errorWithInfo(apperr.PayeeCooldown,
+				fmt.Sprintf("transfers above %.2f %s are only allowed to accounts saved as a payee at least %s ago",
+					currency.PayeeCooldownLimit, currency.Code, s.config.PayeeCooldownDuration),
+				map[string]string{"to_account_id": strconv.FormatInt(req.GetToAccountId(), 10)})
+		}
+		if db.ErrorCode(err) == db.UniqueViolation {
+			// a concurrent retry with the same key won the race
+			transfer, getErr := s.store.GetTransferByEndToEndID(ctx, db.GetTransferByEndToEndIDParams{
+				FromAccountID: fromAccount.ID,
+				EndToEndID:    idempotencyKey,
+			})
+			if getErr == nil {
+				return replayTransfer(transfer, req)
+			}
+		}
+		metrics.Transfers.WithLabelValues(metrics.TransportGRPC, currency.Code, metrics.TransferFailed).Inc()
+		return nil, internalError(ctx, fmt.Errorf("cannot transfer money: %w", err))
+	}
+	metrics.Transfers.WithLabelValues(metrics.TransportGRPC, currency.Code, metrics.TransferCompleted).Inc()
+
+	return &pb.TransferMoneyResponse{
+		Transfer:    convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+	}, nil
+}
+
+// transferAccount fetches one side of a transfer and checks it holds the transfer currency
+func (s *Server) transferAccount(ctx context.Context, id int64, currency string) (db.Account, error) {
+	account, err := s.store.GetAccount(ctx, id)
+	if err != nil {
+		if err == db.ErrRecordNotFound {
+			return account, errorWithInfo(apperr.AccountNotFound, "account not found",
+				map[string]string{"account_id": strconv.FormatInt(id, 10)})
+		}
+		return account, internalError(ctx, fmt.Errorf("cannot fetch account: %w", err))
+	}
+
+	if account.Currency != currency {
+		return account, errorWithInfo(apperr.CurrencyMismatch,
+			fmt.Sprintf("account %d holds %s, not %s", account.ID, account.Currency, currency),
+			map[string]string{
+				"account_id":       strconv.FormatInt(account.ID, 10),
+				"account_currency": account.Currency,
+			})
+	}
+	return account, nil
+}
+
+// replayTransfer answers a retry with the transfer its key created, refusing keys reused for a different transfer
+func replayTransfer(transfer db.Transfer, req *pb.TransferMoneyRequest) (*pb.TransferMoneyResponse, error) {
+	if transfer.ToAccountID != req.GetToAccountId() || transfer.Amount != req.GetAmount() {
+		return nil, errorWithInfo(apperr.IdempotencyKeyReused,
+			"idempotency key was already used for a different transfer",
+			map[string]string{"transfer_id": strconv.FormatInt(transfer.ID, 10)})
+	}
+	return &pb.TransferMoneyResponse{Transfer: convertTransfer(transfer)}, nil
+}
+
+func idempotencyKeyFromContext(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(idempotencyKeyHeader); len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
+
+func validateTransferMoneyRequest(req *pb.TransferMoneyRequest, idempotencyKey string) (violations []*errdetails.BadRequest_FieldViolation) {
+	if req.GetFromAccountId() <= 0 {
+		violations = append(violations, fieldViolation("from_account_id", "must be a positive account id"))
+	}
+	if req.GetToAccountId() <= 0 {
+		violations = append(violations, fieldViolation("to_account_id", "must be a positive account id"))
+	}
+	if req.GetFromAccountId() == req.GetToAccountId() && req.GetFromAccountId() > 0 {
+		violations = append(violations, fieldViolation("to_account_id", "must differ from from_account_id"))
+	}
+	if req.GetAmount() <= 0 {
+		violations = append(violations, fieldViolation("amount", "must be positive"))
+	}
+	if req.GetCurrency() == "" {
+		violations = append(violations, fieldViolation("currency", "is required"))
+	}
+	if err := val.ValidateMemo(req.GetMemo()); err != nil {
+		violations = append(violations, fieldViolation("memo", err.Error()))
+	}
+	if err := val.ValidateReference(req.GetReference()); err != nil {
+		violations = append(violations, fieldViolation("reference", err.Error()))
+	}
+	// the idempotency key is stored as the transfer's end_to_end_id
+	if err := val.ValidateEndToEndID(idempotencyKey); err != nil {
+		violations = append(violations, fieldViolation(idempotencyKeyHeader, err.Error()))
+	}
+	return violations
+}
+
+// checkTransferAmount applies the registry's precision and limits, like api.checkTransferAmount
+func checkTransferAmount(currency db.Currency, amount float64) *errdetails.BadRequest_FieldViolation {
+	if !util.HasValidPrecision(amount, currency.Exponent) {
+		return fieldViolation("amount", fmt.Sprintf("%s amounts can have at most %d decimals", currency.Code, currency.Exponent))
+	}
+	if amount < currency.MinTransferAmount || amount > currency.MaxTransferAmount {
+		return fieldViolation("amount", fmt.Sprintf("%s transfers must be between %.2f and %.2f", currency.Code, currency.MinTransferAmount, currency.MaxTransferAmount))
+	}
+	return nil
+}
@@ -0,0 +1,222 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/S-Devoe/golang-simple-bank/apperr"
+	mockdb "github.com/S-Devoe/golang-simple-bank/db/mock"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/pb"
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestTransferMoneyRPC(t *testing.T) {
+	user := randomUser()
+	fromAccount := randomAccount(user.Username)
+	fromAccount.Balance = 100
+	toAccount := randomAccount(randomUser().Username)
+	toAccount.ID = fromAccount.ID + 1
+	eurAccount := randomAccount(toAccount.Owner)
+	eurAccount.ID = fromAccount.ID + 2
+	eurAccount.Currency = util.EUR
+	usd := db.Currency{Code: util.USD, Exponent: 2, Enabled: true, MinTransferAmount: 1, MaxTransferAmount: 1000, PayeeCooldownLimit: 100}
+
+	transfer := db.Transfer{ID: 42, FromAccountID: fromAccount.ID, ToAccountID: toAccount.ID, Amount: 10, EndToEndID: "retry-1"}
+
+	testCases := []struct {
+		name           string
+		req            *pb.TransferMoneyRequest
+		idempotencyKey string
+		buildStubs     func(store *mockdb.MockStore)
+		checkResponse  func(t *testing.T, res *pb.TransferMoneyResponse, err error)
+	}{
+		{
+			name:           "OK",
+			req:            &pb.TransferMoneyRequest{FromAccountId: fromAccount.ID, ToAccountId: toAccount.ID, Amount: 10, Currency: util.USD, Memo: "lunch"},
+			idempotencyKey: "retry-1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCurrency(gomock.Any(), gomock.Eq(util.USD)).Times(1).Return(usd, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetTransferByEndToEndID(gomock.Any(), gomock.Any()).Times(1).Return(db.Transfer{}, db.ErrRecordNotFound)
+				arg := db.TransferTxParams{
+					FromAccountID: fromAccount.ID,
+					ToAccountID:   toAccount.ID,
+					Amount:        10,
+					Currency:      util.USD,
+					Memo:          "lunch",
+					EndToEndID:    "retry-1",
+					CooldownLimit: 100,
+				}
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Eq(arg)).Times(1).Return(db.TransferTxResult{
+					Transfer:    transfer,
+					FromAccount: db.Account{ID: fromAccount.ID, Balance: 90},
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferMoneyResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, transfer.ID, res.GetTransfer().GetId())
+				require.Equal(t, float64(90), res.GetFromAccount().GetBalance())
+			},
+		},
+		{
+			name:           "IdempotentRetry",
+			req:            &pb.TransferMoneyRequest{FromAccountId: fromAccount.ID, ToAccountId: toAccount.ID, Amount: 10, Currency: util.USD},
+			idempotencyKey: "retry-1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCurrency(gomock.Any(), gomock.Any()).Times(1).Return(usd, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				arg := db.GetTransferByEndToEndIDParams{FromAccountID: fromAccount.ID, EndToEndID: "retry-1"}
+				store.EXPECT().GetTransferByEndToEndID(gomock.Any(), gomock.Eq(arg)).Times(1).Return(transfer, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferMoneyResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, transfer.ID, res.GetTransfer().GetId())
+			},
+		},
+		{
+			name:           "IdempotencyKeyReused",
+			req:            &pb.TransferMoneyRequest{FromAccountId: fromAccount.ID, ToAccountId: toAccount.ID, Amount: 20, Currency: util.USD},
+			idempotencyKey: "retry-1",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCurrency(gomock.Any(), gomock.Any()).Times(1).Return(usd, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().GetTransferByEndToEndID(gomock.Any(), gomock.Any()).Times(1).Return(transfer, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferMoneyResponse, err error) {
+				requireErrorReason(t, err, codes.AlreadyExists, apperr.IdempotencyKeyReused)
+			},
+		},
+		{
+			name: "InsufficientFunds",
+			req:  &pb.TransferMoneyRequest{FromAccountId: fromAccount.ID, ToAccountId: toAccount.ID, Amount: 500, Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCurrency(gomock.Any(), gomock.Any()).Times(1).Return(usd, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				// the balance is only checked when TransferTx debits it
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferMoneyResponse, err error) {
+				requireErrorReason(t, err, codes.FailedPrecondition, apperr.InsufficientFunds)
+			},
+		},
+		{
+			name: "CurrencyMismatch",
+			req:  &pb.TransferMoneyRequest{FromAccountId: fromAccount.ID, ToAccountId: eurAccount.ID, Amount: 10, Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCurrency(gomock.Any(), gomock.Any()).Times(1).Return(usd, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(fromAccount, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(eurAccount.ID)).Times(1).Return(eurAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferMoneyResponse, err error) {
+				requireErrorReason(t, err, codes.FailedPrecondition, apperr.CurrencyMismatch)
+			},
+		},
+		{
+			name: "NotOwner",
+			req:  &pb.TransferMoneyRequest{FromAccountId: toAccount.ID, ToAccountId: fromAccount.ID, Amount: 10, Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCurrency(gomock.Any(), gomock.Any()).Times(1).Return(usd, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).Times(1).Return(toAccount, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferMoneyResponse, err error) {
+				requireErrorReason(t, err, codes.PermissionDenied, apperr.AccountNotOwned)
+			},
+		},
+		{
+			name: "AccountNotFound",
+			req:  &pb.TransferMoneyRequest{FromAccountId: fromAccount.ID, ToAccountId: toAccount.ID, Amount: 10, Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().GetCurrency(gomock.Any(), gomock.Any()).Times(1).Return(usd, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).Times(1).Return(db.Account{}, db.ErrRecordNotFound)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferMoneyResponse, err error) {
+				requireErrorReason(t, err, codes.NotFound, apperr.AccountNotFound)
+			},
+		},
+		{
+			name: "InvalidArguments",
+			req:  &pb.TransferMoneyRequest{FromAccountId: 0, ToAccountId: toAccount.ID, Amount: -5, Currency: util.USD},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.TransferMoneyResponse, err error) {
+				requireFieldViolations(t, err, "from_account_id", "amount")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username, time.Minute)
+			if tc.idempotencyKey != "" {
+				ctx = withIncomingMetadata(ctx, idempotencyKeyHeader, tc.idempotencyKey)
+			}
+			res, err := server.TransferMoney(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func withIncomingMetadata(ctx context.Context, key string, value string) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+	md = md.Copy()
+	md.Set(key, value)
+	return metadata.NewIncomingContext(ctx, md)
+}
+
+func requireErrorReason(t *testing.T, err error, code codes.Code, reason apperr.Code) {
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, code, st.Code())
+
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			require.Equal(t, string(reason), info.GetReason())
+			require.Equal(t, errorDomain, info.GetDomain())
+			return
+		}
+	}
+	t.Fatalf("no ErrorInfo detail in %v", err)
+}
+
+// requireFieldViolations checks err is InvalidArgument with a BadRequest detail naming exactly fields
+func requireFieldViolations(t *testing.T, err error, fields ...string) {
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, codes.InvalidArgument, st.Code())
+
+	var violated []string
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.GetFieldViolations() {
+				violated = append(violated, violation.GetField())
+			}
+		}
+	}
+	require.ElementsMatch(t, fields, violated)
+}
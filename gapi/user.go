@@ -2,39 +2,175 @@ package gapi
 
 import (
 	"context"
+	"fmt"
 
+	"github.com/S-Devoe/golang-simple-bank/apperr"
 	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/logging"
 	"github.com/S-Devoe/golang-simple-bank/pb"
 	"github.com/S-Devoe/golang-simple-bank/util/password"
-	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/status"
+	"github.com/S-Devoe/golang-simple-bank/val"
+	"github.com/jackc/pgx/v5/pgtype"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 )
 
 func (s *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+	if violations := validateCreateUserRequest(req); violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+	logging.With(ctx, "username", req.GetUsername())
+
 	hashedPassword, err := password.GeneratePasswordHash(req.GetPassword())
 	if err != nil {
-		// return util.CreateResponse(900, nil, status.Errorf(codes.Internal, "cannot hash password: %v", err)),note: I need to create another util response for GRPC
-		return nil, status.Errorf(codes.Internal, "cannot hash password: %v", err)
+		// return util.CreateResponse(900, nil, internalError(ctx, fmt.Errorf("cannot hash password: %w", err))),note: I need to create another util response for GRPC
+		return nil, internalError(ctx, fmt.Errorf("cannot hash password: %w", err))
+	}
+	verification, err := s.emails.New()
+	if err != nil {
+		return nil, internalError(ctx, err)
 	}
-	arg := db.CreateUserParams{
-		Username:       req.GetUsername(),
-		Email:          req.GetEmail(),
-		FullName:       req.GetFullName(),
-		HashedPassword: hashedPassword,
+	arg := db.CreateUserTxParams{
+		CreateUserParams: db.CreateUserParams{
+			Username:       req.GetUsername(),
+			Email:          req.GetEmail(),
+			FullName:       req.GetFullName(),
+			HashedPassword: hashedPassword,
+		},
+		Verification: verification,
 	}
 
-	user, err := s.store.CreateUser(ctx, arg)
+	result, err := s.store.CreateUserTx(ctx, arg)
 	if err != nil {
 		if db.ErrorCode(err) == db.UniqueViolation {
 
-			return nil, status.Errorf(codes.AlreadyExists, "username or email already exists")
+			return nil, errorWithInfo(apperr.UserAlreadyExists, "username or email already exists", nil)
 		}
 
-		return nil, status.Errorf(codes.Internal, "cannot create user: %v", err)
+		return nil, internalError(ctx, fmt.Errorf("cannot create user: %w", err))
 	}
+	s.emails.Send(ctx, result.VerifyEmail)
 	resp := &pb.CreateUserResponse{
-		User: converteUser(user),
+		User: converteUser(result.User),
 	}
 	return resp, nil
 
 }
+
+// validateCreateUserRequest applies the rules createUserRequest's binding tags apply over REST
+func validateCreateUserRequest(req *pb.CreateUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateUsername(req.GetUsername()); err != nil {
+		violations = append(violations, fieldViolation("username", err.Error()))
+	}
+	if err := val.ValidatePassword(req.GetPassword()); err != nil {
+		violations = append(violations, fieldViolation("password", err.Error()))
+	}
+	if err := val.ValidateFullName(req.GetFullName()); err != nil {
+		violations = append(violations, fieldViolation("full_name", err.Error()))
+	}
+	if err := val.ValidateEmail(req.GetEmail()); err != nil {
+		violations = append(violations, fieldViolation("email", err.Error()))
+	}
+	return violations
+}
+
+// UpdateUser changes the fields named in the update mask, the same rules as PATCH /users/:username
+func (s *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.UpdateUserResponse, error) {
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.GetUsername() != authPayload.Username {
+		return nil, errorWithInfo(apperr.PermissionDenied, "cannot update another user", nil)
+	}
+
+	arg, err := updateUserParams(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	if arg.Email.Valid {
+		arg.Verification, err = s.emails.New()
+		if err != nil {
+			return nil, internalError(ctx, err)
+		}
+	}
+
+	result, err := s.store.UpdateUserTx(ctx, arg)
+	if err != nil {
+		if err == db.ErrRecordNotFound {
+			return nil, errorWithInfo(apperr.UserNotFound, "user not found", nil)
+		}
+		if db.ErrorCode(err) == db.UniqueViolation {
+			return nil, errorWithInfo(apperr.EmailTaken, "email already exists", nil)
+		}
+		return nil, internalError(ctx, fmt.Errorf("cannot update user: %w", err))
+	}
+	if result.VerifyEmail.ID != 0 {
+		s.emails.Send(ctx, result.VerifyEmail)
+	}
+	return &pb.UpdateUserResponse{User: converteUser(result.User)}, nil
+}
+
+// updateUserParams validates the fields named in the update mask and turns them into the store arguments
+func updateUserParams(ctx context.Context, req *pb.UpdateUserRequest) (db.UpdateUserTxParams, error) {
+	arg := db.UpdateUserTxParams{Username: req.GetUsername()}
+
+	paths := req.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		return arg, invalidArgumentError([]*errdetails.BadRequest_FieldViolation{
+			fieldViolation("update_mask", "must name at least one of full_name, email or password"),
+		})
+	}
+
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, path := range paths {
+		switch path {
+		case "full_name":
+			if err := val.ValidateFullName(req.GetFullName()); err != nil {
+				violations = append(violations, fieldViolation("full_name", err.Error()))
+			}
+			arg.FullName = pgtype.Text{String: req.GetFullName(), Valid: true}
+		case "email":
+			if err := val.ValidateEmail(req.GetEmail()); err != nil {
+				violations = append(violations, fieldViolation("email", err.Error()))
+			}
+			arg.Email = pgtype.Text{String: req.GetEmail(), Valid: true}
+		case "password":
+			if err := val.ValidatePassword(req.GetPassword()); err != nil {
+				violations = append(violations, fieldViolation("password", err.Error()))
+				continue
+			}
+			hashedPassword, err := password.GeneratePasswordHash(req.GetPassword())
+			if err != nil {
+				return arg, internalError(ctx, fmt.Errorf("cannot hash password: %w", err))
+			}
+			arg.HashedPassword = pgtype.Text{String: hashedPassword, Valid: true}
+		default:
+			violations = append(violations, fieldViolation("update_mask", "unknown field "+path))
+		}
+	}
+	if len(violations) > 0 {
+		return arg, invalidArgumentError(violations)
+	}
+	return arg, nil
+}
+
+// VerifyEmail confirms a user's email address with the code mailed to it, the same rules as GET /verify_email
+func (s *Server) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*pb.VerifyEmailResponse, error) {
+	var violations []*errdetails.BadRequest_FieldViolation
+	if req.GetEmailId() <= 0 {
+		violations = append(violations, fieldViolation("email_id", "must be a positive integer"))
+	}
+	if req.GetSecretCode() == "" {
+		violations = append(violations, fieldViolation("secret_code", "must not be empty"))
+	}
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	user, err := s.emails.Verify(ctx, req.GetEmailId(), req.GetSecretCode())
+	if err != nil {
+		return nil, appError(ctx, err)
+	}
+	logging.With(ctx, "username", user.Username)
+	return &pb.VerifyEmailResponse{IsVerified: user.IsEmailVerified}, nil
+}
@@ -0,0 +1,289 @@
+package gapi
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/S-Devoe/golang-simple-bank/apperr"
+	mockdb "github.com/S-Devoe/golang-simple-bank/db/mock"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/pb"
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/S-Devoe/golang-simple-bank/util/password"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+)
+
+func TestCreateUserRPC(t *testing.T) {
+	user := randomUser()
+
+	testCases := []struct {
+		name          string
+		req           *pb.CreateUserRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.CreateUserResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.CreateUserRequest{
+				Username: user.Username,
+				FullName: user.FullName,
+				Email:    user.Email,
+				Password: util.GenerateRandomString(8),
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateUserTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.CreateUserTxParams) (db.CreateUserTxResult, error) {
+						require.Equal(t, user.Email, arg.Email)
+						require.NotEmpty(t, arg.Verification.SecretCode)
+						return db.CreateUserTxResult{User: user}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.Username, res.GetUser().GetUsername())
+			},
+		},
+		{
+			name: "InvalidFields",
+			req: &pb.CreateUserRequest{
+				Username: "bob smith",
+				FullName: "<script>",
+				Email:    "Bob <bob@example.com>",
+				Password: "short",
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CreateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateUserResponse, err error) {
+				requireFieldViolations(t, err, "username", "full_name", "email", "password")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			res, err := server.CreateUser(context.Background(), tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestUpdateUserRPC(t *testing.T) {
+	user := randomUser()
+	newFullName := util.GenerateRandomName()
+	newEmail := util.GenerateRandomEmail()
+	newPassword := util.GenerateRandomString(8)
+
+	testCases := []struct {
+		name          string
+		req           *pb.UpdateUserRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.UpdateUserResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.UpdateUserRequest{
+				Username:   user.Username,
+				FullName:   newFullName,
+				Email:      newEmail,
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"full_name", "email"}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				updated := user
+				updated.FullName = newFullName
+				updated.Email = newEmail
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, arg db.UpdateUserTxParams) (db.UpdateUserTxResult, error) {
+						require.Equal(t, user.Username, arg.Username)
+						require.Equal(t, pgtype.Text{String: newFullName, Valid: true}, arg.FullName)
+						require.Equal(t, pgtype.Text{String: newEmail, Valid: true}, arg.Email)
+						require.False(t, arg.HashedPassword.Valid)
+						// the new address is sent a code to verify it with
+						require.NotEmpty(t, arg.Verification.SecretCode)
+						return db.UpdateUserTxResult{User: updated}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, newFullName, res.GetUser().GetFullName())
+				require.Equal(t, newEmail, res.GetUser().GetEmail())
+				require.False(t, res.GetUser().GetIsEmailVerified())
+			},
+		},
+		{
+			name: "ChangePassword",
+			req: &pb.UpdateUserRequest{
+				Username:   user.Username,
+				FullName:   "ignored because it is not in the mask",
+				Password:   newPassword,
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"password"}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(ctx context.Context, arg db.UpdateUserTxParams) (db.UpdateUserTxResult, error) {
+						require.False(t, arg.FullName.Valid)
+						require.True(t, arg.HashedPassword.Valid)
+						match, err := password.ComparePasswordAndHash(newPassword, arg.HashedPassword.String)
+						require.NoError(t, err)
+						require.True(t, match)
+						return db.UpdateUserTxResult{User: user, BlockedSessions: 1}, nil
+					})
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "OtherUser",
+			req: &pb.UpdateUserRequest{
+				Username:   "someone-else",
+				FullName:   newFullName,
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"full_name"}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.Equal(t, codes.PermissionDenied, status.Code(err))
+			},
+		},
+		{
+			name: "MissingMask",
+			req: &pb.UpdateUserRequest{
+				Username: user.Username,
+				FullName: newFullName,
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.Equal(t, codes.InvalidArgument, status.Code(err))
+			},
+		},
+		{
+			name: "InvalidFields",
+			req: &pb.UpdateUserRequest{
+				Username:   user.Username,
+				Email:      "not-an-email",
+				Password:   "short",
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"email", "password", "username"}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				requireFieldViolations(t, err, "email", "password", "update_mask")
+			},
+		},
+		{
+			name: "DuplicateEmail",
+			req: &pb.UpdateUserRequest{
+				Username:   user.Username,
+				Email:      newEmail,
+				UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"email"}},
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().UpdateUserTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.UpdateUserTxResult{}, db.ErrUniqueViolation)
+			},
+			checkResponse: func(t *testing.T, res *pb.UpdateUserResponse, err error) {
+				require.Equal(t, codes.AlreadyExists, status.Code(err))
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username, time.Minute)
+			res, err := server.UpdateUser(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestVerifyEmailRPC(t *testing.T) {
+	user := randomUser()
+	emailID := util.GenerateRandomInt(1, 1000)
+	secretCode := util.GenerateRandomString(32)
+
+	testCases := []struct {
+		name          string
+		req           *pb.VerifyEmailRequest
+		buildStubs    func(store *mockdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.VerifyEmailResponse, err error)
+	}{
+		{
+			name: "OK",
+			req:  &pb.VerifyEmailRequest{EmailId: emailID, SecretCode: secretCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				arg := db.VerifyEmailTxParams{EmailID: emailID, SecretCode: secretCode}
+				verified := user
+				verified.IsEmailVerified = true
+				store.EXPECT().VerifyEmailTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.VerifyEmailTxResult{User: verified}, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.VerifyEmailResponse, err error) {
+				require.NoError(t, err)
+				require.True(t, res.GetIsVerified())
+			},
+		},
+		{
+			name: "UsedOrExpiredCode",
+			req:  &pb.VerifyEmailRequest{EmailId: emailID, SecretCode: secretCode},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().VerifyEmailTx(gomock.Any(), gomock.Any()).Times(1).
+					Return(db.VerifyEmailTxResult{}, db.ErrRecordNotFound)
+			},
+			checkResponse: func(t *testing.T, res *pb.VerifyEmailResponse, err error) {
+				requireErrorReason(t, err, codes.FailedPrecondition, apperr.InvalidEmailCode)
+			},
+		},
+		{
+			name: "InvalidFields",
+			req:  &pb.VerifyEmailRequest{},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().VerifyEmailTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.VerifyEmailResponse, err error) {
+				requireFieldViolations(t, err, "email_id", "secret_code")
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := newTestServer(t, store)
+			res, err := server.VerifyEmail(context.Background(), tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/S-Devoe/golang-simple-bank/apperr"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/pb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+)
+
+// AccountWatcher wakes WatchAccount streams whenever an account gets a new entry.
+// The channel from Subscribe is closed when the watcher shuts down.
+type AccountWatcher interface {
+	Subscribe(accountID int64) (<-chan struct{}, func())
+}
+
+// watchBatchSize bounds how many entries one WatchAccount response carries
+const watchBatchSize = 100
+
+// entrySettleWindow is how long an entry's transaction may take to commit. Entry ids are taken when an entry is
+// inserted but the entry only becomes visible when its transaction commits, so an entry can show up after one
+// with a higher id; the feed keeps scanning the entries created within the window again and skips the ones it sent.
+const entrySettleWindow = time.Minute
+
+// accountFeed is what a WatchAccount stream has sent: every entry up to cursor, and the entries in sent above it
+type accountFeed struct {
+	accountID int64
+	cursor    int64
+	sent      map[int64]bool
+}
+
+func (s *Server) WatchAccount(req *pb.WatchAccountRequest, stream grpc.ServerStreamingServer[pb.WatchAccountResponse]) error {
+	ctx := stream.Context()
+	authPayload, err := authPayloadFromContext(ctx)
+	if err != nil {
+		return err
+	}
+	if s.watcher == nil {
+		return errorWithInfo(apperr.Unavailable, "account notifications are not enabled", nil)
+	}
+	if req.GetLastEntryId() < 0 {
+		return invalidArgumentError([]*errdetails.BadRequest_FieldViolation{fieldViolation("last_entry_id", "must not be negative")})
+	}
+
+	account, err := s.ownedAccount(ctx, authPayload, req.GetAccountId())
+	if err != nil {
+		return err
+	}
+
+	// subscribe before reading, so an entry committed in between still wakes the stream
+	wakeup, unsubscribe := s.watcher.Subscribe(account.ID)
+	defer unsubscribe()
+
+	feed, err := s.startAccountFeed(ctx, account.ID, req.GetLastEntryId())
+	if err != nil {
+		return err
+	}
+
+	// the first response goes out even without new entries, so the client starts from the current balance
+	if err := s.sendAccountActivity(ctx, stream, feed, true); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-wakeup:
+			if !ok {
+				return errorWithInfo(apperr.Unavailable, "account feed closed, reconnect with last_entry_id to resume", nil)
+			}
+			if err := s.sendAccountActivity(ctx, stream, feed, false); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// startAccountFeed places the cursor on the newest entry at or below lastEntryID that is older than
+// entrySettleWindow, so entries that commit late below lastEntryID are still picked up. Resuming sends the
+// newer entries below lastEntryID again and clients skip the ids they have seen. Starting from now, with
+// lastEntryID 0, counts the entries visible so far as sent, the balance in the first response includes them.
+func (s *Server) startAccountFeed(ctx context.Context, accountID int64, lastEntryID int64) (*accountFeed, error) {
+	feed := &accountFeed{accountID: accountID, sent: map[int64]bool{}}
+
+	cursor := int64(math.MaxInt64)
+	if lastEntryID > 0 {
+		cursor = lastEntryID + 1
+	}
+	for {
+		entries, err := s.store.ListAccountEntries(ctx, db.ListAccountEntriesParams{
+			AccountID: accountID,
+			Cursor:    cursor,
+			Limit:     watchBatchSize,
+		})
+		if err != nil {
+			return nil, internalError(ctx, fmt.Errorf("cannot list entries: %w", err))
+		}
+		for _, entry := range entries {
+			if time.Since(entry.CreatedAt) > entrySettleWindow {
+				feed.cursor = entry.ID
+				return feed, nil
+			}
+			if lastEntryID == 0 {
+				feed.sent[entry.ID] = true
+			}
+		}
+		if len(entries) < watchBatchSize {
+			return feed, nil
+		}
+		cursor = entries[len(entries)-1].ID
+	}
+}
+
+// sendAccountActivity sends the entries after the feed's cursor that it hasn't sent yet in batches, each with the
+// account as it stands, and moves the cursor past the settled ones. Nothing is sent when there are no new
+// entries unless always is set.
+func (s *Server) sendAccountActivity(ctx context.Context, stream grpc.ServerStreamingServer[pb.WatchAccountResponse], feed *accountFeed, always bool) error {
+	cursor := feed.cursor
+	settled := true
+	for {
+		entries, err := s.store.ListAccountEntriesReverse(ctx, db.ListAccountEntriesReverseParams{
+			AccountID: feed.accountID,
+			Cursor:    cursor,
+			Limit:     watchBatchSize,
+		})
+		if err != nil {
+			return internalError(ctx, fmt.Errorf("cannot list entries: %w", err))
+		}
+
+		var pending []db.ListAccountEntriesReverseRow
+		for _, entry := range entries {
+			if !feed.sent[entry.ID] {
+				pending = append(pending, entry)
+			}
+		}
+
+		if len(pending) > 0 || always {
+			account, err := s.store.GetAccount(ctx, feed.accountID)
+			if err != nil {
+				return internalError(ctx, fmt.Errorf("cannot fetch account: %w", err))
+			}
+
+			rsp := &pb.WatchAccountResponse{Account: convertAccount(account)}
+			for _, entry := range pending {
+				rsp.Entries = append(rsp.Entries, convertEntry(db.ListAccountEntriesRow(entry)))
+			}
+			if err := stream.Send(rsp); err != nil {
+				return err
+			}
+			always = false
+		}
+
+		// the cursor only moves over settled entries with no unsettled one below them,
+		// the ids above it are remembered so the next scan doesn't send them again
+		for _, entry := range entries {
+			if settled && time.Since(entry.CreatedAt) > entrySettleWindow {
+				feed.cursor = entry.ID
+				delete(feed.sent, entry.ID)
+				continue
+			}
+			settled = false
+			feed.sent[entry.ID] = true
+		}
+
+		if len(entries) < watchBatchSize {
+			return nil
+		}
+		cursor = entries[len(entries)-1].ID
+	}
+}
@@ -0,0 +1,189 @@
+package gapi
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	mockdb "github.com/S-Devoe/golang-simple-bank/db/mock"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/pb"
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeWatcher struct {
+	wakeup chan struct{}
+}
+
+func (watcher *fakeWatcher) Subscribe(accountID int64) (<-chan struct{}, func()) {
+	return watcher.wakeup, func() {}
+}
+
+// fakeWatchStream collects the responses WatchAccount sends
+type fakeWatchStream struct {
+	grpc.ServerStream
+	ctx       context.Context
+	responses chan *pb.WatchAccountResponse
+}
+
+func (stream *fakeWatchStream) Context() context.Context {
+	return stream.ctx
+}
+
+func (stream *fakeWatchStream) Send(rsp *pb.WatchAccountResponse) error {
+	stream.responses <- rsp
+	return nil
+}
+
+// randomEntry returns an entry created age ago, entries older than entrySettleWindow count as settled
+func randomEntry(accountID int64, id int64, age time.Duration) db.ListAccountEntriesReverseRow {
+	return db.ListAccountEntriesReverseRow{
+		ID:        id,
+		AccountID: accountID,
+		Amount:    util.RandomMoney(),
+		CreatedAt: time.Now().Add(-age),
+	}
+}
+
+func expectEntriesAfter(store *mockdb.MockStore, accountID int64, cursor int64, entries ...db.ListAccountEntriesReverseRow) *gomock.Call {
+	return store.EXPECT().ListAccountEntriesReverse(gomock.Any(), gomock.Eq(db.ListAccountEntriesReverseParams{
+		AccountID: accountID,
+		Cursor:    cursor,
+		Limit:     watchBatchSize,
+	})).Times(1).Return(entries, nil)
+}
+
+func entryIDs(rsp *pb.WatchAccountResponse) []int64 {
+	var ids []int64
+	for _, entry := range rsp.GetEntries() {
+		ids = append(ids, entry.GetId())
+	}
+	return ids
+}
+
+func TestWatchAccountRPC(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := randomUser()
+	account := randomAccount(user.Username)
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).AnyTimes().Return(account, nil)
+
+	// resuming after entry 10 goes back to entry 9, the newest one old enough to have settled
+	settled := randomEntry(account.ID, 9, time.Hour)
+	seen := randomEntry(account.ID, 10, time.Second)
+	store.EXPECT().ListAccountEntries(gomock.Any(), gomock.Eq(db.ListAccountEntriesParams{
+		AccountID: account.ID,
+		Cursor:    11,
+		Limit:     watchBatchSize,
+	})).Times(1).Return([]db.ListAccountEntriesRow{db.ListAccountEntriesRow(seen), db.ListAccountEntriesRow(settled)}, nil)
+
+	// entry 11 commits after entry 12, and is still delivered once it does
+	late := randomEntry(account.ID, 11, time.Second)
+	incoming := randomEntry(account.ID, 12, time.Second)
+	next := randomEntry(account.ID, 13, time.Second)
+	gomock.InOrder(
+		expectEntriesAfter(store, account.ID, 9, seen, incoming),
+		expectEntriesAfter(store, account.ID, 9, seen, late, incoming),
+		// once they have settled the cursor moves past them
+		expectEntriesAfter(store, account.ID, 9,
+			randomEntry(account.ID, 10, time.Hour), randomEntry(account.ID, 11, time.Hour), randomEntry(account.ID, 12, time.Hour)),
+		expectEntriesAfter(store, account.ID, 12, next),
+	)
+
+	server := newTestServer(t, store)
+	watcher := &fakeWatcher{wakeup: make(chan struct{}, 1)}
+	server.watcher = watcher
+
+	ctx, cancel := context.WithCancel(newContextWithBearerToken(t, server.tokenMaker, user.Username, time.Minute))
+	stream := &fakeWatchStream{ctx: ctx, responses: make(chan *pb.WatchAccountResponse, 3)}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.WatchAccount(&pb.WatchAccountRequest{AccountId: account.ID, LastEntryId: 10}, stream)
+	}()
+
+	// entry 10 is sent again, clients skip the ids they have seen
+	rsp := <-stream.responses
+	require.Equal(t, account.ID, rsp.GetAccount().GetId())
+	require.Equal(t, []int64{10, 12}, entryIDs(rsp))
+
+	watcher.wakeup <- struct{}{}
+	rsp = <-stream.responses
+	require.Equal(t, []int64{11}, entryIDs(rsp))
+
+	// nothing new is sent when the entries settle
+	watcher.wakeup <- struct{}{}
+	watcher.wakeup <- struct{}{}
+	rsp = <-stream.responses
+	require.Equal(t, []int64{13}, entryIDs(rsp))
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestWatchAccountRPCFromNow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	user := randomUser()
+	account := randomAccount(user.Username)
+	store := mockdb.NewMockStore(ctrl)
+
+	// the balance in the first response already counts the entries visible when the stream starts
+	recent := randomEntry(account.ID, 42, time.Second)
+	settled := randomEntry(account.ID, 41, time.Hour)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(2).Return(account, nil)
+	store.EXPECT().ListAccountEntries(gomock.Any(), gomock.Eq(db.ListAccountEntriesParams{
+		AccountID: account.ID,
+		Cursor:    math.MaxInt64,
+		Limit:     watchBatchSize,
+	})).Times(1).Return([]db.ListAccountEntriesRow{db.ListAccountEntriesRow(recent), db.ListAccountEntriesRow(settled)}, nil)
+	expectEntriesAfter(store, account.ID, 41, recent)
+
+	server := newTestServer(t, store)
+	watcher := &fakeWatcher{wakeup: make(chan struct{})}
+	server.watcher = watcher
+
+	ctx := newContextWithBearerToken(t, server.tokenMaker, user.Username, time.Minute)
+	stream := &fakeWatchStream{ctx: ctx, responses: make(chan *pb.WatchAccountResponse, 1)}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- server.WatchAccount(&pb.WatchAccountRequest{AccountId: account.ID}, stream)
+	}()
+
+	// the first response only carries the current balance
+	rsp := <-stream.responses
+	require.Equal(t, account.Balance, rsp.GetAccount().GetBalance())
+	require.Empty(t, rsp.GetEntries())
+
+	// the stream ends when the watcher shuts down
+	close(watcher.wakeup)
+	require.Equal(t, codes.Unavailable, status.Code(<-done))
+}
+
+func TestWatchAccountRPCNotOwned(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	account := randomAccount("someone-else")
+	store := mockdb.NewMockStore(ctrl)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account.ID)).Times(1).Return(account, nil)
+	store.EXPECT().ListAccountEntriesReverse(gomock.Any(), gomock.Any()).Times(0)
+
+	server := newTestServer(t, store)
+	server.watcher = &fakeWatcher{wakeup: make(chan struct{})}
+
+	ctx := newContextWithBearerToken(t, server.tokenMaker, randomUser().Username, time.Minute)
+	stream := &fakeWatchStream{ctx: ctx, responses: make(chan *pb.WatchAccountResponse, 1)}
+	err := server.WatchAccount(&pb.WatchAccountRequest{AccountId: account.ID}, stream)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+}
@@ -0,0 +1,155 @@
+// Package health tracks whether the server can take traffic, from periodic dependency checks and its shutdown state.
+package health
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Check reports why a dependency is unusable, or nil when it is fine
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// the status of each check in a Report
+const (
+	StatusOK      = "ok"
+	StatusFailing = "failing"
+)
+
+// Report is the outcome of the last round of checks. It is served to anyone who asks, so it only says which
+// checks fail; why they fail goes to the log, it can name hosts, users and other internals.
+type Report struct {
+	Ready    bool              `json:"ready"`
+	Draining bool              `json:"draining"`
+	Checks   map[string]string `json:"checks"` // Checks: StatusOK or StatusFailing for each check
+}
+
+// Checker runs its checks every interval and tells its listeners whenever readiness flips.
+// It is not ready before the first round of checks has finished, nor once Drain has been called.
+type Checker struct {
+	interval  time.Duration
+	checks    []namedCheck
+	mu        sync.RWMutex
+	results   map[string]error
+	checked   bool
+	draining  bool
+	ready     bool
+	listeners []func(ready bool)
+}
+
+func NewChecker(interval time.Duration) *Checker {
+	return &Checker{
+		interval: interval,
+		results:  make(map[string]error),
+	}
+}
+
+// AddCheck registers a check, before Run is called
+func (checker *Checker) AddCheck(name string, check Check) {
+	checker.checks = append(checker.checks, namedCheck{name: name, check: check})
+}
+
+// OnChange calls listener with the current readiness and again every time it changes.
+// Listeners run with the checker locked, so they must not call back into it.
+func (checker *Checker) OnChange(listener func(ready bool)) {
+	checker.mu.Lock()
+	defer checker.mu.Unlock()
+	checker.listeners = append(checker.listeners, listener)
+	listener(checker.ready)
+}
+
+// Run checks right away and then every interval until ctx is done
+func (checker *Checker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(checker.interval)
+	defer ticker.Stop()
+
+	for {
+		checker.CheckNow(ctx)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckNow runs every check once, each bounded by the check interval
+func (checker *Checker) CheckNow(ctx context.Context) {
+	results := make(map[string]error, len(checker.checks))
+	for _, c := range checker.checks {
+		checkCtx, cancel := context.WithTimeout(ctx, checker.interval)
+		results[c.name] = c.check(checkCtx)
+		cancel()
+	}
+
+	checker.mu.Lock()
+	defer checker.mu.Unlock()
+	for name, err := range results {
+		if err != nil && checker.results[name] == nil {
+			slog.Warn("health check failing", "check", name, "error", err)
+		}
+		if err == nil && checker.results[name] != nil {
+			slog.Info("health check recovered", "check", name)
+		}
+	}
+	checker.results = results
+	checker.checked = true
+	checker.update()
+}
+
+// Drain marks the server as shutting down, so it stops reporting ready whatever the checks say
+func (checker *Checker) Drain() {
+	checker.mu.Lock()
+	defer checker.mu.Unlock()
+	checker.draining = true
+	checker.update()
+}
+
+// Ready reports whether the server should receive traffic
+func (checker *Checker) Ready() bool {
+	checker.mu.RLock()
+	defer checker.mu.RUnlock()
+	return checker.ready
+}
+
+func (checker *Checker) Report() Report {
+	checker.mu.RLock()
+	defer checker.mu.RUnlock()
+
+	report := Report{
+		Ready:    checker.ready,
+		Draining: checker.draining,
+		Checks:   make(map[string]string, len(checker.results)),
+	}
+	for name, err := range checker.results {
+		report.Checks[name] = StatusOK
+		if err != nil {
+			report.Checks[name] = StatusFailing
+		}
+	}
+	return report
+}
+
+// update recomputes readiness and notifies the listeners if it changed, with mu locked
+func (checker *Checker) update() {
+	ready := checker.checked && !checker.draining
+	for _, err := range checker.results {
+		if err != nil {
+			ready = false
+		}
+	}
+
+	if ready == checker.ready {
+		return
+	}
+	checker.ready = ready
+	for _, listener := range checker.listeners {
+		listener(ready)
+	}
+}
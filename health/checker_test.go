@@ -0,0 +1,83 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckerReadiness(t *testing.T) {
+	dbErr := errors.New("connection refused")
+	var dbFailing error
+	checker := NewChecker(time.Second)
+	checker.AddCheck("database", func(ctx context.Context) error { return dbFailing })
+	checker.AddCheck("migrations", func(ctx context.Context) error { return nil })
+
+	var changes []bool
+	checker.OnChange(func(ready bool) { changes = append(changes, ready) })
+
+	// not ready until the first round of checks has run
+	require.False(t, checker.Ready())
+	require.False(t, checker.Report().Ready)
+
+	checker.CheckNow(context.Background())
+	require.True(t, checker.Ready())
+	require.Equal(t, map[string]string{"database": "ok", "migrations": "ok"}, checker.Report().Checks)
+
+	dbFailing = dbErr
+	checker.CheckNow(context.Background())
+	require.False(t, checker.Ready())
+	require.Equal(t, StatusFailing, checker.Report().Checks["database"])
+
+	dbFailing = nil
+	checker.CheckNow(context.Background())
+	require.True(t, checker.Ready())
+
+	// draining wins over passing checks, and unchanged rounds don't notify
+	checker.Drain()
+	checker.CheckNow(context.Background())
+	report := checker.Report()
+	require.False(t, report.Ready)
+	require.True(t, report.Draining)
+
+	require.Equal(t, []bool{false, true, false, true, false}, changes)
+}
+
+func TestCheckerBoundsChecks(t *testing.T) {
+	checker := NewChecker(10 * time.Millisecond)
+	checker.AddCheck("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	checker.CheckNow(context.Background())
+	require.False(t, checker.Ready())
+	require.Equal(t, StatusFailing, checker.Report().Checks["slow"])
+}
+
+func TestCheckerRun(t *testing.T) {
+	checker := NewChecker(time.Hour)
+	checker.AddCheck("database", func(ctx context.Context) error { return nil })
+
+	ready := make(chan struct{})
+	checker.OnChange(func(isReady bool) {
+		if isReady {
+			close(ready)
+		}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- checker.Run(ctx) }()
+
+	select {
+	case <-ready:
+	case <-time.After(time.Second):
+		t.Fatal("checker did not run its checks on start")
+	}
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,27 @@
+package health
+
+import (
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// GRPCServer returns a grpc.health.v1 server whose status for the overall server ("")
+// and for each of services follows the checker. Once draining it reports NOT_SERVING for good.
+func (checker *Checker) GRPCServer(services ...string) *health.Server {
+	server := health.NewServer()
+	names := append([]string{""}, services...)
+
+	checker.OnChange(func(ready bool) {
+		status := healthpb.HealthCheckResponse_NOT_SERVING
+		if ready {
+			status = healthpb.HealthCheckResponse_SERVING
+		}
+		for _, name := range names {
+			server.SetServingStatus(name, status)
+		}
+		if checker.draining {
+			server.Shutdown()
+		}
+	})
+	return server
+}
@@ -0,0 +1,45 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestGRPCServer(t *testing.T) {
+	var dbFailing error
+	checker := NewChecker(time.Second)
+	checker.AddCheck("database", func(ctx context.Context) error { return dbFailing })
+	server := checker.GRPCServer("pb.SimpleBank")
+
+	requireStatus := func(want healthpb.HealthCheckResponse_ServingStatus) {
+		t.Helper()
+		for _, service := range []string{"", "pb.SimpleBank"} {
+			res, err := server.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+			require.NoError(t, err)
+			require.Equal(t, want, res.Status, "service %q", service)
+		}
+	}
+
+	requireStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+
+	checker.CheckNow(context.Background())
+	requireStatus(healthpb.HealthCheckResponse_SERVING)
+
+	dbFailing = errors.New("connection refused")
+	checker.CheckNow(context.Background())
+	requireStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+
+	dbFailing = nil
+	checker.CheckNow(context.Background())
+	requireStatus(healthpb.HealthCheckResponse_SERVING)
+
+	// once draining the server stays NOT_SERVING
+	checker.Drain()
+	checker.CheckNow(context.Background())
+	requireStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+}
@@ -0,0 +1,91 @@
+// Package logging builds the structured logger and carries a per-request logger through context.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+
+	"github.com/S-Devoe/golang-simple-bank/util"
+)
+
+// RequestIDHeader correlates a request across the http api, the gateway and the gRPC server
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the ids accepted from clients, longer ones are replaced
+const maxRequestIDLength = 128
+
+// ParseLevel reads a LOG_LEVEL value: debug, info, warn or error
+func ParseLevel(level string) (slog.Level, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return l, fmt.Errorf("unknown log level %q", level)
+	}
+	return l, nil
+}
+
+// New returns a logger writing to w in format ("json" or "text") from level up
+func New(w io.Writer, format string, level slog.Level) *slog.Logger {
+	options := &slog.HandlerOptions{Level: level}
+	if format == "text" {
+		return slog.New(slog.NewTextHandler(w, options))
+	}
+	return slog.New(slog.NewJSONHandler(w, options))
+}
+
+// requestLogger is shared by everything handling one request, so attributes added deep in the
+// call chain (the authenticated username for one) show up when the transport logs the outcome
+type requestLogger struct {
+	mu     sync.Mutex
+	logger *slog.Logger
+}
+
+type loggerKey struct{}
+
+// NewContext returns a context carrying logger for the request it belongs to
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, &requestLogger{logger: logger})
+}
+
+// FromContext returns the request logger, or the default logger outside of a request
+func FromContext(ctx context.Context) *slog.Logger {
+	if rl, ok := ctx.Value(loggerKey{}).(*requestLogger); ok {
+		rl.mu.Lock()
+		defer rl.mu.Unlock()
+		return rl.logger
+	}
+	return slog.Default()
+}
+
+// With adds attributes to the request logger in place, it does nothing outside of a request
+func With(ctx context.Context, args ...any) {
+	if rl, ok := ctx.Value(loggerKey{}).(*requestLogger); ok {
+		rl.mu.Lock()
+		defer rl.mu.Unlock()
+		rl.logger = rl.logger.With(args...)
+	}
+}
+
+// RequestID returns id if a client sent a usable one, or a new ULID
+func RequestID(id string) string {
+	if id != "" && len(id) <= maxRequestIDLength && isPrintable(id) {
+		return id
+	}
+	generated, err := util.GenerateULID()
+	if err != nil {
+		return ""
+	}
+	return generated.String()
+}
+
+// isPrintable keeps client ids to visible ASCII so they can't forge log lines
+func isPrintable(id string) bool {
+	for _, r := range id {
+		if r <= ' ' || r > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("debug")
+	require.NoError(t, err)
+	require.Equal(t, slog.LevelDebug, level)
+
+	level, err = ParseLevel("WARN")
+	require.NoError(t, err)
+	require.Equal(t, slog.LevelWarn, level)
+
+	_, err = ParseLevel("loud")
+	require.Error(t, err)
+}
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New(&buf, "json", slog.LevelInfo)
+	logger.Debug("hidden")
+	logger.Info("shown", "username", "alice")
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	require.Equal(t, "shown", line["msg"])
+	require.Equal(t, "alice", line["username"])
+
+	buf.Reset()
+	New(&buf, "text", slog.LevelInfo).Info("shown")
+	require.Contains(t, buf.String(), "msg=shown")
+}
+
+func TestContextLogger(t *testing.T) {
+	require.Equal(t, slog.Default(), FromContext(context.Background()))
+	// With outside of a request is a no-op
+	With(context.Background(), "username", "alice")
+
+	var buf bytes.Buffer
+	ctx := NewContext(context.Background(), New(&buf, "json", slog.LevelInfo).With("request_id", "abc"))
+	With(ctx, "username", "alice")
+	FromContext(ctx).Info("done")
+
+	var line map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	require.Equal(t, "abc", line["request_id"])
+	require.Equal(t, "alice", line["username"])
+}
+
+func TestRequestID(t *testing.T) {
+	require.Equal(t, "client-id-1", RequestID("client-id-1"))
+
+	for _, id := range []string{"", "bad\nid", "with space", strings.Repeat("a", maxRequestIDLength+1)} {
+		generated := RequestID(id)
+		require.NotEqual(t, id, generated)
+		require.Len(t, generated, 26)
+	}
+}
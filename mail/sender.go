@@ -0,0 +1,122 @@
+// Package mail delivers the messages the bank sends to its users
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/S-Devoe/golang-simple-bank/logging"
+)
+
+// sendTimeout bounds a delivery whose context has no deadline of its own
+const sendTimeout = 30 * time.Second
+
+// Sender delivers a plain text message to one address
+type Sender interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// NewSender sends through the SMTP server at address, authenticating with username and password when they are set.
+// Without an address the messages are only logged, which is meant for local development.
+func NewSender(address, username, password, from string) Sender {
+	if address == "" {
+		return logSender{}
+	}
+	return &smtpSender{address: address, username: username, password: password, from: from}
+}
+
+type smtpSender struct {
+	address  string
+	username string
+	password string
+	from     string
+}
+
+func (s *smtpSender) Send(ctx context.Context, to, subject, body string) error {
+	// the addresses end up in the message headers, a line break would let them add their own
+	if strings.ContainsAny(to+s.from+subject, "\r\n") {
+		return fmt.Errorf("cannot send mail to %q: header contains a line break", to)
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, sendTimeout)
+		defer cancel()
+	}
+
+	message := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n",
+		s.from, to, subject, body)
+	if err := s.send(ctx, to, []byte(message)); err != nil {
+		return fmt.Errorf("cannot send mail to %s: %w", to, err)
+	}
+	return nil
+}
+
+// send is smtp.SendMail over a connection that gives up when ctx is done
+func (s *smtpSender) send(ctx context.Context, to string, message []byte) error {
+	host, _, err := net.SplitHostPort(s.address)
+	if err != nil {
+		return fmt.Errorf("invalid smtp address %q: %w", s.address, err)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.address)
+	if err != nil {
+		return err
+	}
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+	// closing the connection unblocks the exchange when ctx is canceled before its deadline
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return err
+		}
+	}
+	if s.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.username, s.password, host)); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(s.from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(message); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+type logSender struct{}
+
+// Send logs that a message was dropped. The body carries secrets like verification codes, so it is only logged at
+// debug level, for local development.
+func (logSender) Send(ctx context.Context, to, subject, body string) error {
+	logger := logging.FromContext(ctx)
+	logger.Warn("mail not sent, SMTP_ADDRESS is not set", "to", to, "subject", subject)
+	logger.Debug("unsent mail", "to", to, "body", body)
+	return nil
+}
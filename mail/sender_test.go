@@ -0,0 +1,59 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/S-Devoe/golang-simple-bank/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSMTPSenderFollowsContext(t *testing.T) {
+	// a server that accepts connections and never greets
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			accepted <- conn
+		}
+	}()
+
+	sender := NewSender(listener.Addr().String(), "", "", "no-reply@simplebank.local")
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = sender.Send(ctx, "user@example.com", "subject", "body")
+	require.Error(t, err)
+	require.Less(t, time.Since(start), 2*time.Second)
+	(<-accepted).Close()
+}
+
+func TestSMTPSenderRejectsHeaderInjection(t *testing.T) {
+	sender := NewSender("127.0.0.1:25", "", "", "no-reply@simplebank.local")
+	err := sender.Send(context.Background(), "user@example.com\r\nBcc: victim@example.com", "subject", "body")
+	require.ErrorContains(t, err, "header contains a line break")
+}
+
+func TestLogSenderHidesBody(t *testing.T) {
+	var logs bytes.Buffer
+	ctx := logging.NewContext(context.Background(), logging.New(&logs, "json", slog.LevelInfo))
+
+	err := NewSender("", "", "", "").Send(ctx, "user@example.com", "Verify", "open https://bank/verify?secret_code=s3cr3t")
+	require.NoError(t, err)
+	require.Contains(t, logs.String(), "user@example.com")
+	require.NotContains(t, logs.String(), "s3cr3t")
+
+	// debug logging, meant for local development, shows it
+	logs.Reset()
+	ctx = logging.NewContext(context.Background(), logging.New(&logs, "json", slog.LevelDebug))
+	err = NewSender("", "", "", "").Send(ctx, "user@example.com", "Verify", "open https://bank/verify?secret_code=s3cr3t")
+	require.NoError(t, err)
+	require.Contains(t, logs.String(), "s3cr3t")
+}
@@ -0,0 +1,45 @@
+package metrics
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/S-Devoe/golang-simple-bank/token"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Handler serves the metrics of the default registry. It belongs on the admin listener, not next to the api:
+// the counters reveal traffic and failure rates to whoever can read them.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// TxObserver counts the transactions the store retries and rolls back, it satisfies db.TxObserver
+type TxObserver struct{}
+
+func (TxObserver) TxRetried(reason string) {
+	TxRetries.WithLabelValues(reason).Inc()
+}
+
+func (TxObserver) TxRolledBack() {
+	TxRollbacks.Inc()
+}
+
+// countingMaker counts the tokens the token.Maker it wraps issues
+type countingMaker struct {
+	token.Maker
+	name string
+}
+
+// CountTokens wraps maker so the tokens it issues are counted in TokensIssued under name
+func CountTokens(maker token.Maker, name string) token.Maker {
+	return &countingMaker{Maker: maker, name: name}
+}
+
+func (maker *countingMaker) CreateToken(username string, email string, sessionID string, duration time.Duration) (string, *token.Payload, error) {
+	token, payload, err := maker.Maker.CreateToken(username, email, sessionID, duration)
+	if err == nil {
+		TokensIssued.WithLabelValues(maker.name).Inc()
+	}
+	return token, payload, err
+}
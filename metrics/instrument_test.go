@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/S-Devoe/golang-simple-bank/token"
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountTokens(t *testing.T) {
+	pasetoMaker, err := token.NewPasetoMaker(util.GenerateRandomString(32))
+	require.NoError(t, err)
+	maker := CountTokens(pasetoMaker, "paseto")
+
+	issued := testutil.ToFloat64(TokensIssued.WithLabelValues("paseto"))
+	accessToken, _, err := maker.CreateToken(util.GenerateRandomUserName(), util.GenerateRandomEmail(), "session", time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, issued+1, testutil.ToFloat64(TokensIssued.WithLabelValues("paseto")))
+
+	// verifying goes straight to the wrapped maker
+	_, err = maker.VerifyToken(accessToken)
+	require.NoError(t, err)
+	require.Equal(t, issued+1, testutil.ToFloat64(TokensIssued.WithLabelValues("paseto")))
+}
+
+func TestTxObserver(t *testing.T) {
+	deadlocks := TxRetries.WithLabelValues("deadlock")
+	retries := testutil.ToFloat64(deadlocks)
+	rollbacks := testutil.ToFloat64(TxRollbacks)
+
+	TxObserver{}.TxRetried("deadlock")
+	TxObserver{}.TxRolledBack()
+
+	require.Equal(t, retries+1, testutil.ToFloat64(deadlocks))
+	require.Equal(t, rollbacks+1, testutil.ToFloat64(TxRollbacks))
+}
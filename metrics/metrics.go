@@ -0,0 +1,88 @@
+// Package metrics defines the Prometheus metrics of the service.
+// Labels are kept to bounded sets, routes, methods, codes, currencies and outcomes, never usernames or ids.
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "simplebank"
+
+// outcomes of a transfer
+const (
+	TransferCompleted         = "completed"
+	TransferRejected          = "rejected"
+	TransferInsufficientFunds = "insufficient_funds"
+	TransferFailed            = "failed"
+)
+
+// outcomes of a login
+const (
+	LoginSucceeded = "success"
+	LoginFailed    = "failure"
+)
+
+// transports a request came in over
+const (
+	TransportHTTP = "http"
+	TransportGRPC = "grpc"
+)
+
+var (
+	HTTPRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "HTTP requests handled by the gin api, by route and status.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "Latency of the HTTP requests handled by the gin api, by route.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	GRPCRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_requests_total",
+		Help:      "RPCs handled by the gRPC server, gateway calls included, by method and status code.",
+	}, []string{"method", "code"})
+
+	GRPCRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_request_duration_seconds",
+		Help:      "Latency of the RPCs handled by the gRPC server, by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+
+	TxRetries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_tx_retries_total",
+		Help:      "Transactions run again after postgres aborted them, by reason.",
+	}, []string{"reason"})
+
+	TxRollbacks = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "db_tx_rollbacks_total",
+		Help:      "Transactions rolled back because they failed.",
+	})
+
+	Transfers = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfers_total",
+		Help:      "Transfer attempts, by currency and outcome.",
+	}, []string{"transport", "currency", "outcome"})
+
+	Logins = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "logins_total",
+		Help:      "Login attempts that were checked against a user's credentials, by outcome.",
+	}, []string{"transport", "outcome"})
+
+	TokensIssued = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tokens_issued_total",
+		Help:      "Access and refresh tokens issued, by token maker.",
+	}, []string{"maker"})
+)
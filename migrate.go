@@ -0,0 +1,91 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+
+	"github.com/S-Devoe/golang-simple-bank/db/migration"
+)
+
+var errMigrateUsage = errors.New("usage: migrate up [steps] | down [steps] | status | force <version>")
+
+// runMigrateCommand handles `migrate up|down|status|force` against the embedded migrations
+func runMigrateCommand(dbSource string, args []string) error {
+	if len(args) == 0 {
+		return errMigrateUsage
+	}
+
+	migrator, err := migration.New(dbSource)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	switch args[0] {
+	case "up":
+		if len(args) > 1 {
+			steps, err := strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid steps %q: %w", args[1], err)
+			}
+			err = migrator.UpSteps(steps)
+		} else {
+			err = migrator.Up()
+		}
+		if err != nil {
+			return fmt.Errorf("cannot apply migrations: %w", err)
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid steps %q: %w", args[1], err)
+			}
+		}
+		if err := migrator.Down(steps); err != nil {
+			return fmt.Errorf("cannot roll back migrations: %w", err)
+		}
+	case "force":
+		if len(args) < 2 {
+			return errMigrateUsage
+		}
+		version, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %q: %w", args[1], err)
+		}
+		if err := migrator.Force(version); err != nil {
+			return fmt.Errorf("cannot force version: %w", err)
+		}
+	case "status":
+	default:
+		return errMigrateUsage
+	}
+
+	status, err := migrator.Status()
+	if err != nil {
+		return fmt.Errorf("cannot read migration status: %w", err)
+	}
+	return json.NewEncoder(os.Stdout).Encode(status)
+}
+
+// prepareSchema optionally applies pending migrations, then refuses to start on a dirty or outdated schema
+func prepareSchema(dbSource string, migrateOnStartup bool) error {
+	migrator, err := migration.New(dbSource)
+	if err != nil {
+		return err
+	}
+	defer migrator.Close()
+
+	if migrateOnStartup {
+		slog.Info("applying pending migrations")
+		if err := migrator.Up(); err != nil {
+			return fmt.Errorf("cannot apply migrations: %w", err)
+		}
+	}
+	return migrator.CheckCurrent()
+}
@@ -1,7 +1,7 @@
 // Code generated by protoc-gen-go. DO NOT EDIT.
 // versions:
 // 	protoc-gen-go v1.36.1
-// 	protoc        v5.29.2
+// 	protoc        (unknown)
 // source: rpc_auth.proto
 
 package pb
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/S-Devoe/golang-simple-bank/config"
+)
+
+// runReconcileCommand handles `reconcile`, failing when any account balance disagrees with its ledger entries
+func runReconcileCommand(config config.Config) error {
+	connection, store, err := connectStore(config)
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	accounts, err := store.ListUnbalancedAccounts(context.Background())
+	if err != nil {
+		return fmt.Errorf("cannot reconcile accounts: %w", err)
+	}
+	for _, account := range accounts {
+		log.Printf("account %d (%s, %s): balance %.2f, entries total %.2f, off by %.2f",
+			account.ID, account.Owner, account.Currency, account.Balance, account.EntriesTotal, account.Balance-account.EntriesTotal)
+	}
+	if len(accounts) > 0 {
+		return fmt.Errorf("%d accounts do not reconcile", len(accounts))
+	}
+	log.Println("all accounts reconcile")
+	return nil
+}
@@ -0,0 +1,160 @@
+package session
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/S-Devoe/golang-simple-bank/apperr"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/token"
+)
+
+// the errors carry their apperr code, so both transports report them the same way
+var (
+	ErrInvalidRefreshToken = apperr.New(apperr.InvalidRefreshToken, "expired or invalid refresh token, please login again")
+	ErrSessionNotFound     = apperr.New(apperr.SessionNotFound, "session not found")
+	ErrSessionBlocked      = apperr.New(apperr.SessionBlocked, "session is blocked")
+	ErrMismatchedUser      = apperr.New(apperr.SessionUserMismatch, "incorrect session user")
+	ErrMismatchedToken     = apperr.New(apperr.SessionTokenMismatch, "mismatched session token")
+	ErrSessionExpired      = apperr.New(apperr.SessionExpired, "session expired")
+	// ErrSessionEnded rejects an access token whose session was logged out, revoked or has expired, or that predates a password change
+	ErrSessionEnded = apperr.New(apperr.Unauthenticated, "the session of this access token has ended, please login again")
+)
+
+// session ids are ULIDs, so every id sorts below a string of 26 Zs
+var FirstCursor = strings.Repeat("Z", 26)
+
+// Service holds the session rules shared by the http and gRPC handlers
+type Service struct {
+	store               db.Store
+	tokenMaker          token.Maker
+	accessTokenDuration time.Duration
+}
+
+func NewService(store db.Store, tokenMaker token.Maker, accessTokenDuration time.Duration) *Service {
+	return &Service{
+		store:               store,
+		tokenMaker:          tokenMaker,
+		accessTokenDuration: accessTokenDuration,
+	}
+}
+
+// Verify checks refreshToken and returns the live session it belongs to
+func (s *Service) Verify(ctx context.Context, refreshToken string) (*token.Payload, db.Session, error) {
+	refreshPayload, err := s.tokenMaker.VerifyToken(refreshToken)
+	if err != nil {
+		return nil, db.Session{}, ErrInvalidRefreshToken
+	}
+
+	session, err := s.store.GetSession(ctx, refreshPayload.ID.String())
+	if err != nil {
+		if err == db.ErrRecordNotFound {
+			return nil, db.Session{}, ErrSessionNotFound
+		}
+		return nil, db.Session{}, err
+	}
+
+	if session.IsBlocked {
+		return nil, db.Session{}, ErrSessionBlocked
+	}
+	if session.Username != refreshPayload.Username {
+		return nil, db.Session{}, ErrMismatchedUser
+	}
+	if session.RefreshToken != refreshToken {
+		return nil, db.Session{}, ErrMismatchedToken
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return nil, db.Session{}, ErrSessionExpired
+	}
+	return refreshPayload, session, nil
+}
+
+// Renew issues a new access token for the session behind refreshToken
+func (s *Service) Renew(ctx context.Context, refreshToken string) (string, *token.Payload, error) {
+	refreshPayload, _, err := s.Verify(ctx, refreshToken)
+	if err != nil {
+		return "", nil, err
+	}
+	return s.tokenMaker.CreateToken(refreshPayload.Username, refreshPayload.Email, refreshPayload.ID.String(), s.accessTokenDuration)
+}
+
+// Authorize checks that the session an access token was issued for is still live, so logging out or revoking
+// a session cuts off its access tokens at once rather than when they expire. Tokens issued before the user's
+// last password change are refused too. Refresh tokens name no session and are refused here, they are not access tokens.
+func (s *Service) Authorize(ctx context.Context, accessPayload *token.Payload) error {
+	if accessPayload.SessionID == "" {
+		return ErrSessionEnded
+	}
+	row, err := s.store.GetAuthSession(ctx, accessPayload.SessionID)
+	if err != nil {
+		if err == db.ErrRecordNotFound {
+			return ErrSessionEnded
+		}
+		return err
+	}
+	session := row.Session
+	if session.IsBlocked || session.Username != accessPayload.Username || time.Now().After(session.ExpiresAt) {
+		return ErrSessionEnded
+	}
+	if accessPayload.IssuedAt.Before(row.PasswordChangedAt) {
+		return ErrSessionEnded
+	}
+	return nil
+}
+
+// Logout blocks the session behind refreshToken, so it can no longer renew access tokens and the ones it issued stop working
+func (s *Service) Logout(ctx context.Context, refreshToken string) (db.Session, error) {
+	_, session, err := s.Verify(ctx, refreshToken)
+	if err != nil {
+		return db.Session{}, err
+	}
+	return s.store.BlockSession(ctx, session.ID)
+}
+
+type ListParams struct {
+	Username string
+	Cursor   string
+	Limit    int32
+	Backward bool
+}
+
+// List returns up to arg.Limit of the user's sessions after arg.Cursor, newest first unless arg.Backward is set
+func (s *Service) List(ctx context.Context, arg ListParams) ([]db.Session, error) {
+	if arg.Backward {
+		return s.store.ListSessionsReverse(ctx, db.ListSessionsReverseParams{
+			Username: arg.Username,
+			Cursor:   arg.Cursor,
+			Limit:    arg.Limit,
+		})
+	}
+	return s.store.ListSessions(ctx, db.ListSessionsParams{
+		Username: arg.Username,
+		Cursor:   arg.Cursor,
+		Limit:    arg.Limit,
+	})
+}
+
+// Count returns how many sessions the user has
+func (s *Service) Count(ctx context.Context, username string) (int64, error) {
+	return s.store.CountSessions(ctx, username)
+}
+
+// Revoke blocks one of the user's sessions, cutting off its access tokens too. Sessions of other users are reported as not found.
+// Revoking an already blocked session succeeds.
+func (s *Service) Revoke(ctx context.Context, username, id string) (db.Session, error) {
+	session, err := s.store.GetSession(ctx, id)
+	if err != nil {
+		if err == db.ErrRecordNotFound {
+			return db.Session{}, ErrSessionNotFound
+		}
+		return db.Session{}, err
+	}
+	if session.Username != username {
+		return db.Session{}, ErrSessionNotFound
+	}
+	if session.IsBlocked {
+		return session, nil
+	}
+	return s.store.BlockSession(ctx, session.ID)
+}
@@ -0,0 +1,330 @@
+package session
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/S-Devoe/golang-simple-bank/db/mock"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/token"
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+func newTestService(t *testing.T, store db.Store) (*Service, token.Maker) {
+	tokenMaker, err := token.NewPasetoMaker(util.GenerateRandomString(32))
+	require.NoError(t, err)
+	return NewService(store, tokenMaker, time.Minute), tokenMaker
+}
+
+// newTestSession returns a refresh token for username and the live session stored for it
+func newTestSession(t *testing.T, tokenMaker token.Maker, username string) (string, db.Session) {
+	refreshToken, payload, err := tokenMaker.CreateToken(username, "", "", time.Hour)
+	require.NoError(t, err)
+
+	return refreshToken, db.Session{
+		ID:           payload.ID.String(),
+		Username:     username,
+		RefreshToken: refreshToken,
+		ExpiresAt:    payload.ExpiredAt,
+		CreatedAt:    payload.IssuedAt,
+	}
+}
+
+func TestRenew(t *testing.T) {
+	username := util.GenerateRandomString(10)
+
+	testCases := []struct {
+		name        string
+		buildStubs  func(store *mockdb.MockStore, session db.Session)
+		refreshFunc func(refreshToken string) string
+		checkResult func(t *testing.T, accessToken string, payload *token.Payload, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+			},
+			checkResult: func(t *testing.T, accessToken string, payload *token.Payload, err error) {
+				require.NoError(t, err)
+				require.NotEmpty(t, accessToken)
+				require.Equal(t, username, payload.Username)
+				require.NotEmpty(t, payload.SessionID)
+				require.WithinDuration(t, time.Now().Add(time.Minute), payload.ExpiredAt, time.Second)
+			},
+		},
+		{
+			name: "InvalidToken",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			refreshFunc: func(refreshToken string) string {
+				return "invalid"
+			},
+			checkResult: func(t *testing.T, accessToken string, payload *token.Payload, err error) {
+				require.ErrorIs(t, err, ErrInvalidRefreshToken)
+			},
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{}, db.ErrRecordNotFound)
+			},
+			checkResult: func(t *testing.T, accessToken string, payload *token.Payload, err error) {
+				require.ErrorIs(t, err, ErrSessionNotFound)
+			},
+		},
+		{
+			name: "Blocked",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.IsBlocked = true
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(1).Return(session, nil)
+			},
+			checkResult: func(t *testing.T, accessToken string, payload *token.Payload, err error) {
+				require.ErrorIs(t, err, ErrSessionBlocked)
+			},
+		},
+		{
+			name: "MismatchedUser",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.Username = "someone-else"
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(1).Return(session, nil)
+			},
+			checkResult: func(t *testing.T, accessToken string, payload *token.Payload, err error) {
+				require.ErrorIs(t, err, ErrMismatchedUser)
+			},
+		},
+		{
+			name: "MismatchedToken",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.RefreshToken = "another-token"
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(1).Return(session, nil)
+			},
+			checkResult: func(t *testing.T, accessToken string, payload *token.Payload, err error) {
+				require.ErrorIs(t, err, ErrMismatchedToken)
+			},
+		},
+		{
+			name: "Expired",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.ExpiresAt = time.Now().Add(-time.Minute)
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(1).Return(session, nil)
+			},
+			checkResult: func(t *testing.T, accessToken string, payload *token.Payload, err error) {
+				require.ErrorIs(t, err, ErrSessionExpired)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			service, tokenMaker := newTestService(t, store)
+			refreshToken, session := newTestSession(t, tokenMaker, username)
+			tc.buildStubs(store, session)
+			if tc.refreshFunc != nil {
+				refreshToken = tc.refreshFunc(refreshToken)
+			}
+
+			accessToken, payload, err := service.Renew(context.Background(), refreshToken)
+			tc.checkResult(t, accessToken, payload, err)
+		})
+	}
+}
+
+func TestAuthorize(t *testing.T) {
+	username := util.GenerateRandomString(10)
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore, session db.Session)
+		sessionID  func(session db.Session) string
+		checkErr   func(t *testing.T, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetAuthSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(db.GetAuthSessionRow{Session: session}, nil)
+			},
+			checkErr: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "LoggedOut",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.IsBlocked = true
+				store.EXPECT().GetAuthSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(db.GetAuthSessionRow{Session: session}, nil)
+			},
+			checkErr: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrSessionEnded)
+			},
+		},
+		{
+			name: "Expired",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.ExpiresAt = time.Now().Add(-time.Minute)
+				store.EXPECT().GetAuthSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(db.GetAuthSessionRow{Session: session}, nil)
+			},
+			checkErr: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrSessionEnded)
+			},
+		},
+		{
+			name: "IssuedBeforePasswordChange",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				row := db.GetAuthSessionRow{Session: session, PasswordChangedAt: time.Now().Add(time.Minute)}
+				store.EXPECT().GetAuthSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(row, nil)
+			},
+			checkErr: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrSessionEnded)
+			},
+		},
+		{
+			name: "NotFound",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetAuthSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(db.GetAuthSessionRow{}, db.ErrRecordNotFound)
+			},
+			checkErr: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrSessionEnded)
+			},
+		},
+		{
+			name: "RefreshToken",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetAuthSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			sessionID: func(session db.Session) string {
+				return ""
+			},
+			checkErr: func(t *testing.T, err error) {
+				require.ErrorIs(t, err, ErrSessionEnded)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			service, tokenMaker := newTestService(t, store)
+			_, session := newTestSession(t, tokenMaker, username)
+			tc.buildStubs(store, session)
+
+			sessionID := session.ID
+			if tc.sessionID != nil {
+				sessionID = tc.sessionID(session)
+			}
+			_, accessPayload, err := tokenMaker.CreateToken(username, "", sessionID, time.Minute)
+			require.NoError(t, err)
+
+			tc.checkErr(t, service.Authorize(context.Background(), accessPayload))
+		})
+	}
+}
+
+func TestLogout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := mockdb.NewMockStore(ctrl)
+	service, tokenMaker := newTestService(t, store)
+	refreshToken, session := newTestSession(t, tokenMaker, util.GenerateRandomString(10))
+
+	blocked := session
+	blocked.IsBlocked = true
+	gomock.InOrder(
+		store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil),
+		store.EXPECT().BlockSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(blocked, nil),
+	)
+
+	result, err := service.Logout(context.Background(), refreshToken)
+	require.NoError(t, err)
+	require.True(t, result.IsBlocked)
+}
+
+func TestRevoke(t *testing.T) {
+	username := util.GenerateRandomString(10)
+
+	testCases := []struct {
+		name        string
+		username    string
+		buildStubs  func(store *mockdb.MockStore, session db.Session)
+		checkResult func(t *testing.T, result db.Session, err error)
+	}{
+		{
+			name:     "OK",
+			username: username,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				blocked := session
+				blocked.IsBlocked = true
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(blocked, nil)
+			},
+			checkResult: func(t *testing.T, result db.Session, err error) {
+				require.NoError(t, err)
+				require.True(t, result.IsBlocked)
+			},
+		},
+		{
+			name:     "AlreadyBlocked",
+			username: username,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				session.IsBlocked = true
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResult: func(t *testing.T, result db.Session, err error) {
+				require.NoError(t, err)
+				require.True(t, result.IsBlocked)
+			},
+		},
+		{
+			name:     "NotOwned",
+			username: "someone-else",
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Eq(session.ID)).Times(1).Return(session, nil)
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResult: func(t *testing.T, result db.Session, err error) {
+				require.ErrorIs(t, err, ErrSessionNotFound)
+			},
+		},
+		{
+			name:     "NotFound",
+			username: username,
+			buildStubs: func(store *mockdb.MockStore, session db.Session) {
+				store.EXPECT().GetSession(gomock.Any(), gomock.Any()).Times(1).Return(db.Session{}, db.ErrRecordNotFound)
+				store.EXPECT().BlockSession(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResult: func(t *testing.T, result db.Session, err error) {
+				require.ErrorIs(t, err, ErrSessionNotFound)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			service, tokenMaker := newTestService(t, store)
+			_, session := newTestSession(t, tokenMaker, username)
+			tc.buildStubs(store, session)
+
+			result, err := service.Revoke(context.Background(), tc.username, session.ID)
+			tc.checkResult(t, result, err)
+		})
+	}
+}
@@ -23,8 +23,8 @@ func NewJWTMaker(secretKey string) (Maker, error) {
 }
 
 // CreateToken creates a new token for a specific username and duration
-func (maker *JWTMaker) CreateToken(username string, email string, duration time.Duration) (string, *Payload, error) {
-	payload, err := NewPayload(username, email, duration)
+func (maker *JWTMaker) CreateToken(username string, email string, sessionID string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, email, sessionID, duration)
 	if err != nil {
 		return "", payload, err
 	}
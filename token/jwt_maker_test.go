@@ -17,13 +17,14 @@ func TestJWTMaker(t *testing.T) {
 
 	username := util.GenerateRandomUserName()
 	email := util.GenerateRandomEmail()
+	sessionID := util.GenerateRandomString(26)
 
 	duration := time.Minute
 
 	issuedAt := time.Now()
 	expiredAt := issuedAt.Add(duration)
 
-	token, payload, err := maker.CreateToken(username, email, duration)
+	token, payload, err := maker.CreateToken(username, email, sessionID, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -35,6 +36,7 @@ func TestJWTMaker(t *testing.T) {
 	require.NotZero(t, payload.ID)
 	require.Equal(t, username, payload.Username)
 	require.Equal(t, email, payload.Email)
+	require.Equal(t, sessionID, payload.SessionID)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
 }
@@ -44,7 +46,7 @@ func TestExpiredToken(t *testing.T) {
 	maker, err := NewJWTMaker(secret)
 	require.NoError(t, err)
 
-	token, payload, err := maker.CreateToken(util.GenerateRandomUserName(), util.GenerateRandomEmail(), -time.Second)
+	token, payload, err := maker.CreateToken(util.GenerateRandomUserName(), util.GenerateRandomEmail(), "", -time.Second)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -70,7 +72,7 @@ func TestInvalidAlgorithm(t *testing.T) {
 	require.NoError(t, err)
 
 	// Create a valid token
-	token, payload, err := maker.CreateToken(util.GenerateRandomUserName(), util.GenerateRandomEmail(), time.Minute)
+	token, payload, err := maker.CreateToken(util.GenerateRandomUserName(), util.GenerateRandomEmail(), "", time.Minute)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -4,7 +4,9 @@ import "time"
 
 // maker is an interface for managing tokens
 type Maker interface {
-	CreateToken(username string, email string, duration time.Duration) (string, *Payload, error)
+	// CreateToken signs a token for username. sessionID ties an access token to the session it was issued for;
+	// refresh tokens leave it empty, their own id names the session.
+	CreateToken(username string, email string, sessionID string, duration time.Duration) (string, *Payload, error)
 
 	VerifyToken(token string) (*Payload, error)
 }
@@ -26,10 +26,10 @@ func NewPasetoMaker(symmetricKey string) (Maker, error) {
 	return maker, nil
 }
 
-func (maker *PasetoMaker) CreateToken(username string, email string, duration time.Duration) (string, *Payload, error) {
+func (maker *PasetoMaker) CreateToken(username string, email string, sessionID string, duration time.Duration) (string, *Payload, error) {
 
 	// Create a new payload
-	payload, err := NewPayload(username, email, duration)
+	payload, err := NewPayload(username, email, sessionID, duration)
 	if err != nil {
 		return "", payload, err
 	}
@@ -15,13 +15,14 @@ func TestPasetoMaker(t *testing.T) {
 
 	username := util.GenerateRandomUserName()
 	email := util.GenerateRandomEmail()
+	sessionID := util.GenerateRandomString(26)
 
 	duration := time.Minute
 
 	issuedAt := time.Now()
 	expiredAt := issuedAt.Add(duration)
 
-	token, payload, err := maker.CreateToken(username, email, duration)
+	token, payload, err := maker.CreateToken(username, email, sessionID, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -33,6 +34,7 @@ func TestPasetoMaker(t *testing.T) {
 	require.NotZero(t, payload.ID)
 	require.Equal(t, username, payload.Username)
 	require.Equal(t, email, payload.Email)
+	require.Equal(t, sessionID, payload.SessionID)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
 }
@@ -42,7 +44,7 @@ func TestExpiredPasetoToken(t *testing.T) {
 	maker, err := NewPasetoMaker(secret)
 	require.NoError(t, err)
 
-	token, payload, err := maker.CreateToken(util.GenerateRandomUserName(), util.GenerateRandomEmail(), -time.Second)
+	token, payload, err := maker.CreateToken(util.GenerateRandomUserName(), util.GenerateRandomEmail(), "", -time.Second)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 	require.NotEmpty(t, payload)
@@ -22,9 +22,10 @@ type Payload struct {
 	ExpiredAt time.Time `json:"expired_at"`
 	ID        ulid.ULID `json:"id"`
 	Audience  string    `json:"audience,omitempty"`
+	SessionID string    `json:"session_id,omitempty"`
 }
 
-func NewPayload(username, email string, duration time.Duration) (*Payload, error) {
+func NewPayload(username, email, sessionID string, duration time.Duration) (*Payload, error) {
 	id, err := util.GenerateULID()
 	if err != nil {
 		return nil, err
@@ -36,6 +37,7 @@ func NewPayload(username, email string, duration time.Duration) (*Payload, error
 		ExpiredAt: time.Now().Add(duration),
 		IssuedAt:  time.Now(),
 		ID:        id,
+		SessionID: sessionID,
 	}
 
 	return payload, nil
@@ -0,0 +1,64 @@
+// Package tracing sets up OpenTelemetry tracing, and traces the SQL statements run through pgx.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// ServiceName identifies this service on the spans it exports
+const ServiceName = "simple-bank"
+
+// exporters TRACING_EXPORTER can name
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+)
+
+// Tracer returns the tracer instrumentation in this repository starts its spans from
+func Tracer() trace.Tracer {
+	return otel.Tracer("github.com/S-Devoe/golang-simple-bank")
+}
+
+// Setup installs the tracer provider for exporter ("none" or "stdout", writing to w).
+// It returns a function that flushes the spans still buffered, to call on shutdown.
+func Setup(exporter string, w io.Writer) (func(context.Context) error, error) {
+	switch exporter {
+	case ExporterNone:
+		// nothing is recorded, but a caller's trace context is still passed on to the logs and the database
+		Install(noop.NewTracerProvider())
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		spanExporter, err := stdouttrace.New(stdouttrace.WithWriter(w))
+		if err != nil {
+			return nil, fmt.Errorf("cannot create stdout span exporter: %w", err)
+		}
+		provider := NewProvider(sdktrace.WithBatcher(spanExporter))
+		Install(provider)
+		return provider.Shutdown, nil
+	}
+	return nil, fmt.Errorf("unknown tracing exporter %q", exporter)
+}
+
+// NewProvider returns a tracer provider for this service. Tests pass
+// sdktrace.WithSyncer(tracetest.NewInMemoryExporter()) to read spans as soon as they end.
+func NewProvider(options ...sdktrace.TracerProviderOption) *sdktrace.TracerProvider {
+	res := resource.NewSchemaless(semconv.ServiceName(ServiceName))
+	return sdktrace.NewTracerProvider(append([]sdktrace.TracerProviderOption{sdktrace.WithResource(res)}, options...)...)
+}
+
+// Install makes provider the global tracer provider and propagates W3C trace context and baggage
+func Install(provider trace.TracerProvider) {
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
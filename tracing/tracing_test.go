@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestSetup(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+
+	var out bytes.Buffer
+	shutdown, err := Setup(ExporterStdout, &out)
+	require.NoError(t, err)
+	_, span := Tracer().Start(context.Background(), "transfer")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+	require.Contains(t, out.String(), `"Name":"transfer"`)
+	require.Contains(t, out.String(), ServiceName)
+
+	shutdown, err = Setup(ExporterNone, &out)
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+
+	_, err = Setup("jaeger", &out)
+	require.Error(t, err)
+}
+
+func TestPgxTracer(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+	exporter := tracetest.NewInMemoryExporter()
+	Install(NewProvider(sdktrace.WithSyncer(exporter)))
+
+	ctx, parent := Tracer().Start(context.Background(), "request")
+	tracer := PgxTracer{}
+
+	query := "-- name: GetAccount :one\nSELECT id FROM accounts WHERE id = $1"
+	queryCtx := tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: query, Args: []any{"secret-arg"}})
+	tracer.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("SELECT 1")})
+
+	queryCtx = tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "LISTEN account_entries"})
+	tracer.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{Err: pgx.ErrNoRows})
+	parent.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+
+	getAccount := spans[0]
+	require.Equal(t, "db GetAccount", getAccount.Name)
+	require.Equal(t, parent.SpanContext().SpanID(), getAccount.Parent.SpanID())
+	require.Contains(t, getAccount.Attributes, attribute.String("db.query.text", query))
+	require.Contains(t, getAccount.Attributes, attribute.Int64("db.rows_affected", 1))
+	for _, attr := range getAccount.Attributes {
+		require.NotContains(t, attr.Value.Emit(), "secret-arg")
+	}
+
+	require.Equal(t, "db LISTEN", spans[1].Name)
+	require.Equal(t, codes.Error, spans[1].Status.Code)
+}
@@ -0,0 +1,66 @@
+package util
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+// kinds of handle a user can be paid by
+const (
+	AliasUsername = "username"
+	AliasEmail    = "email"
+	AliasPhone    = "phone"
+	AliasTag      = "tag"
+)
+
+var (
+	ErrInvalidAlias = errors.New("alias must be a username, email, +phone number or @tag")
+
+	phonePattern = regexp.MustCompile(`^\+[0-9]{7,15}$`)
+	tagPattern   = regexp.MustCompile(`^@[a-z0-9_]{3,30}$`)
+	emailPattern = regexp.MustCompile(`^[^@\s]+@[^@\s]+\.[^@\s]+$`)
+)
+
+// ParseAlias detects what kind of handle raw is and returns it in the normalized form it is stored under.
+// Phone numbers are written in international form, starting with +, so usernames that start with a digit stay
+// usernames. Anything that isn't a tag, an email or a phone number is a username, returned unchanged since
+// usernames are case sensitive.
+func ParseAlias(raw string) (kind string, alias string, err error) {
+	alias = strings.TrimSpace(raw)
+	if alias == "" {
+		return "", "", ErrInvalidAlias
+	}
+
+	switch {
+	case strings.HasPrefix(alias, "@"):
+		kind, alias = AliasTag, strings.ToLower(alias)
+		if !tagPattern.MatchString(alias) {
+			return "", "", ErrInvalidAlias
+		}
+	case strings.Contains(alias, "@"):
+		kind, alias = AliasEmail, strings.ToLower(alias)
+		if !emailPattern.MatchString(alias) {
+			return "", "", ErrInvalidAlias
+		}
+	case strings.HasPrefix(alias, "+"):
+		// strip the usual separators people type in phone numbers
+		kind, alias = AliasPhone, strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "").Replace(alias)
+		if !phonePattern.MatchString(alias) {
+			return "", "", ErrInvalidAlias
+		}
+	default:
+		kind = AliasUsername
+	}
+	return kind, alias, nil
+}
+
+// MaskName hides all but the first letter of every word in a name, e.g. "John Smith" becomes "J*** S****"
+func MaskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		letters := []rune(word)
+		words[i] = string(letters[0]) + strings.Repeat("*", len(letters)-1)
+	}
+	return strings.Join(words, " ")
+}
@@ -1,5 +1,8 @@
 package util
 
+import "math"
+
+// currencies seeded into the currency registry; the registry itself decides what is supported
 const (
 	USD = "USD"
 	NGN = "NGN"
@@ -7,10 +10,8 @@ const (
 	CAD = "CAD"
 )
 
-func IsSupportedCurrency(currency string) bool {
-	switch currency {
-	case USD, NGN, EUR, CAD:
-		return true
-	}
-	return false
+// HasValidPrecision reports whether amount has no more decimals than the currency's minor-unit exponent allows
+func HasValidPrecision(amount float64, exponent int16) bool {
+	scaled := amount * math.Pow10(int(exponent))
+	return math.Abs(scaled-math.Round(scaled)) < 1e-6
 }
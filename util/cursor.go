@@ -0,0 +1,129 @@
+package util
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const MaxLimit = 100 // MaxLimit caps how many items a cursor page can hold
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorParams holds the keyset pagination parameters of a list request.
+type CursorParams struct {
+	Limit     int32  // Limit: how many items per page
+	Key       string // Key: sort key of the item the page starts after, empty on the first page
+	Backward  bool   // Backward: the page lies before Key instead of after it
+	WithTotal bool   // WithTotal: the caller asked for the size of the whole filtered set
+}
+
+// cursor is what a next/prev cursor carries before it is encoded
+type cursor struct {
+	Key      string `json:"k"`
+	Backward bool   `json:"b,omitempty"`
+}
+
+// ParseCursorQuery parses the `limit`, `cursor` and `include_total` query parameters.
+func ParseCursorQuery(ctx *gin.Context) (CursorParams, error) {
+	limit, err := strconv.Atoi(ctx.DefaultQuery("limit", strconv.Itoa(DefaultLimit)))
+	if err != nil || limit <= 0 || limit > MaxLimit {
+		return CursorParams{}, fmt.Errorf("invalid limit: must be between 1 and %d", MaxLimit)
+	}
+
+	params := CursorParams{
+		Limit:     int32(limit),
+		WithTotal: ctx.Query("include_total") == "true",
+	}
+
+	if encoded := ctx.Query("cursor"); encoded != "" {
+		params.Key, params.Backward, err = DecodeCursor(encoded)
+		if err != nil {
+			return CursorParams{}, err
+		}
+	}
+	return params, nil
+}
+
+// Int64Key returns the cursor key as an integer id, or first when there is no cursor yet.
+func (p CursorParams) Int64Key(first int64) (int64, error) {
+	if p.Key == "" {
+		return first, nil
+	}
+	id, err := strconv.ParseInt(p.Key, 10, 64)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	return id, nil
+}
+
+// StringKey returns the cursor key, or first when there is no cursor yet.
+func (p CursorParams) StringKey(first string) string {
+	if p.Key == "" {
+		return first
+	}
+	return p.Key
+}
+
+// EncodeCursor turns a sort key into an opaque cursor string
+func EncodeCursor(key string, backward bool) string {
+	data, _ := json.Marshal(cursor{Key: key, Backward: backward})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor reads back the sort key and direction of a cursor made by EncodeCursor
+func DecodeCursor(encoded string) (key string, backward bool, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", false, ErrInvalidCursor
+	}
+	var c cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Key == "" {
+		return "", false, ErrInvalidCursor
+	}
+	return c.Key, c.Backward, nil
+}
+
+// NewCursorPage builds a page out of rows fetched with a LIMIT of params.Limit+1, in the direction of the cursor.
+// The extra row only tells whether there is another page beyond this one.
+func NewCursorPage[T any](rows []T, params CursorParams, keyOf func(T) string) PaginatedData[T] {
+	hasMore := len(rows) > int(params.Limit)
+	if hasMore {
+		rows = rows[:params.Limit]
+	}
+
+	if params.Backward {
+		// backward pages are fetched in reverse order
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	data := PaginatedData[T]{
+		Results: rows,
+		Limit:   params.Limit,
+	}
+	if len(rows) == 0 {
+		return data
+	}
+
+	first, last := keyOf(rows[0]), keyOf(rows[len(rows)-1])
+	if params.Backward {
+		data.NextCursor = EncodeCursor(last, false)
+		if hasMore {
+			data.PrevCursor = EncodeCursor(first, true)
+		}
+	} else {
+		if hasMore {
+			data.NextCursor = EncodeCursor(last, false)
+		}
+		if params.Key != "" {
+			data.PrevCursor = EncodeCursor(first, true)
+		}
+	}
+	return data
+}
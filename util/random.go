@@ -56,7 +56,7 @@ func RandomMoney() float64 {
 }
 
 func GenerateRandomCurrency() string {
-	currencies := []string{"USD", "NGN", "EUR", "GBP"}
+	currencies := []string{USD, NGN, EUR, CAD}
 
 	return currencies[rand.Intn(len(currencies))]
 }
@@ -18,10 +18,12 @@ type PaginatedResponse[T any] struct {
 
 // Define the structure of the paginated data (inside the "data" field)
 type PaginatedData[T any] struct {
-	Results []T   `json:"results"` // Results is a generic slice of any type
-	Page    int32 `json:"page"`
-	Limit   int32 `json:"limit"`
-	Total   int64 `json:"total"`
+	Results    []T    `json:"results"` // Results is a generic slice of any type
+	Page       int32  `json:"page,omitempty"`
+	Limit      int32  `json:"limit"`
+	Total      *int64 `json:"total,omitempty"`       // Total is only set when it was asked for
+	NextCursor string `json:"next_cursor,omitempty"` // NextCursor and PrevCursor are set on keyset paginated lists
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
 func CreateResponse(status int, data interface{}, err interface{}) Response {
@@ -70,7 +72,28 @@ func CreatePaginatedResponse[T any](status int, results []T, page, limit int32,
 		Results: results,
 		Page:    page,
 		Limit:   limit,
-		Total:   totalItems,
+		Total:   &totalItems,
+	}
+
+	return PaginatedResponse[T]{
+		Status: status,
+		Data:   data,
+		Error:  errorField,
+	}
+}
+
+// Standardize the keyset paginated response format
+func CreateCursorPaginatedResponse[T any](status int, data PaginatedData[T], err interface{}) PaginatedResponse[T] {
+	var errorField interface{}
+	if err != nil {
+		switch e := err.(type) {
+		case error:
+			errorField = e.Error()
+		case string:
+			errorField = e
+		default:
+			errorField = fmt.Sprintf("Unknown error type: %v", e)
+		}
 	}
 
 	return PaginatedResponse[T]{
@@ -0,0 +1,94 @@
+// Package val holds the input rules both the REST and the gRPC handlers enforce, so the two transports accept the same requests.
+package val
+
+import (
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// memos and references follow the ISO 20022 text limits the transfer table was sized for
+const (
+	MaxMemoLength       = 140
+	MaxReferenceLength  = 35
+	MaxEndToEndIDLength = 35
+	MaxNicknameLength   = 50
+)
+
+var (
+	isValidUsername = regexp.MustCompile(`^[a-zA-Z0-9_]+$`).MatchString
+	// a letter may carry combining marks, for names written in decomposed form or in scripts such as Devanagari
+	isValidFullName = regexp.MustCompile(`^(?:\p{L}\p{M}*|[\s'.-])+$`).MatchString
+	hasLetter       = regexp.MustCompile(`\p{L}`).MatchString
+)
+
+// ValidateString checks value is between minLength and maxLength characters long
+func ValidateString(value string, minLength int, maxLength int) error {
+	n := utf8.RuneCountInString(value)
+	if n < minLength || n > maxLength {
+		return fmt.Errorf("must contain from %d-%d characters", minLength, maxLength)
+	}
+	return nil
+}
+
+func ValidateUsername(value string) error {
+	if err := ValidateString(value, 3, 100); err != nil {
+		return err
+	}
+	if !isValidUsername(value) {
+		return fmt.Errorf("must contain only letters, digits, or underscore")
+	}
+	return nil
+}
+
+func ValidateFullName(value string) error {
+	if err := ValidateString(value, 1, 100); err != nil {
+		return err
+	}
+	if !isValidFullName(value) {
+		return fmt.Errorf("must contain only letters, spaces, apostrophes, hyphens, or periods")
+	}
+	// spaces and punctuation alone are not a name
+	if !hasLetter(value) {
+		return fmt.Errorf("must contain at least one letter")
+	}
+	return nil
+}
+
+func ValidatePassword(value string) error {
+	return ValidateString(value, 6, 100)
+}
+
+func ValidateEmail(value string) error {
+	if err := ValidateString(value, 3, 200); err != nil {
+		return err
+	}
+	// ParseAddress also accepts "Name <address>", only the bare address is an email here
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Address != value {
+		return fmt.Errorf("is not a valid email address")
+	}
+	return nil
+}
+
+// ValidateNickname checks a payee nickname, which is stored with its surrounding spaces trimmed
+func ValidateNickname(value string) error {
+	if err := ValidateString(strings.TrimSpace(value), 1, MaxNicknameLength); err != nil {
+		return err
+	}
+	return nil
+}
+
+func ValidateMemo(value string) error {
+	return ValidateString(value, 0, MaxMemoLength)
+}
+
+func ValidateReference(value string) error {
+	return ValidateString(value, 0, MaxReferenceLength)
+}
+
+func ValidateEndToEndID(value string) error {
+	return ValidateString(value, 0, MaxEndToEndIDLength)
+}
@@ -0,0 +1,72 @@
+package val
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidators(t *testing.T) {
+	testCases := []struct {
+		name     string
+		validate func(string) error
+		valid    []string
+		invalid  []string
+	}{
+		{
+			name:     "Username",
+			validate: ValidateUsername,
+			valid:    []string{"bob", "Alice_99", strings.Repeat("a", 100)},
+			invalid:  []string{"", "ab", "bob smith", "bob@example.com", "robert;drop", strings.Repeat("a", 101)},
+		},
+		{
+			name:     "FullName",
+			validate: ValidateFullName,
+			valid:    []string{"John Smith", "Chiamaka Ọkafor", "Chiamaka O\u0323kafor", "अनुराग शर्मा", "Mary-Jane O'Neil", "J. R. R. Tolkien"},
+			invalid:  []string{"", "   ", "...", "-'-", "John1", "<script>", "\u0301John", strings.Repeat("a", 101)},
+		},
+		{
+			name:     "Password",
+			validate: ValidatePassword,
+			valid:    []string{"secret", strings.Repeat("p", 100)},
+			invalid:  []string{"", "12345", strings.Repeat("p", 101)},
+		},
+		{
+			name:     "Email",
+			validate: ValidateEmail,
+			valid:    []string{"bob@example.com", "a.b+tag@sub.example.ng"},
+			invalid:  []string{"", "bob", "bob@", "Bob <bob@example.com>", strings.Repeat("a", 200) + "@example.com"},
+		},
+		{
+			name:     "Memo",
+			validate: ValidateMemo,
+			valid:    []string{"", "rent", strings.Repeat("€", MaxMemoLength)},
+			invalid:  []string{strings.Repeat("a", MaxMemoLength+1)},
+		},
+		{
+			name:     "Reference",
+			validate: ValidateReference,
+			valid:    []string{"", "INV-2024-001"},
+			invalid:  []string{strings.Repeat("a", MaxReferenceLength+1)},
+		},
+		{
+			name:     "Nickname",
+			validate: ValidateNickname,
+			valid:    []string{"Mum", " landlord ", strings.Repeat("ß", MaxNicknameLength)},
+			invalid:  []string{"", "   ", strings.Repeat("a", MaxNicknameLength+1)},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			for _, value := range tc.valid {
+				require.NoError(t, tc.validate(value), value)
+			}
+			for _, value := range tc.invalid {
+				require.Error(t, tc.validate(value), value)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+// Package verification holds the email verification rules shared by the http and gRPC handlers
+package verification
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/S-Devoe/golang-simple-bank/apperr"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/logging"
+	"github.com/S-Devoe/golang-simple-bank/mail"
+)
+
+// ErrInvalidCode carries its apperr code, so both transports report it the same way
+var ErrInvalidCode = apperr.New(apperr.InvalidEmailCode, "the verification code is invalid, already used or expired")
+
+const secretCodeBytes = 24
+
+type Service struct {
+	store    db.Store
+	sender   mail.Sender
+	link     string
+	duration time.Duration
+}
+
+// NewService mails codes that expire after duration, as links to link with the code in the query string
+func NewService(store db.Store, sender mail.Sender, link string, duration time.Duration) *Service {
+	return &Service{
+		store:    store,
+		sender:   sender,
+		link:     link,
+		duration: duration,
+	}
+}
+
+// New returns a fresh code for the store to save with a new email address, Send mails it once it is saved
+func (s *Service) New() (db.EmailVerification, error) {
+	secret := make([]byte, secretCodeBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return db.EmailVerification{}, fmt.Errorf("cannot generate verification code: %w", err)
+	}
+	return db.EmailVerification{
+		SecretCode: base64.RawURLEncoding.EncodeToString(secret),
+		ExpiredAt:  time.Now().Add(s.duration),
+	}, nil
+}
+
+// Send mails a saved code to its address, after the transaction that saved it has committed. The signup or
+// change already happened, so a failed delivery is logged rather than returned; changing the address again
+// saves and mails a new code.
+func (s *Service) Send(ctx context.Context, verifyEmail db.VerifyEmail) {
+	body := fmt.Sprintf("Hello %s,\n\nplease confirm this is your email address by opening %s\n\nThe link expires at %s.",
+		verifyEmail.Username, s.Link(verifyEmail), verifyEmail.ExpiredAt.UTC().Format(time.RFC1123))
+	if err := s.sender.Send(ctx, verifyEmail.Email, "Verify your Simple Bank email address", body); err != nil {
+		logging.FromContext(ctx).Error("cannot mail email verification code", "email_id", verifyEmail.ID, "error", err)
+	}
+}
+
+// Link is the url the user opens to verify their address
+func (s *Service) Link(verifyEmail db.VerifyEmail) string {
+	link, err := url.Parse(s.link)
+	if err != nil {
+		// the config is validated at startup, an unparsable link can't get here
+		link = &url.URL{}
+	}
+	query := link.Query()
+	query.Set("email_id", strconv.FormatInt(verifyEmail.ID, 10))
+	query.Set("secret_code", verifyEmail.SecretCode)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
+
+// Verify uses up a code and returns the user whose address it verified
+func (s *Service) Verify(ctx context.Context, emailID int64, secretCode string) (db.User, error) {
+	result, err := s.store.VerifyEmailTx(ctx, db.VerifyEmailTxParams{
+		EmailID:    emailID,
+		SecretCode: secretCode,
+	})
+	if err != nil {
+		if err == db.ErrRecordNotFound {
+			return db.User{}, ErrInvalidCode
+		}
+		return db.User{}, err
+	}
+	return result.User, nil
+}
@@ -0,0 +1,110 @@
+package verification
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	mockdb "github.com/S-Devoe/golang-simple-bank/db/mock"
+	db "github.com/S-Devoe/golang-simple-bank/db/sqlc"
+	"github.com/S-Devoe/golang-simple-bank/util"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+)
+
+type sentMail struct {
+	to, subject, body string
+}
+
+type recordingSender struct {
+	sent []sentMail
+}
+
+func (s *recordingSender) Send(ctx context.Context, to, subject, body string) error {
+	s.sent = append(s.sent, sentMail{to, subject, body})
+	return nil
+}
+
+func TestNew(t *testing.T) {
+	sender := &recordingSender{}
+	service := NewService(nil, sender, "https://bank.example/api/v1/verify_email?lang=en", time.Hour)
+
+	verification, err := service.New()
+	require.NoError(t, err)
+	require.NotEmpty(t, verification.SecretCode)
+	require.WithinDuration(t, time.Now().Add(time.Hour), verification.ExpiredAt, time.Second)
+
+	// every code is a fresh one
+	other, err := service.New()
+	require.NoError(t, err)
+	require.NotEqual(t, verification.SecretCode, other.SecretCode)
+
+	verifyEmail := db.VerifyEmail{
+		ID:         util.GenerateRandomInt(1, 1000),
+		Username:   util.GenerateRandomString(10),
+		Email:      util.GenerateRandomEmail(),
+		SecretCode: verification.SecretCode,
+		ExpiredAt:  verification.ExpiredAt,
+	}
+	service.Send(context.Background(), verifyEmail)
+	require.Len(t, sender.sent, 1)
+	require.Equal(t, verifyEmail.Email, sender.sent[0].to)
+	require.Contains(t, sender.sent[0].body, service.Link(verifyEmail))
+
+	link, err := url.Parse(service.Link(verifyEmail))
+	require.NoError(t, err)
+	require.Equal(t, "/api/v1/verify_email", link.Path)
+	require.Equal(t, "en", link.Query().Get("lang"))
+	require.Equal(t, strconv.FormatInt(verifyEmail.ID, 10), link.Query().Get("email_id"))
+	require.Equal(t, verifyEmail.SecretCode, link.Query().Get("secret_code"))
+}
+
+func TestVerify(t *testing.T) {
+	user := db.User{Username: util.GenerateRandomString(10), IsEmailVerified: true}
+	arg := db.VerifyEmailTxParams{EmailID: 7, SecretCode: util.GenerateRandomString(32)}
+
+	testCases := []struct {
+		name       string
+		buildStubs func(store *mockdb.MockStore)
+		check      func(t *testing.T, verified db.User, err error)
+	}{
+		{
+			name: "OK",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().VerifyEmailTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.VerifyEmailTxResult{User: user}, nil)
+			},
+			check: func(t *testing.T, verified db.User, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user, verified)
+			},
+		},
+		{
+			name: "InvalidCode",
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().VerifyEmailTx(gomock.Any(), gomock.Eq(arg)).Times(1).
+					Return(db.VerifyEmailTxResult{}, db.ErrRecordNotFound)
+			},
+			check: func(t *testing.T, verified db.User, err error) {
+				require.ErrorIs(t, err, ErrInvalidCode)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			service := NewService(store, &recordingSender{}, "http://localhost:8080/api/v1/verify_email", time.Hour)
+			verified, err := service.Verify(context.Background(), arg.EmailID, arg.SecretCode)
+			tc.check(t, verified, err)
+		})
+	}
+}